@@ -0,0 +1,314 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"qrcode/encode"
+)
+
+// VersionEstimate is the smallest version, which fits the data, with the bits used by the data.
+type VersionEstimate struct {
+	// Version is the minimum version (M1-M4 are negative).
+	Version int
+
+	// UsedBits is the number of bits of the data with the mode and length prefixes of the blocks.
+	UsedBits int
+
+	// AvailableBits is the number of data bits of the version for the error correction level.
+	AvailableBits int
+
+	// LeftoverBits is the number of data bits left after the data (filled with the terminator and the padding).
+	LeftoverBits int
+}
+
+// modeDataBits returns the number of data bits for the count of characters of the built-in encoder of the mode.
+func modeDataBits(mode encode.EncodingMode, count int) (int, error) {
+	switch mode {
+	case encode.EncodingModeNumeric:
+		return count/3*10 + [3]int{0, 4, 7}[count%3], nil
+	case encode.EncodingModeAlphaNumeric:
+		return count/2*11 + count%2*6, nil
+	case encode.EncodingModeByte:
+		return count * 8, nil
+	case encode.EncodingModeKanji, encode.EncodingModeHanzi:
+		return count * 13, nil
+	}
+
+	return 0, fmt.Errorf("%w: %d", encode.ErrUnknownEncodingMode, mode)
+}
+
+// Capacity returns the maximum number of characters of the mode (digits, characters, bytes or kanji/hanzi
+// characters), which fit into the version with the error correction level in one block.
+// The version is 1-40, M1-M4 or an rMQR version.
+func Capacity(version int, level ErrorCorrectionLevel, mode encode.EncodingMode) (int, error) {
+	if _, err := modeDataBits(mode, 0); err != nil {
+		return 0, err
+	}
+
+	block := &encode.EncodeBlock{Mode: mode}
+	lengthBits, err := block.GetLengthBits(version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get length bits: %w", err)
+	}
+	availableBits, err := dataBitsCount(version, level)
+	if err != nil {
+		return 0, err
+	}
+	prefixBits := lengthBits + block.GetModeBits(version)
+	if prefixBits > availableBits {
+		return 0, nil
+	}
+
+	// the count of characters is limited by the length bits too
+	return sort.Search(1<<lengthBits, func(count int) bool {
+		dataBits, _ := modeDataBits(mode, count)
+		return prefixBits+dataBits > availableBits
+	}) - 1, nil
+}
+
+// Estimate returns the minimum version of the QR Code (or the Micro QR Code), which fits the blocks
+// with the error correction level, and the number of the used and the available data bits.
+func Estimate(blocks []*encode.EncodeBlock, level ErrorCorrectionLevel, micro bool) (*VersionEstimate, error) {
+	versions, err := candidateVersions(micro, false, level)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := minVersion(blocks, level, versions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate min version: %w", err)
+	}
+
+	dataSize := 0
+	for _, block := range blocks {
+		blockSize, err := block.CalculateDataBitsCount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate data bits count: %w", err)
+		}
+		dataSize += blockSize
+	}
+
+	usedBits, err := blocksBitsCount(blocks, version, dataSize)
+	if err != nil {
+		return nil, err
+	}
+	availableBits, err := dataBitsCount(version, level)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionEstimate{
+		Version:       version,
+		UsedBits:      usedBits,
+		AvailableBits: availableBits,
+		LeftoverBits:  availableBits - usedBits,
+	}, nil
+}
+
+// ErrCapacityExceeded is returned when the content doesn't fit the largest allowed version.
+// It matches ErrContentTooLong with errors.Is.
+type ErrCapacityExceeded struct {
+	// Version is the largest checked version: the fixed one, 40, M4 or R17x139.
+	Version    int
+	ErrorLevel ErrorCorrectionLevel
+
+	// RequiredBits is the number of bits of the data with the mode and length prefixes of the blocks.
+	RequiredBits int
+
+	// MaxBits is the number of data bits of the version with the error correction level.
+	MaxBits int
+
+	// ExcessCharacters is the number of characters (bytes for binary data) to cut from the end of the content,
+	// so it fits the version.
+	ExcessCharacters int
+
+	// FittingLevel is the highest error correction level lower than ErrorLevel, with which the content fits
+	// the version. Default: nil, no level fits.
+	FittingLevel *ErrorCorrectionLevel
+
+	// FittingVersion is the smallest larger version, which fits the content with the error correction level:
+	// the next versions of the fixed version or QR Code versions for Micro QR and rMQR codes
+	// (Micro QR codes hold less data than QR Codes, so they never help). Default: 0, no version fits.
+	FittingVersion int
+
+	// SegmentationFits is true if the content fits the version being split into the blocks
+	// with the optimal modes, i.e. without the fixed Mode option.
+	SegmentationFits bool
+}
+
+func (e ErrCapacityExceeded) Error() string {
+	return fmt.Sprintf("%v: %d bits required, version %d with error level %d holds %d bits (%d characters over)",
+		ErrContentTooLong, e.RequiredBits, e.Version, e.ErrorLevel, e.MaxBits, e.ExcessCharacters)
+}
+
+func (e ErrCapacityExceeded) Unwrap() error {
+	return ErrContentTooLong
+}
+
+// isDataBlock returns true if the block has the characters (not a header).
+func isDataBlock(block *encode.EncodeBlock) bool {
+	_, err := modeDataBits(block.Mode, 0)
+	return err == nil || block.Mode == encode.EncodingModeECI
+}
+
+// minVersion returns the minimum version from the candidate versions (see calculateMinVersion)
+// or the ErrCapacityExceeded error for the largest one.
+func minVersion(blocks []*encode.EncodeBlock, ecl ErrorCorrectionLevel, versions []int) (int, error) {
+	version, err := calculateMinVersion(blocks, ecl, versions)
+	if errors.Is(err, ErrContentTooLong) {
+		return 0, newCapacityError(blocks, versions[len(versions)-1], ecl)
+	}
+	return version, err
+}
+
+// blocksFit checks if the blocks fit the version with the error correction level.
+func blocksFit(blocks []*encode.EncodeBlock, version int, ecl ErrorCorrectionLevel) (bool, error) {
+	if !isLevelSupported(version, ecl) {
+		return false, nil
+	}
+
+	dataSize := 0
+	for _, block := range blocks {
+		blockSize, err := block.CalculateDataBitsCount()
+		if err != nil {
+			return false, fmt.Errorf("failed to calculate data bits count: %w", err)
+		}
+		dataSize += blockSize
+	}
+
+	return isVersionEnough(blocks, version, dataSize, ecl)
+}
+
+// excessCharacters returns the number of characters to cut from the end of the blocks, so they fit the version.
+// The characters of the last block are cut first, the empty block is removed with its prefix.
+func excessCharacters(blocks []*encode.EncodeBlock, version int, ecl ErrorCorrectionLevel) int {
+	excess := 0
+	for idx := len(blocks) - 1; idx >= 0; idx-- {
+		block := blocks[idx]
+		if !isDataBlock(block) {
+			// the headers have no characters
+			break
+		}
+
+		// binary data is cut by bytes, the text by runes
+		var chars []string
+		if block.Raw {
+			for i := range len(block.Data) {
+				chars = append(chars, block.Data[i:i+1])
+			}
+		} else {
+			for _, r := range block.Data {
+				chars = append(chars, string(r))
+			}
+		}
+
+		cut := sort.Search(len(chars)+1, func(count int) bool {
+			truncated := blocks[:idx:idx]
+			if count < len(chars) {
+				shorter := *block
+				shorter.Data = strings.Join(chars[:len(chars)-count], "")
+				truncated = append(truncated, &shorter)
+			}
+			ok, err := blocksFit(truncated, version, ecl)
+			return err == nil && ok
+		})
+		if cut <= len(chars) {
+			return excess + cut
+		}
+		excess += len(chars)
+	}
+
+	return excess
+}
+
+// largerVersions returns the versions larger than the version: the next versions of the same family
+// and QR Code versions for Micro QR and rMQR codes.
+func largerVersions(version int, ecl ErrorCorrectionLevel) []int {
+	family, err := candidateVersions(version < 0, encode.IsRMQRVersion(version), ecl)
+	if err != nil {
+		family = nil
+	}
+
+	var versions []int
+	for idx, candidate := range family {
+		if candidate == version {
+			versions = append(versions, family[idx+1:]...)
+			break
+		}
+	}
+
+	if version < 0 || encode.IsRMQRVersion(version) {
+		qr, _ := candidateVersions(false, false, ecl)
+		versions = append(versions, qr...)
+	}
+
+	return versions
+}
+
+// newCapacityError returns the ErrCapacityExceeded error for the blocks, which don't fit the version.
+// The plain ErrContentTooLong is returned, if the details can't be calculated.
+func newCapacityError(blocks []*encode.EncodeBlock, version int, ecl ErrorCorrectionLevel) error {
+	dataSize := 0
+	for _, block := range blocks {
+		blockSize, err := block.CalculateDataBitsCount()
+		if err != nil {
+			return ErrContentTooLong
+		}
+		dataSize += blockSize
+	}
+
+	requiredBits, err := blocksBitsCount(blocks, version, dataSize)
+	if err != nil {
+		return ErrContentTooLong
+	}
+	maxBits, err := dataBitsCount(version, ecl)
+	if err != nil {
+		return ErrContentTooLong
+	}
+
+	capacityErr := ErrCapacityExceeded{
+		Version:          version,
+		ErrorLevel:       ecl,
+		RequiredBits:     requiredBits,
+		MaxBits:          maxBits,
+		ExcessCharacters: excessCharacters(blocks, version, ecl),
+	}
+
+	for level := ecl - 1; level >= ErrorCorrectionLevelLow; level-- {
+		if ok, err := blocksFit(blocks, version, level); err == nil && ok {
+			capacityErr.FittingLevel = &level
+			break
+		}
+	}
+
+	if fittingVersion, err := calculateMinVersion(blocks, ecl, largerVersions(version, ecl)); err == nil {
+		capacityErr.FittingVersion = fittingVersion
+	}
+
+	capacityErr.SegmentationFits = segmentationFits(blocks, version, ecl)
+
+	return capacityErr
+}
+
+// segmentationFits checks if the content of the blocks fits the version with the optimal segmentation.
+// The blocks with the headers and binary data can't be segmented.
+func segmentationFits(blocks []*encode.EncodeBlock, version int, ecl ErrorCorrectionLevel) bool {
+	var content strings.Builder
+	for _, block := range blocks {
+		if !isDataBlock(block) || block.Raw {
+			return false
+		}
+		content.WriteString(block.Data)
+	}
+
+	segmented, err := encode.Segment(content.String(), version)
+	if err != nil {
+		return false
+	}
+
+	ok, err := blocksFit(segmented, version, ecl)
+	return err == nil && ok
+}
@@ -0,0 +1,288 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"qrcode/encode"
+)
+
+func TestCapacity(t *testing.T) {
+	// ISO/IEC 18004 table 7 (numeric, alphanumeric, byte and kanji) and ISO/IEC 23941 table 6 for rMQR
+	tests := []struct {
+		version  int
+		level    ErrorCorrectionLevel
+		expected [4]int
+	}{
+		{1, ErrorCorrectionLevelLow, [4]int{41, 25, 17, 10}},
+		{1, ErrorCorrectionLevelHigh, [4]int{17, 10, 7, 4}},
+		{10, ErrorCorrectionLevelMedium, [4]int{513, 311, 213, 131}},
+		{40, ErrorCorrectionLevelLow, [4]int{7089, 4296, 2953, 1817}},
+		{40, ErrorCorrectionLevelHigh, [4]int{3057, 1852, 1273, 784}},
+		{M3, ErrorCorrectionLevelLow, [4]int{23, 14, 9, 6}},
+		{M3, ErrorCorrectionLevelMedium, [4]int{18, 11, 7, 4}},
+		{M4, ErrorCorrectionLevelLow, [4]int{35, 21, 15, 9}},
+		{M4, ErrorCorrectionLevelQuartile, [4]int{21, 13, 9, 5}},
+		{R7x43, ErrorCorrectionLevelMedium, [4]int{12, 7, 5, 3}},
+	}
+
+	modes := [4]encode.EncodingMode{
+		encode.EncodingModeNumeric,
+		encode.EncodingModeAlphaNumeric,
+		encode.EncodingModeByte,
+		encode.EncodingModeKanji,
+	}
+
+	for _, test := range tests {
+		for idx, mode := range modes {
+			t.Run(fmt.Sprintf("version %v, level %v, mode %v", test.version, test.level, mode), func(t *testing.T) {
+				capacity, err := Capacity(test.version, test.level, mode)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if capacity != test.expected[idx] {
+					t.Errorf("Expected %v, got %v", test.expected[idx], capacity)
+				}
+			})
+		}
+	}
+}
+
+func TestCapacityModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		level    ErrorCorrectionLevel
+		mode     encode.EncodingMode
+		expected int
+		err      error
+	}{
+		{"M1 numeric", M1, ErrorCorrectionLevelLow, encode.EncodingModeNumeric, 5, nil},
+		{"M2 numeric", M2, ErrorCorrectionLevelMedium, encode.EncodingModeNumeric, 8, nil},
+		{"hanzi", 1, ErrorCorrectionLevelLow, encode.EncodingModeHanzi, 10, nil},
+		{"M1 alphanumeric", M1, ErrorCorrectionLevelLow, encode.EncodingModeAlphaNumeric, 0, encode.ErrVersionDoesNotSupportEncodingMode},
+		{"micro hanzi", M4, ErrorCorrectionLevelLow, encode.EncodingModeHanzi, 0, encode.ErrVersionDoesNotSupportEncodingMode},
+		{"micro level", M4, ErrorCorrectionLevelHigh, encode.EncodingModeNumeric, 0, ErrUnsupportedErrorLevel},
+		{"rmqr level", R7x43, ErrorCorrectionLevelLow, encode.EncodingModeNumeric, 0, ErrRMQRErrorLevel},
+		{"eci", 1, ErrorCorrectionLevelLow, encode.EncodingModeECI, 0, encode.ErrUnknownEncodingMode},
+		{"invalid version", 41, ErrorCorrectionLevelLow, encode.EncodingModeNumeric, 0, encode.ErrVersionInvalid{Version: 41}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			capacity, err := Capacity(test.version, test.level, test.mode)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if capacity != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, capacity)
+			}
+		})
+	}
+}
+
+func TestCapacityFits(t *testing.T) {
+	// the content of the capacity length fits the version and one more character doesn't
+	for _, version := range []int{M3, M4, 1, 9, 10, R13x77} {
+		capacity, err := Capacity(version, ErrorCorrectionLevelMedium, encode.EncodingModeByte)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, count := range []int{capacity, capacity + 1} {
+			block := &encode.EncodeBlock{Mode: encode.EncodingModeByte, Data: string(make([]byte, count))}
+			ok, err := isVersionEnough([]*encode.EncodeBlock{block}, version, count*8, ErrorCorrectionLevelMedium)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != (count == capacity) {
+				t.Errorf("version %v: Expected %v for %v bytes, got %v", version, count == capacity, count, ok)
+			}
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []*encode.EncodeBlock
+		level    ErrorCorrectionLevel
+		micro    bool
+		expected VersionEstimate
+	}{
+		{
+			// 4 mode bits, 9 length bits and 5*11 + 6 data bits of 16 data codewords
+			name:     "qr",
+			blocks:   []*encode.EncodeBlock{{Mode: encode.EncodingModeAlphaNumeric, Data: "HELLO WORLD"}},
+			level:    ErrorCorrectionLevelMedium,
+			expected: VersionEstimate{Version: 1, UsedBits: 74, AvailableBits: 128, LeftoverBits: 54},
+		},
+		{
+			// 2 mode bits, 4 length bits and 61 data bits of 11 data codewords (the last one has 4 bits)
+			name:     "micro",
+			blocks:   []*encode.EncodeBlock{{Mode: encode.EncodingModeAlphaNumeric, Data: "HELLO WORLD"}},
+			level:    ErrorCorrectionLevelLow,
+			micro:    true,
+			expected: VersionEstimate{Version: M3, UsedBits: 67, AvailableBits: 84, LeftoverBits: 17},
+		},
+		{
+			name: "multi mode",
+			blocks: []*encode.EncodeBlock{
+				{Mode: encode.EncodingModeNumeric, Data: "1234567890"},
+				{Mode: encode.EncodingModeByte, Data: "abcdefghijklmnopqrstuvwxyz"},
+			},
+			level:    ErrorCorrectionLevelHigh,
+			expected: VersionEstimate{Version: 4, UsedBits: 4 + 10 + 34 + 4 + 8 + 208, AvailableBits: 288, LeftoverBits: 288 - 268},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate, err := Estimate(test.blocks, test.level, test.micro)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *estimate != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, *estimate)
+			}
+		})
+	}
+}
+
+func TestEstimateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []*encode.EncodeBlock
+		level  ErrorCorrectionLevel
+		micro  bool
+		err    error
+	}{
+		{"too long", []*encode.EncodeBlock{{Mode: encode.EncodingModeNumeric, Data: string(make([]byte, 8000))}}, ErrorCorrectionLevelLow, false, ErrContentTooLong},
+		{"micro mode", []*encode.EncodeBlock{{Mode: encode.EncodingModeHanzi, Data: "简体"}}, ErrorCorrectionLevelLow, true, encode.ErrVersionDoesNotSupportEncodingMode},
+		{"micro level", []*encode.EncodeBlock{{Mode: encode.EncodingModeNumeric, Data: "123"}}, ErrorCorrectionLevelHigh, true, ErrUnsupportedErrorLevel},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Estimate(test.blocks, test.level, test.micro); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestCapacityExceeded(t *testing.T) {
+	low := ErrorCorrectionLevelLow
+
+	tests := []struct {
+		name     string
+		blocks   []*encode.EncodeBlock
+		options  *QRCodeOptionsMultiMode
+		expected ErrCapacityExceeded
+	}{
+		{
+			// 4 mode bits, 10 length bits and 13*10 + 4 data bits of 16 data codewords
+			name:    "fixed version",
+			blocks:  []*encode.EncodeBlock{{Mode: encode.EncodingModeNumeric, Data: strings.Repeat("0123456789", 4)}},
+			options: &QRCodeOptionsMultiMode{Version: 1, ErrorLevel: ErrorCorrectionLevelMedium},
+			expected: ErrCapacityExceeded{
+				Version:          1,
+				ErrorLevel:       ErrorCorrectionLevelMedium,
+				RequiredBits:     148,
+				MaxBits:          128,
+				ExcessCharacters: 6,
+				FittingLevel:     &low,
+				FittingVersion:   2,
+			},
+		},
+		{
+			// the digits take 81 bits in the numeric mode
+			name:    "fixed mode",
+			blocks:  []*encode.EncodeBlock{{Mode: encode.EncodingModeByte, Data: strings.Repeat("0123456789", 2)}},
+			options: &QRCodeOptionsMultiMode{Version: 1, ErrorLevel: ErrorCorrectionLevelLow},
+			expected: ErrCapacityExceeded{
+				Version:          1,
+				ErrorLevel:       ErrorCorrectionLevelLow,
+				RequiredBits:     172,
+				MaxBits:          152,
+				ExcessCharacters: 3,
+				FittingVersion:   2,
+				SegmentationFits: true,
+			},
+		},
+		{
+			// 3 mode bits, 5 length bits and 11*11 + 6 data bits of 16 data codewords
+			name:    "micro",
+			blocks:  []*encode.EncodeBlock{{Mode: encode.EncodingModeAlphaNumeric, Data: "HELLO WORLD HELLO WORLD"}},
+			options: &QRCodeOptionsMultiMode{MicroQR: true, ErrorLevel: ErrorCorrectionLevelLow},
+			expected: ErrCapacityExceeded{
+				Version:          M4,
+				ErrorLevel:       ErrorCorrectionLevelLow,
+				RequiredBits:     135,
+				MaxBits:          128,
+				ExcessCharacters: 2,
+				FittingVersion:   1,
+			},
+		},
+		{
+			name:    "binary data",
+			blocks:  []*encode.EncodeBlock{encode.NewBytesBlock([]byte{0x00, 0xff, 0xfe, 0x80, 0x0a, 0xc3, 0x01, 0x02})},
+			options: &QRCodeOptionsMultiMode{Version: M3, ErrorLevel: ErrorCorrectionLevelMedium},
+			expected: ErrCapacityExceeded{
+				Version:          M3,
+				ErrorLevel:       ErrorCorrectionLevelMedium,
+				RequiredBits:     2 + 4 + 64,
+				MaxBits:          68,
+				ExcessCharacters: 1,
+				FittingLevel:     &low,
+				FittingVersion:   M4,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := CreateMultiMode(test.blocks, test.options)
+			if !errors.Is(err, ErrContentTooLong) {
+				t.Fatalf("Expected %v, got %v", ErrContentTooLong, err)
+			}
+
+			var capacityErr ErrCapacityExceeded
+			if !errors.As(err, &capacityErr) {
+				t.Fatalf("Expected %T, got %v", capacityErr, err)
+			}
+
+			if (capacityErr.FittingLevel == nil) != (test.expected.FittingLevel == nil) ||
+				capacityErr.FittingLevel != nil && *capacityErr.FittingLevel != *test.expected.FittingLevel {
+				t.Errorf("Expected fitting level %v, got %v", test.expected.FittingLevel, capacityErr.FittingLevel)
+			}
+			capacityErr.FittingLevel = test.expected.FittingLevel
+			if capacityErr != test.expected {
+				t.Errorf("Expected %+v, got %+v", test.expected, capacityErr)
+			}
+		})
+	}
+}
+
+func TestCapacityExceededCreate(t *testing.T) {
+	_, err := Create(strings.Repeat("0123456789", 800), &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelLow})
+
+	var capacityErr ErrCapacityExceeded
+	if !errors.As(err, &capacityErr) {
+		t.Fatalf("Expected %T, got %v", capacityErr, err)
+	}
+
+	// 7089 digits fit version 40 with the low error correction level
+	expected := ErrCapacityExceeded{
+		Version:          40,
+		ErrorLevel:       ErrorCorrectionLevelLow,
+		RequiredBits:     4 + 14 + 2666*10 + 7,
+		MaxBits:          2956 * 8,
+		ExcessCharacters: 8000 - 7089,
+	}
+	if capacityErr != expected {
+		t.Errorf("Expected %+v, got %+v", expected, capacityErr)
+	}
+}
@@ -0,0 +1,90 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+var (
+	ErrInvertedColors        = errors.New("foreground color is lighter than background color")
+	ErrLowContrast           = errors.New("contrast between foreground and background colors is too low")
+	ErrTransparentBackground = errors.New("transparent background is not supported by the output format")
+)
+
+// minContrastRatio is the minimal contrast ratio (as defined by WCAG) of the foreground and background colors,
+// which scanners read reliably.
+const minContrastRatio = 3.0
+
+// colors returns the foreground and background colors of the plot options with the defaults.
+func (o *PlotOptions) colors() (color.Color, color.Color) {
+	foreground, background := o.Foreground, o.Background
+	if foreground == nil {
+		foreground = color.Black
+	}
+	if background == nil {
+		background = color.White
+	}
+	return foreground, background
+}
+
+// isTransparent returns true if the color is fully transparent.
+func isTransparent(clr color.Color) bool {
+	_, _, _, a := clr.RGBA()
+	return a == 0
+}
+
+// relativeLuminance returns the relative luminance of the opaque color (0 for black, 1 for white).
+func relativeLuminance(clr color.NRGBA) float64 {
+	linear := func(value uint8) float64 {
+		c := float64(value) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(clr.R) + 0.7152*linear(clr.G) + 0.0722*linear(clr.B)
+}
+
+// compositeLuminance returns the luminance of the (possibly translucent) color over the surface with the given luminance.
+func compositeLuminance(clr color.Color, under float64) float64 {
+	nrgba := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	alpha := float64(nrgba.A) / 255
+	return alpha*relativeLuminance(nrgba) + (1-alpha)*under
+}
+
+// contrastRatio returns the contrast ratio of the colors with the given luminance (from 1 to 21).
+func contrastRatio(lighter, darker float64) float64 {
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// checkContrast checks that the dark modules drawn with the foreground color are darker than the background
+// and the contrast is high enough for scanners. The transparent background is checked as the white one,
+// because the code is expected to be placed on a light surface.
+func checkContrast(foreground, background color.Color) error {
+	backgroundLuminance := compositeLuminance(background, 1)
+	foregroundLuminance := compositeLuminance(foreground, backgroundLuminance)
+
+	if foregroundLuminance >= backgroundLuminance {
+		return ErrInvertedColors
+	}
+
+	if ratio := contrastRatio(backgroundLuminance, foregroundLuminance); ratio < minContrastRatio {
+		return fmt.Errorf("%w: %.2f, minimum is %.2f", ErrLowContrast, ratio, minContrastRatio)
+	}
+
+	return nil
+}
+
+// hexColor returns the color in the #rrggbb form and its opacity (0-1).
+func hexColor(clr color.Color) (string, float64) {
+	nrgba := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B), float64(nrgba.A) / 255
+}
+
+// rgbComponents returns the red, green and blue components of the color (0-1) for PDF and PostScript.
+func rgbComponents(clr color.Color) string {
+	nrgba := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("%s %s %s", formatNumber(float64(nrgba.R)/255), formatNumber(float64(nrgba.G)/255), formatNumber(float64(nrgba.B)/255))
+}
@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestCheckContrast(t *testing.T) {
+	tests := []struct {
+		name       string
+		foreground color.Color
+		background color.Color
+		err        error
+	}{
+		{"black on white", color.Black, color.White, nil},
+		{"navy on light yellow", color.RGBA{0, 0, 128, 255}, color.RGBA{255, 255, 224, 255}, nil},
+		{"black on transparent", color.Black, color.Transparent, nil},
+		{"dark red on white", color.RGBA{139, 0, 0, 255}, color.White, nil},
+		{"white on black", color.White, color.Black, ErrInvertedColors},
+		{"same colors", color.RGBA{100, 100, 100, 255}, color.RGBA{100, 100, 100, 255}, ErrInvertedColors},
+		{"white on transparent", color.White, color.Transparent, ErrInvertedColors},
+		{"gray on white", color.RGBA{180, 180, 180, 255}, color.White, ErrLowContrast},
+		{"yellow on white", color.RGBA{255, 255, 0, 255}, color.White, ErrLowContrast},
+		{"translucent black on white", color.NRGBA{0, 0, 0, 40}, color.White, ErrLowContrast},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkContrast(test.foreground, test.background); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestPlotColors(t *testing.T) {
+	qr, err := Create("1234", &QRCodeOptions{Version: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	foreground := color.RGBA{0, 0, 128, 255}
+	background := color.RGBA{255, 255, 224, 255}
+
+	for _, format := range []OutputFormat{PNG, GIF} {
+		t.Run(string(format), func(t *testing.T) {
+			for _, bg := range []color.Color{background, color.Transparent} {
+				var buf bytes.Buffer
+				options := &PlotOptions{Scale: 1, Border: 2, OutputFormat: format, Foreground: foreground, Background: bg}
+				if err := qr.Plot(&buf, options); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				img, _, err := image.Decode(&buf)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				expectedBackground := color.NRGBAModel.Convert(bg)
+				if transparent := isTransparent(bg); transparent {
+					if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+						t.Errorf("Expected transparent border, got %v", img.At(0, 0))
+					}
+				} else if clr := color.NRGBAModel.Convert(img.At(0, 0)); clr != expectedBackground {
+					t.Errorf("Expected border %v, got %v", expectedBackground, clr)
+				}
+
+				// the top-left module of the finder pattern
+				if clr := color.NRGBAModel.Convert(img.At(2, 2)); clr != color.NRGBAModel.Convert(foreground) {
+					t.Errorf("Expected foreground %v, got %v", foreground, clr)
+				}
+			}
+		})
+	}
+
+	t.Run("svg", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{OutputFormat: SVG, Foreground: color.NRGBA{0, 0, 128, 200}, Background: color.Transparent}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		svg := buf.String()
+		if strings.Contains(svg, "<rect") {
+			t.Error("Expected no background for transparent color")
+		}
+		if !strings.Contains(svg, `<path fill="#000080" fill-opacity="0.7843" d=`) {
+			t.Errorf("Expected foreground fill, got %v", svg)
+		}
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{OutputFormat: PDF, Foreground: foreground, Background: background}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "1 1 0.8784 rg\n") || !strings.Contains(buf.String(), "0 0 0.502 rg\n") {
+			t.Errorf("Expected colors, got %v", buf.String())
+		}
+	})
+
+	t.Run("readable", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{Scale: 4, Border: 16, OutputFormat: PNG, Foreground: foreground, Background: background}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := ReadImage(img)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Content != "1234" {
+			t.Errorf("Expected 1234, got %v", result.Content)
+		}
+	})
+}
+
+func TestPlotColorsErrors(t *testing.T) {
+	data := [][]Cell{{{Value: true}}}
+
+	tests := []struct {
+		name    string
+		options *PlotOptions
+		err     error
+	}{
+		{"inverted", &PlotOptions{Scale: 1, OutputFormat: PNG, Foreground: color.White, Background: color.Black}, ErrInvertedColors},
+		{"low contrast", &PlotOptions{Scale: 1, OutputFormat: SVG, Foreground: color.RGBA{200, 200, 200, 255}}, ErrLowContrast},
+		{"transparent jpeg", &PlotOptions{Scale: 1, OutputFormat: JPEG, Background: color.Transparent}, ErrTransparentBackground},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := plot(data, &buf, test.options); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	t.Run("text ignores colors", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{OutputFormat: ASCII, Foreground: color.White, Background: color.Black}
+		if err := plot(data, &buf, options); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
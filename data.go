@@ -1,8 +1,8 @@
 package qrcode
 
 import (
+	"errors"
 	"fmt"
-	"math"
 
 	"qrcode/encode"
 )
@@ -22,40 +22,105 @@ const (
 )
 
 var ErrContentTooLong = fmt.Errorf("content is too long")
+var ErrUnsupportedErrorLevel = errors.New("unsupported error correction level")
 
-// isVersionEnough checks if the given version can contain the data
-func isVersionEnough(encodeBlocks []*encode.EncodeBlock, version int, dataSize int, ecl ErrorCorrectionLevel) (bool, error) {
+// blocksBitsCount returns the number of bits of the blocks with the mode and length prefixes for the version,
+// dataSize is the number of data bits of the blocks.
+func blocksBitsCount(encodeBlocks []*encode.EncodeBlock, version int, dataSize int) (int, error) {
 	prefixBits := 0
 
 	for _, block := range encodeBlocks {
 		lengthBits, err := block.GetLengthBits(version)
 		if err != nil {
-			return false, fmt.Errorf("failed to get length bits: %w", err)
+			return 0, fmt.Errorf("failed to get length bits: %w", err)
 		}
 		prefixBits += lengthBits + block.GetModeBits(version)
 	}
 
-	size := int(math.Ceil(float64(dataSize+prefixBits) / 8.0))
+	return dataSize + prefixBits, nil
+}
+
+// dataBitsCount returns the number of data bits for the version and the error correction level.
+// The last data codeword of M1 and M3 versions has only 4 bits.
+func dataBitsCount(version int, ecl ErrorCorrectionLevel) (int, error) {
+	if !isLevelSupported(version, ecl) {
+		if encode.IsRMQRVersion(version) {
+			return 0, ErrRMQRErrorLevel
+		}
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedErrorLevel, ecl)
+	}
+
+	bits := dataCodewordsCount(version, ecl) * 8
+	if version == M1 || version == M3 {
+		bits -= 4
+	}
+	return bits, nil
+}
 
-	var dataCodewords int
+// isVersionEnough checks if the given version can contain the data
+func isVersionEnough(encodeBlocks []*encode.EncodeBlock, version int, dataSize int, ecl ErrorCorrectionLevel) (bool, error) {
+	usedBits, err := blocksBitsCount(encodeBlocks, version, dataSize)
+	if err != nil {
+		return false, err
+	}
+
+	availableBits, err := dataBitsCount(version, ecl)
+	if err != nil {
+		return false, err
+	}
+
+	return usedBits <= availableBits, nil
+}
+
+// isLevelSupported checks if the version has the error correction level
+// (Micro QR versions don't have some levels, rMQR versions have only M and H).
+func isLevelSupported(version int, ecl ErrorCorrectionLevel) bool {
+	if ecl < ErrorCorrectionLevelLow || ecl > ErrorCorrectionLevelHigh {
+		return false
+	}
+	if encode.IsRMQRVersion(version) {
+		_, dataCodewords := rmqrCodewordsCount(version, ecl)
+		return dataCodewords != 0
+	}
 	if version < 0 {
-		codewords := microCodewordsCount[-version]
-		errorCodewords := microErrorCorrectionCodeWords[-version][ecl]
+		return microErrorCorrectionCodeWords[-version][ecl] != 0
+	}
+	return true
+}
 
-		if errorCodewords == 0 {
-			return false, fmt.Errorf("unsupported error correction level: %v", ecl)
+// boostErrorLevel returns the highest error correction level, which is not lower than the given one
+// and still fits the data into the version.
+func boostErrorLevel(encodeBlocks []*encode.EncodeBlock, version int, ecl ErrorCorrectionLevel) (ErrorCorrectionLevel, error) {
+	dataSize := 0
+	for _, block := range encodeBlocks {
+		blockSize, err := block.CalculateDataBitsCount()
+		if err != nil {
+			return 0, fmt.Errorf("failed to calculate data bits count: %w", err)
 		}
-		dataCodewords = codewords - errorCodewords
-	} else {
-		dataCodewords = codewordsCount[version] - errorCorrectionCodeWords[version][ecl]
+		dataSize += blockSize
 	}
 
-	return size <= dataCodewords, nil
+	boosted := ecl
+	for level := ecl + 1; level <= ErrorCorrectionLevelHigh; level++ {
+		if !isLevelSupported(version, level) {
+			continue
+		}
+
+		ok, err := isVersionEnough(encodeBlocks, version, dataSize, level)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check version: %w", err)
+		}
+		if ok {
+			boosted = level
+		}
+	}
+
+	return boosted, nil
 }
 
 // calculateMinVersion returns the minimum version for the given content, encoding mode, and error correction level.
-// Alghorithm: iterate over versions from 1 to 40 (from M1 to M4 for MicroQR) and return the first version that can contain the content.
-func calculateMinVersion(encodeBlocks []*encode.EncodeBlock, ecl ErrorCorrectionLevel, microQR bool) (int, error) {
+// Alghorithm: iterate over the candidate versions (see candidateVersions) and return the first version that can contain the content.
+func calculateMinVersion(encodeBlocks []*encode.EncodeBlock, ecl ErrorCorrectionLevel, versions []int) (int, error) {
 	dataSize := 0
 	for _, block := range encodeBlocks {
 		blockSize, err := block.CalculateDataBitsCount()
@@ -66,32 +131,91 @@ func calculateMinVersion(encodeBlocks []*encode.EncodeBlock, ecl ErrorCorrection
 		dataSize += blockSize
 	}
 
-	start, end, step := 1, 40, 1
-	if microQR {
-		start, end, step = -1, -4, -1
-	}
+	var modeErr error
+	checked := false
+	for _, version := range versions {
+		if !isLevelSupported(version, ecl) {
+			continue
+		}
 
-	// For Normal QRs: 1 to 40
-	// For Micro QRs: -1 to -4
-	for version := start; version != end+step; version += step {
-		ok, _ := isVersionEnough(encodeBlocks, version, dataSize, ecl)
-		// if err != nil {
-		// 	return 0, fmt.Errorf("failed to check version: %w", err)
-		// }
+		ok, err := isVersionEnough(encodeBlocks, version, dataSize, ecl)
+		if errors.Is(err, encode.ErrVersionDoesNotSupportEncodingMode) {
+			// e.g. Micro QR versions, which don't support the mode
+			modeErr = err
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to check version: %w", err)
+		}
+		checked = true
 
 		if ok {
 			return version, nil
 		}
 	}
 
+	if !checked {
+		if modeErr != nil {
+			return 0, modeErr
+		}
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedErrorLevel, ecl)
+	}
+
 	return 0, ErrContentTooLong
 }
 
+// calculateMinVersionSegmented returns the minimum version for the given content and error correction level
+// together with the optimal segmentation of the content for this version.
+// The segmentation depends on the count of length bits, so the content is segmented for each checked version.
+func calculateMinVersionSegmented(content string, ecl ErrorCorrectionLevel, versions []int) (int, []*encode.EncodeBlock, error) {
+	var segmentErr error
+	segmented := false
+	for _, version := range versions {
+		blocks, err := encode.Segment(content, version)
+		if err != nil {
+			// the content can't be segmented for the version (e.g. Micro QR version doesn't support the modes)
+			segmentErr = err
+			continue
+		}
+		segmented = true
+
+		if !isLevelSupported(version, ecl) {
+			continue
+		}
+
+		dataSize := 0
+		for _, block := range blocks {
+			blockSize, err := block.CalculateDataBitsCount()
+			if err != nil {
+				return 0, nil, fmt.Errorf("failed to calculate data bits count: %w", err)
+			}
+
+			dataSize += blockSize
+		}
+
+		ok, err := isVersionEnough(blocks, version, dataSize, ecl)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to check version: %w", err)
+		}
+		if ok {
+			return version, blocks, nil
+		}
+	}
+
+	if !segmented {
+		return 0, nil, fmt.Errorf("failed to segment content: %w", segmentErr)
+	}
+
+	return 0, nil, ErrContentTooLong
+}
+
 // rearrangeDataBlocks rearranges the data blocks according to the QR code specification.
 // When the QR code is split into data blocks, the data stream should be rearranged.
 func rearrangeDataBlocks(data []byte, version int, errorLevel ErrorCorrectionLevel) []byte {
 	var blocks []ecBlock
-	if version < 0 {
+	if encode.IsRMQRVersion(version) {
+		blocks = rmqrErrorCorrectionBlocks[version-R7x43][errorLevel]
+	} else if version < 0 {
 		blocks = microErrorCorrectionBlocks[-version][errorLevel]
 	} else {
 		blocks = errorCorrectionBlocks[version][errorLevel]
@@ -126,17 +250,28 @@ func rearrangeDataBlocks(data []byte, version int, errorLevel ErrorCorrectionLev
 	return buf
 }
 
+// dataCodewordsCount returns the number of data codewords for the version and the error correction level.
+func dataCodewordsCount(version int, errorLevel ErrorCorrectionLevel) int {
+	if encode.IsRMQRVersion(version) {
+		_, dataCodewords := rmqrCodewordsCount(version, errorLevel)
+		return dataCodewords
+	}
+	if version < 0 {
+		return microCodewordsCount[-version] - microErrorCorrectionCodeWords[-version][errorLevel]
+	}
+	return codewordsCount[version] - errorCorrectionCodeWords[version][errorLevel]
+}
+
 // fillTerminator fills the data with terminator and padding bits based on the QR code specification.
 func fillTerminator(data []byte, remainedBits int, version int, errorLevel ErrorCorrectionLevel) []byte {
-	var availableCodewords int
+	availableCodewords := dataCodewordsCount(version, errorLevel)
 	terminatorBits := 4
 
-	// Micro QR Codes
-	if version < 0 {
-		availableCodewords = microCodewordsCount[-version] - microErrorCorrectionCodeWords[-version][errorLevel]
+	if encode.IsRMQRVersion(version) {
+		terminatorBits = 3
+	} else if version < 0 {
+		// Micro QR Codes
 		terminatorBits = -version*2 + 1
-	} else {
-		availableCodewords = codewordsCount[version] - errorCorrectionCodeWords[version][errorLevel]
 	}
 
 	if remainedBits < terminatorBits && len(data) < availableCodewords {
@@ -171,13 +306,9 @@ func fillTerminator(data []byte, remainedBits int, version int, errorLevel Error
 func getBytesData(blocks []*encode.EncodeBlock, errorLevel ErrorCorrectionLevel, version int) ([]byte, error) {
 	allBits := 0
 
-	queue := make(chan encode.ValueBlock, 100)
-	result := make(chan []byte)
-
-	go encode.GenerateData(queue, result)
-
+	writer := encode.NewBitWriter(dataCodewordsCount(version, errorLevel))
 	for _, block := range blocks {
-		blockBits, err := block.Encode(version, queue)
+		blockBits, err := block.EncodeBits(version, writer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encode data: %w", err)
 		}
@@ -185,8 +316,7 @@ func getBytesData(blocks []*encode.EncodeBlock, errorLevel ErrorCorrectionLevel,
 		allBits += blockBits
 	}
 
-	close(queue)
-	data := <-result
+	data := writer.Bytes()
 
 	// add terminator
 	remainedBits := len(data)*8 - allBits
@@ -0,0 +1,134 @@
+package qrcode
+
+import (
+	"fmt"
+	"testing"
+
+	"qrcode/encode"
+)
+
+func TestIsVersionEnoughECI(t *testing.T) {
+	// version 1-L has 19 data codewords (152 bits), 16 bytes of data take 128 bits
+	// and the prefix is 4 (mode) + designator + 4 (byte mode) + 8 (length) bits
+	tests := []struct {
+		assignmentNumber uint
+		expected         bool
+	}{
+		{encode.UTF8, true},
+		{999, false},
+		{encode.MaxAssignmentNumber, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.assignmentNumber), func(t *testing.T) {
+			blocks := []*encode.EncodeBlock{{
+				Mode:             encode.EncodingModeECI,
+				SubMode:          encode.EncodingModeByte,
+				AssignmentNumber: test.assignmentNumber,
+			}}
+
+			ok, err := isVersionEnough(blocks, 1, 128, ErrorCorrectionLevelLow)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, ok)
+			}
+		})
+	}
+}
+
+func TestBoostErrorLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		options  QRCodeOptions
+		expected ErrorCorrectionLevel
+	}{
+		{"disabled", "HELLO 123", QRCodeOptions{Version: 1}, ErrorCorrectionLevelLow},
+		{"fixed version", "HELLO 123", QRCodeOptions{Version: 1, BoostErrorLevel: true}, ErrorCorrectionLevelHigh},
+		{"min version", "HELLO 123", QRCodeOptions{BoostErrorLevel: true}, ErrorCorrectionLevelHigh},
+		// version 1 holds 25 alphanumeric characters with the level L and 20 with the level M
+		{"full version", "HELLO WORLD 1234567890ABC", QRCodeOptions{Version: 1, BoostErrorLevel: true}, ErrorCorrectionLevelLow},
+		{"quartile", "HELLO WORLD 1234", QRCodeOptions{Version: 1, BoostErrorLevel: true}, ErrorCorrectionLevelQuartile},
+		// M2 has only the levels L and M
+		{"micro", "12345", QRCodeOptions{Version: M2, BoostErrorLevel: true}, ErrorCorrectionLevelMedium},
+		{"rmqr", "HELLO", QRCodeOptions{Version: R13x43, ErrorLevel: ErrorCorrectionLevelMedium, BoostErrorLevel: true}, ErrorCorrectionLevelHigh},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := Create(test.content, &test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if qr.ErrorLevel != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, qr.ErrorLevel)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ErrorLevel != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result.ErrorLevel)
+			}
+			if result.Content != test.content {
+				t.Errorf("Expected %v, got %v", test.content, result.Content)
+			}
+		})
+	}
+}
+
+func TestCreateBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		options *QRCodeOptions
+	}{
+		{"binary", []byte{0x00, 0xff, 0xfe, 0x80, 0x0a, 0xc3}, nil},
+		{"utf-8", []byte("ÿé"), &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh}},
+		{"micro", []byte{0xde, 0xad, 0xbe, 0xef}, &QRCodeOptions{MicroQR: true}},
+		{"rmqr", []byte{0xde, 0xad, 0xbe, 0xef}, &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := CreateBytes(test.data, test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Blocks) != 1 || result.Blocks[0].Mode != encode.EncodingModeByte {
+				t.Fatalf("Expected one byte block, got %v", result.Blocks)
+			}
+
+			// the decoder converts the bytes from ISO 8859-1, each rune is a byte
+			var decoded []byte
+			for _, r := range result.Content {
+				decoded = append(decoded, byte(r))
+			}
+			if string(decoded) != string(test.data) {
+				t.Errorf("Expected %v, got %v", test.data, decoded)
+			}
+		})
+	}
+}
+
+func BenchmarkGetBytesData(b *testing.B) {
+	blocks, err := encode.Segment("https://example.com/path?query=1234567890&name=QRCODE", 5)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := getBytesData(blocks, ErrorCorrectionLevelMedium, 5); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"qrcode/encode"
+)
+
+var (
+	ErrInvalidSize       = errors.New("invalid size of the QR code matrix")
+	ErrInvalidFormatInfo = errors.New("cannot read format information")
+)
+
+// maxInfoDistance is the maximum number of wrong bits in the format (or version) information,
+// which can be corrected. The codes of the format information have the minimal Hamming distance of 7.
+const maxInfoDistance = 3
+
+// DecodeResult is a result of decoding a QR Code matrix.
+type DecodeResult struct {
+	// Content is the decoded content (concatenation of the data of all blocks).
+	Content string
+
+	// Blocks are the decoded blocks of data with their modes.
+	Blocks []*encode.EncodeBlock
+
+	// Version is the version of the QR Code (M1-M4 are negative, rMQR versions are R7x43-R17x139).
+	Version int
+
+	// ErrorLevel is the error correction level.
+	ErrorLevel ErrorCorrectionLevel
+
+	// Mask is the mask pattern (0-7 for QR Codes, 0-3 for Micro QR Codes, 0 for rMQR codes with the only mask).
+	Mask int
+
+	// Corrections are the statistics of the error correction for each error correction block.
+	Corrections []BlockCorrection
+}
+
+// BlockCorrection is a statistic of the error correction of one error correction block.
+type BlockCorrection struct {
+	// Errors is the number of corrected codewords with unknown positions.
+	Errors int
+
+	// Erasures is the number of corrected codewords with known positions.
+	Erasures int
+
+	// ErrorCorrectionCodewords is the number of error correction codewords in the block.
+	// The block can be corrected while 2*Errors + Erasures <= ErrorCorrectionCodewords - MisdecodeProtection.
+	ErrorCorrectionCodewords int
+
+	// MisdecodeProtection is the number of error correction codewords, which only detect errors
+	// (ISO/IEC 18004 Table 9, the small versions only).
+	MisdecodeProtection int
+}
+
+// Margin returns the number of additional errors, which the block can still correct.
+func (c BlockCorrection) Margin() int {
+	return (c.ErrorCorrectionCodewords - c.MisdecodeProtection - 2*c.Errors - c.Erasures) / 2
+}
+
+// getVersionBySize returns the version of the QR code matrix with the given size.
+func getVersionBySize(size int) (int, error) {
+	if size >= 11 && size <= 17 && size%2 == 1 {
+		return -(size - 9) / 2, nil
+	}
+
+	if size >= 21 && size <= 177 && (size-17)%4 == 0 {
+		return (size - 17) / 4, nil
+	}
+
+	return 0, ErrInvalidSize
+}
+
+// infoDistance returns the number of different bits of the read information and the expected values.
+func infoDistance(read []byte, expected []byte) int {
+	distance := 0
+	for idx, value := range expected {
+		if read[idx] != value {
+			distance++
+		}
+	}
+	return distance
+}
+
+// readFormatBlock reads both copies of the format information from the QR code matrix.
+// It's the reverse operation for fillFormatBlock.
+func readFormatBlock(field [][]Cell) ([15]byte, [15]byte) {
+	size := len(field)
+	var first, second [15]byte
+	bit := func(cell Cell) byte {
+		if cell.Value {
+			return 1
+		}
+		return 0
+	}
+
+	for i := 0; i < 8; i++ {
+		shiftDelimiter := 0
+		if i > 5 {
+			shiftDelimiter = 1
+		}
+		first[14-i] = bit(field[i+shiftDelimiter][8])
+		first[i] = bit(field[8][i+shiftDelimiter])
+
+		second[14-i] = bit(field[8][size-1-i])
+		if i < 7 {
+			second[i] = bit(field[size-1-i][8])
+		}
+	}
+
+	return first, second
+}
+
+// decodeFormat finds the error correction level and the mask pattern with the closest format information.
+func decodeFormat(field [][]Cell) (ErrorCorrectionLevel, int, error) {
+	first, second := readFormatBlock(field)
+
+	bestDistance := maxInfoDistance + 1
+	var level ErrorCorrectionLevel
+	mask := 0
+	for levelIdx, masks := range formatValues {
+		for maskIdx, values := range masks {
+			for _, read := range [][15]byte{first, second} {
+				distance := infoDistance(read[:], values[:])
+				if distance < bestDistance {
+					bestDistance = distance
+					level = ErrorCorrectionLevel(levelIdx)
+					mask = maskIdx
+				}
+			}
+		}
+	}
+
+	if bestDistance > maxInfoDistance {
+		return 0, 0, ErrInvalidFormatInfo
+	}
+
+	return level, mask, nil
+}
+
+// decodeFormatMicro finds the version, the error correction level and the mask pattern
+// with the closest format information of the Micro QR code.
+// It's the reverse operation for fillFormatBlockMicro.
+func decodeFormatMicro(field [][]Cell) (int, ErrorCorrectionLevel, int, error) {
+	var read [15]byte
+	for i := 0; i < 8; i++ {
+		if field[i+1][8].Value {
+			read[14-i] = 1
+		}
+		if field[8][i+1].Value {
+			read[i] = 1
+		}
+	}
+
+	bestDistance := maxInfoDistance + 1
+	version := 0
+	var level ErrorCorrectionLevel
+	mask := 0
+	for versionIdx := 1; versionIdx < len(microFormatValues); versionIdx++ {
+		for levelIdx, masks := range microFormatValues[versionIdx] {
+			if microErrorCorrectionCodeWords[versionIdx][levelIdx] == 0 {
+				continue
+			}
+			for maskIdx, values := range masks {
+				distance := infoDistance(read[:], values[:])
+				if distance < bestDistance {
+					bestDistance = distance
+					version = -versionIdx
+					level = ErrorCorrectionLevel(levelIdx)
+					mask = maskIdx
+				}
+			}
+		}
+	}
+
+	if bestDistance > maxInfoDistance {
+		return 0, 0, 0, ErrInvalidFormatInfo
+	}
+
+	return version, level, mask, nil
+}
+
+// decodeVersion reads the version information (for versions 7-40) and returns the closest version.
+// If the information cannot be read, the version calculated by the size is returned.
+// It's the reverse operation for fillVersionBlock.
+func decodeVersion(field [][]Cell, sizeVersion int) int {
+	if sizeVersion < 7 {
+		return sizeVersion
+	}
+
+	size := len(field)
+	var first, second [18]byte
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 3; j++ {
+			if field[size-11+j][i].Value {
+				first[i*3+j] = 1
+			}
+			if field[5-i][size-9-j].Value {
+				second[i*3+j] = 1
+			}
+		}
+	}
+
+	bestDistance := maxInfoDistance + 1
+	version := sizeVersion
+	for idx, values := range versionValues {
+		for _, read := range [][18]byte{first, second} {
+			distance := infoDistance(read[:], values[:])
+			if distance < bestDistance {
+				bestDistance = distance
+				version = idx + 7
+			}
+		}
+	}
+
+	return version
+}
+
+// walkDataModules calls fn for each data module of the QR code matrix in the order of the data bits:
+// byteIdx is the index of the codeword, bitIdx is the index of the bit in the codeword (0 is the most significant).
+// It's the same order as in fillDataBlock and fillDataBlockMicro.
+func walkDataModules(functionField [][]Cell, codewords int, version int, errorLevel ErrorCorrectionLevel, fn func(x, y, byteIdx, bitIdx int)) {
+	size := len(functionField)
+	bitIdx := 0
+	pos := position{X: size - 1, Y: size - 1, Size: size, Direction: -1, Micro: version < 0}
+	if encode.IsRMQRVersion(version) {
+		pos = rmqrPosition(functionField)
+	}
+	for byteIdx := 0; byteIdx < codewords; {
+		if functionField[pos.Y][pos.X].Type == CellTypeData {
+			fn(pos.X, pos.Y, byteIdx, bitIdx)
+			bitIdx++
+
+			// For M1, M3L and M3M, the last data byte has 4 bits
+			if bitIdx == 4 && version < 0 {
+				if version == -1 && errorLevel == ErrorCorrectionLevelLow && byteIdx == 2 {
+					bitIdx = 8
+				} else if version == -3 && errorLevel == ErrorCorrectionLevelLow && byteIdx == 10 {
+					bitIdx = 8
+				} else if version == -3 && errorLevel == ErrorCorrectionLevelMedium && byteIdx == 8 {
+					bitIdx = 8
+				}
+			}
+
+			if bitIdx > 7 {
+				bitIdx = 0
+				byteIdx++
+			}
+		}
+		pos.Next()
+	}
+}
+
+// readDataBlock reads the codewords from the QR code matrix.
+// It's the reverse operation for fillDataBlock and fillDataBlockMicro: the function field defines the data cells,
+// the values are taken from the read field.
+func readDataBlock(field, functionField [][]Cell, codewords int, version int, errorLevel ErrorCorrectionLevel) []byte {
+	data := make([]byte, codewords)
+	walkDataModules(functionField, codewords, version, errorLevel, func(x, y, byteIdx, bitIdx int) {
+		if field[y][x].Value {
+			data[byteIdx] |= 1 << uint(7-bitIdx)
+		}
+	})
+
+	return data
+}
+
+// interleavedBlockIndexes returns the index of the error correction block for each codeword of the interleaved data
+// and the numbers of data and error correction codewords of each block.
+// It follows the order of rearrangeDataBlocks and getEDCData: data codewords of all blocks, then error correction ones.
+func interleavedBlockIndexes(blocks []ecBlock) ([]int, []int, []int) {
+	var dataSizes, errorSizes []int
+	maxDataSize, maxErrorSize := 0, 0
+	for _, block := range blocks {
+		for i := 0; i < block.Blocks; i++ {
+			dataSizes = append(dataSizes, block.DataCodewords)
+			errorSizes = append(errorSizes, block.TotalCodewords-block.DataCodewords)
+		}
+		maxDataSize = max(maxDataSize, block.DataCodewords)
+		maxErrorSize = max(maxErrorSize, block.TotalCodewords-block.DataCodewords)
+	}
+
+	var indexes []int
+	for i := 0; i < maxDataSize; i++ {
+		for j := range dataSizes {
+			if i < dataSizes[j] {
+				indexes = append(indexes, j)
+			}
+		}
+	}
+
+	for i := 0; i < maxErrorSize; i++ {
+		for j := range errorSizes {
+			if i < errorSizes[j] {
+				indexes = append(indexes, j)
+			}
+		}
+	}
+
+	return indexes, dataSizes, errorSizes
+}
+
+// splitDataBlocks splits the interleaved codewords into the error correction blocks.
+// It returns the codewords of each block (data codewords followed by error correction codewords)
+// and the number of data codewords in each block.
+// It's the reverse operation for rearrangeDataBlocks and getEDCData.
+func splitDataBlocks(data []byte, blocks []ecBlock) ([][]byte, []int) {
+	indexes, dataSizes, _ := interleavedBlockIndexes(blocks)
+
+	// all data codewords go before error correction codewords, so each block gets its data codewords first
+	codewordBlocks := make([][]byte, len(dataSizes))
+	for idx, block := range indexes {
+		codewordBlocks[block] = append(codewordBlocks[block], data[idx])
+	}
+
+	return codewordBlocks, dataSizes
+}
+
+// Decode decodes the QR Code (Micro QR Code or rMQR code) matrix: reads the format and version information,
+// removes the mask, corrects the errors with Reed-Solomon codes and parses the data blocks.
+func Decode(data [][]Cell) (*DecodeResult, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidSize
+	}
+	width, height := len(data[0]), len(data)
+	for _, row := range data {
+		if len(row) != width {
+			return nil, ErrInvalidSize
+		}
+	}
+
+	var version int
+	var err error
+	if width != height {
+		version, err = getRMQRVersionBySize(width, height)
+	} else {
+		version, err = getVersionBySize(height)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var errorLevel ErrorCorrectionLevel
+	var mask, normalMask int
+	var codewords int
+	var blocks []ecBlock
+
+	if encode.IsRMQRVersion(version) {
+		var formatVersion int
+		formatVersion, errorLevel, err = decodeFormatRMQR(data)
+		if err != nil {
+			return nil, err
+		}
+		if formatVersion != version {
+			return nil, fmt.Errorf("rMQR version from format information doesn't match the size: %w", ErrInvalidFormatInfo)
+		}
+		normalMask = rmqrMask
+		codewords, _ = rmqrCodewordsCount(version, errorLevel)
+		blocks = rmqrErrorCorrectionBlocks[version-R7x43][errorLevel]
+	} else if version < 0 {
+		var formatVersion int
+		formatVersion, errorLevel, mask, err = decodeFormatMicro(data)
+		if err != nil {
+			return nil, err
+		}
+		if formatVersion != version {
+			return nil, fmt.Errorf("version M%d from format information doesn't match the size: %w", -formatVersion, ErrInvalidFormatInfo)
+		}
+		normalMask = microToNormalMask[mask].normalMask
+		codewords = microCodewordsCount[-version]
+		blocks = microErrorCorrectionBlocks[-version][errorLevel]
+	} else {
+		errorLevel, mask, err = decodeFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		if decodedVersion := decodeVersion(data, version); decodedVersion != version {
+			return nil, fmt.Errorf("version %d from version information doesn't match the size: %w", decodedVersion, ErrInvalidSize)
+		}
+		normalMask = mask
+		codewords = codewordsCount[version]
+		blocks = errorCorrectionBlocks[version][errorLevel]
+	}
+
+	functionField := createFunctionField(version)
+
+	// remove the mask from the copy of the data cells
+	field := make([][]Cell, height)
+	for idx, row := range data {
+		field[idx] = make([]Cell, width)
+		for jdx, cell := range row {
+			field[idx][jdx] = Cell{Value: cell.Value, Type: functionField[idx][jdx].Type}
+		}
+	}
+	applyMask(field, normalMask)
+
+	buf := readDataBlock(field, functionField, codewords, version, errorLevel)
+
+	var dataCodewords []byte
+	var corrections []BlockCorrection
+	codewordBlocks, dataSizes := splitDataBlocks(buf, blocks)
+	for idx, block := range codewordBlocks {
+		geometry := ecBlock{Blocks: 1, TotalCodewords: len(block), DataCodewords: dataSizes[idx]}
+		corrected, correction, err := correctBlock(block, geometry, misdecodeProtection(version, errorLevel), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to correct errors in block %d: %w", idx, err)
+		}
+		dataCodewords = append(dataCodewords, corrected...)
+		corrections = append(corrections, correction)
+	}
+
+	encodeBlocks, err := encode.DecodeData(dataCodewords, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range encodeBlocks {
+		content.WriteString(block.Data)
+	}
+
+	return &DecodeResult{
+		Content:     content.String(),
+		Blocks:      encodeBlocks,
+		Version:     version,
+		ErrorLevel:  errorLevel,
+		Mask:        mask,
+		Corrections: corrections,
+	}, nil
+}
@@ -0,0 +1,430 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"qrcode/encode"
+)
+
+func TestGetVersionBySize(t *testing.T) {
+	tests := []struct {
+		size    int
+		version int
+		err     error
+	}{
+		{11, -1, nil},
+		{17, -4, nil},
+		{21, 1, nil},
+		{177, 40, nil},
+		{19, 0, ErrInvalidSize},
+		{22, 0, ErrInvalidSize},
+		{181, 0, ErrInvalidSize},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("size %v", test.size), func(t *testing.T) {
+			version, err := getVersionBySize(test.size)
+			if err != test.err {
+				t.Fatalf("Expected error %v, got %v", test.err, err)
+			}
+			if version != test.version {
+				t.Errorf("Expected %v, got %v", test.version, version)
+			}
+		})
+	}
+}
+
+func TestSplitDataBlocks(t *testing.T) {
+	for _, version := range []int{-4, 1, 5, 10, 40} {
+		for level := ErrorCorrectionLevelLow; level <= ErrorCorrectionLevelHigh; level++ {
+			var blocks []ecBlock
+			var dataCodewords int
+			if version < 0 {
+				if microErrorCorrectionCodeWords[-version][level] == 0 {
+					continue
+				}
+				blocks = microErrorCorrectionBlocks[-version][level]
+				dataCodewords = microCodewordsCount[-version] - microErrorCorrectionCodeWords[-version][level]
+			} else {
+				blocks = errorCorrectionBlocks[version][level]
+				dataCodewords = codewordsCount[version] - errorCorrectionCodeWords[version][level]
+			}
+
+			t.Run(fmt.Sprintf("version %v, level %v", version, level), func(t *testing.T) {
+				data := make([]byte, dataCodewords)
+				for idx := range data {
+					data[idx] = byte(idx)
+				}
+
+				buf := rearrangeDataBlocks(data, version, level)
+				buf = append(buf, getEDCData(data, version, level)...)
+
+				codewordBlocks, dataSizes := splitDataBlocks(buf, blocks)
+
+				var restored []byte
+				for idx, block := range codewordBlocks {
+					if _, hasErrors := calculateSyndromes(block, len(block)-dataSizes[idx]); hasErrors {
+						t.Errorf("block %v has errors", idx)
+					}
+					restored = append(restored, block[:dataSizes[idx]]...)
+				}
+
+				if string(restored) != string(data) {
+					t.Errorf("Expected %v, got %v", data, restored)
+				}
+			})
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	modes := []encode.EncodingMode{
+		encode.EncodingModeNumeric,
+		encode.EncodingModeAlphaNumeric,
+		encode.EncodingModeByte,
+		encode.EncodingModeKanji,
+	}
+
+	type decodeTest struct {
+		version int
+		level   ErrorCorrectionLevel
+		mode    encode.EncodingMode
+		size    int
+	}
+
+	var tests []decodeTest
+	for _, version := range []int{1, 2, 6, 7, 10, 27, 40} {
+		for level := 0; level < 4; level++ {
+			for modeIdx, mode := range modes {
+				tests = append(tests, decodeTest{version, ErrorCorrectionLevel(level), mode, ContentLengthLimits[version][level][modeIdx]})
+			}
+		}
+	}
+
+	for version := 1; version <= 4; version++ {
+		for level := 0; level < 4; level++ {
+			for modeIdx, mode := range modes {
+				if limit := ContentLengthLimitsMicro[version][level][modeIdx]; limit != 0 {
+					tests = append(tests, decodeTest{-version, ErrorCorrectionLevel(level), mode, limit})
+				}
+			}
+		}
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("version %v, level %v, mode %v, size %v", test.version, test.level, test.mode, test.size)
+		t.Run(name, func(t *testing.T) {
+			var content string
+			switch test.mode {
+			case encode.EncodingModeNumeric:
+				content = GenerateNumericContent(test.size)
+			case encode.EncodingModeAlphaNumeric:
+				content = GenerateAlphaNumericContent(test.size)
+			case encode.EncodingModeByte:
+				content = GenerateByteContent(test.size)
+			case encode.EncodingModeKanji:
+				content = GenerateKanjiContent(test.size)
+			}
+
+			qr, err := CreateMultiMode([]*encode.EncodeBlock{{Mode: test.mode, Data: content}}, &QRCodeOptionsMultiMode{
+				ErrorLevel: test.level,
+				Version:    test.version,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Content != content {
+				t.Errorf("Expected %v, got %v", content, result.Content)
+			}
+			if result.Version != test.version {
+				t.Errorf("Expected version %v, got %v", test.version, result.Version)
+			}
+			if result.ErrorLevel != test.level {
+				t.Errorf("Expected level %v, got %v", test.level, result.ErrorLevel)
+			}
+		})
+	}
+}
+
+func TestDecodeMultiMode(t *testing.T) {
+	blocks := []*encode.EncodeBlock{
+		{Mode: encode.EncodingModeNumeric, Data: "1234567890"},
+		{Mode: encode.EncodingModeAlphaNumeric, Data: "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+		{Mode: encode.EncodingModeByte, Data: "abcdefghijklmnopqrstuvwxyz"},
+		{Mode: encode.EncodingModeKanji, Data: "茗"},
+		{Mode: encode.EncodingModeHanzi, Data: "简体中文"},
+		{Mode: encode.EncodingModeECI, SubMode: encode.EncodingModeByte, AssignmentNumber: encode.ISO8859_5, Data: "привет мир"},
+		{Mode: encode.EncodingModeECI, SubMode: encode.EncodingModeByte, AssignmentNumber: encode.UTF8, Data: "ååß∂∆"},
+	}
+
+	qr, err := CreateMultiMode(blocks, &QRCodeOptionsMultiMode{ErrorLevel: ErrorCorrectionLevelQuartile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := Decode(qr.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Blocks) != len(blocks) {
+		t.Fatalf("Expected %v blocks, got %v", len(blocks), len(result.Blocks))
+	}
+
+	for idx, block := range result.Blocks {
+		if *block != *blocks[idx] {
+			t.Errorf("Expected %v, got %v", *blocks[idx], *block)
+		}
+	}
+}
+
+func TestDecodeHanzi(t *testing.T) {
+	content := "条形码标签"
+
+	qr, err := Create(content, &QRCodeOptions{Mode: encode.EncodingModeHanzi})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := Decode(qr.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Blocks) != 1 || result.Blocks[0].Mode != encode.EncodingModeHanzi {
+		t.Errorf("Expected one hanzi block, got %v", result.Blocks)
+	}
+	if result.Content != content {
+		t.Errorf("Expected %v, got %v", content, result.Content)
+	}
+
+	// hanzi mode isn't supported by Micro QR and rMQR codes
+	for _, options := range []*QRCodeOptions{
+		{Mode: encode.EncodingModeHanzi, Version: M4},
+		{Mode: encode.EncodingModeHanzi, Version: R17x139, ErrorLevel: ErrorCorrectionLevelMedium},
+	} {
+		if _, err := Create(content, options); !errors.Is(err, encode.ErrVersionDoesNotSupportEncodingMode) {
+			t.Errorf("Expected %v, got %v", encode.ErrVersionDoesNotSupportEncodingMode, err)
+		}
+	}
+}
+
+func TestCreateHanziAutomatic(t *testing.T) {
+	tests := []struct {
+		content string
+		modes   []encode.EncodingMode
+	}{
+		{"条形码标签", []encode.EncodingMode{encode.EncodingModeHanzi}},
+		// the Japanese text keeps the kanji mode with the shorter header
+		{"漢字", []encode.EncodingMode{encode.EncodingModeKanji}},
+		// the Cyrillic characters of GB 2312 are left to UTF-8 with ECI
+		{"привет", []encode.EncodingMode{encode.EncodingModeECI}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			qr, err := Create(test.content, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Content != test.content {
+				t.Errorf("Expected %v, got %v", test.content, result.Content)
+			}
+			if len(result.Blocks) != len(test.modes) {
+				t.Fatalf("Expected %v blocks, got %v", len(test.modes), result.Blocks)
+			}
+			for idx, block := range result.Blocks {
+				if block.Mode != test.modes[idx] {
+					t.Errorf("Expected mode %v, got %v", test.modes[idx], block.Mode)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeGS1(t *testing.T) {
+	elements := []encode.GS1Element{{AI: "01", Value: "09506000134352"}, {AI: "17", Value: "251231"}, {AI: "10", Value: "LOT-7"}, {AI: "21", Value: "X%1"}}
+	blocks, err := encode.NewGS1Blocks(elements, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	qr, err := CreateMultiMode(blocks, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := Decode(qr.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Blocks[0].Mode != encode.EncodingModeFNC1First {
+		t.Errorf("Expected FNC1 first position header, got %v", result.Blocks[0].Mode)
+	}
+	expected := "0109506000134352" + "17251231" + "10LOT-7" + encode.GS + "21X%1"
+	if result.Content != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Content)
+	}
+}
+
+func TestDecodeCreate(t *testing.T) {
+	contents := []string{
+		"https://example.com",
+		"ORDER 12345678901234 café",
+		"ååß∂∆…¬å´œ¨®ˆπø∑´∆˚çå˜ß¬˚…¬√“‘ˆœ‘ø´®“π\\",
+		"あア亜 1234567890",
+	}
+
+	for _, content := range contents {
+		t.Run(content, func(t *testing.T) {
+			qr, err := Create(content, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Content != content {
+				t.Errorf("Expected %v, got %v", content, result.Content)
+			}
+		})
+	}
+}
+
+func TestDecodeDamaged(t *testing.T) {
+	content := "https://example.com/damaged"
+	qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh, Version: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// invert a 3x3 square of data modules and one bit of the format information
+	for idx := 15; idx < 18; idx++ {
+		for jdx := 15; jdx < 18; jdx++ {
+			qr.Data[idx][jdx].Value = !qr.Data[idx][jdx].Value
+		}
+	}
+	qr.Data[8][0].Value = !qr.Data[8][0].Value
+
+	result, err := Decode(qr.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Content != content {
+		t.Errorf("Expected %v, got %v", content, result.Content)
+	}
+
+	corrected := 0
+	for _, correction := range result.Corrections {
+		corrected += correction.Errors
+		if correction.Margin() < 0 {
+			t.Errorf("Expected non-negative margin, got %v", correction.Margin())
+		}
+	}
+	if corrected == 0 {
+		t.Error("Expected corrected errors")
+	}
+}
+
+func TestDecodeCorrections(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *QRCodeOptions
+		expected BlockCorrection
+		margin   int
+	}{
+		{"M1", &QRCodeOptions{MicroQR: true, Version: M1}, BlockCorrection{ErrorCorrectionCodewords: 2, MisdecodeProtection: 2}, 0},
+		{"M2-L", &QRCodeOptions{MicroQR: true, Version: M2}, BlockCorrection{ErrorCorrectionCodewords: 5, MisdecodeProtection: 3}, 1},
+		{"1-L", &QRCodeOptions{Version: 1}, BlockCorrection{ErrorCorrectionCodewords: 7, MisdecodeProtection: 3}, 2},
+		{"1-H", &QRCodeOptions{Version: 1, ErrorLevel: ErrorCorrectionLevelHigh}, BlockCorrection{ErrorCorrectionCodewords: 17, MisdecodeProtection: 1}, 8},
+		{"4-L", &QRCodeOptions{Version: 4}, BlockCorrection{ErrorCorrectionCodewords: 20}, 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := Create("12345", test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Corrections) == 0 {
+				t.Fatal("Expected corrections")
+			}
+			if result.Corrections[0] != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result.Corrections[0])
+			}
+			if margin := result.Corrections[0].Margin(); margin != test.margin {
+				t.Errorf("Expected %v, got %v", test.margin, margin)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		data := make([][]Cell, 20)
+		for idx := range data {
+			data[idx] = make([]Cell, 20)
+		}
+		if _, err := Decode(data); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("Expected %v, got %v", ErrInvalidSize, err)
+		}
+	})
+
+	t.Run("not square", func(t *testing.T) {
+		data := make([][]Cell, 21)
+		for idx := range data {
+			data[idx] = make([]Cell, 20)
+		}
+		if _, err := Decode(data); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("Expected %v, got %v", ErrInvalidSize, err)
+		}
+	})
+
+	t.Run("empty format", func(t *testing.T) {
+		data := make([][]Cell, 21)
+		for idx := range data {
+			data[idx] = make([]Cell, 21)
+		}
+		if _, err := Decode(data); !errors.Is(err, ErrInvalidFormatInfo) {
+			t.Errorf("Expected %v, got %v", ErrInvalidFormatInfo, err)
+		}
+	})
+
+	t.Run("too many errors", func(t *testing.T) {
+		qr, err := Create("1234567890", &QRCodeOptions{Version: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for idx := 9; idx < 21; idx++ {
+			for jdx := 9; jdx < 21; jdx++ {
+				qr.Data[idx][jdx].Value = !qr.Data[idx][jdx].Value
+			}
+		}
+		if _, err := Decode(qr.Data); !errors.Is(err, ErrTooManyErrors) {
+			t.Errorf("Expected %v, got %v", ErrTooManyErrors, err)
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package qrcode
 
+import "qrcode/encode"
+
 // calculateEDCData calculates error correction data for the given data block, version and error correction level
 func calculateEDCPoly(data []byte, codewords int) []byte {
 	dataPoly := &polynomial{data}
@@ -14,7 +16,9 @@ func getEDCData(data []byte, version int, errorLevel ErrorCorrectionLevel) []byt
 	var buf []byte
 
 	var blocks []ecBlock
-	if version < 0 {
+	if encode.IsRMQRVersion(version) {
+		blocks = rmqrErrorCorrectionBlocks[version-R7x43][errorLevel]
+	} else if version < 0 {
 		blocks = microErrorCorrectionBlocks[-version][errorLevel]
 	} else {
 		blocks = errorCorrectionBlocks[version][errorLevel]
@@ -60,6 +64,42 @@ var microErrorCorrectionCodeWords = [5][4]int{
 	{8, 10, 14, 0},
 }
 
+// Count of misdecode protection code words (p) for Micro QR Code version and error correction level (ISO/IEC 18004 Table 9).
+// These code words only detect errors, M1 has no error correction at all.
+// Structure: [version][error correction level]
+var microMisdecodeProtectionCodeWords = [5][4]int{
+	{0, 0, 0, 0}, // added to shift the index by 1
+	{2, 0, 0, 0},
+	{3, 2, 0, 0},
+	{2, 0, 0, 0},
+	{2, 0, 0, 0},
+}
+
+// Count of misdecode protection code words (p) for the versions 1-3 and error correction level (ISO/IEC 18004 Table 9),
+// the larger versions don't have them.
+// Structure: [version][error correction level]
+var misdecodeProtectionCodeWords = [4][4]int{
+	{0, 0, 0, 0}, // added to shift the index by 1
+	{3, 2, 1, 1},
+	{2, 0, 0, 0},
+	{1, 0, 0, 0},
+}
+
+// misdecodeProtection returns the number of misdecode protection code words of each error correction block
+// of the version with the error correction level. The rMQR versions are corrected without them.
+func misdecodeProtection(version int, errorLevel ErrorCorrectionLevel) int {
+	if encode.IsRMQRVersion(version) {
+		return 0
+	}
+	if version < 0 {
+		return microMisdecodeProtectionCodeWords[-version][errorLevel]
+	}
+	if version < len(misdecodeProtectionCodeWords) {
+		return misdecodeProtectionCodeWords[version][errorLevel]
+	}
+	return 0
+}
+
 // Count of error correction code words for each version and error correction level
 // Structure: [version][error correction level]
 var errorCorrectionCodeWords = [41][4]int{
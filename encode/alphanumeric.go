@@ -8,7 +8,16 @@ import (
 // alphaNumericConverter is a struct that uses for converting string to alphanumeric data.
 type alphaNumericEncoder struct{}
 
-func (*alphaNumericEncoder) Encode(content string, queue chan ValueBlock) error {
+func (e *alphaNumericEncoder) Encode(content string, queue chan ValueBlock) error {
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e *alphaNumericEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode writes the pairs of characters with 11 bits (the last single character takes 6 bits).
+func (*alphaNumericEncoder) encode(content string, write func(value, bits int)) error {
 	enc := &alphaNumericConverter{}
 	encoded, err := enc.Convert(content)
 	if err != nil {
@@ -29,10 +38,7 @@ func (*alphaNumericEncoder) Encode(content string, queue chan ValueBlock) error
 			number = number*45 + uint(encoded[i*2+1])
 		}
 
-		queue <- ValueBlock{
-			Bits:  dupletBytesSize,
-			Value: int(number),
-		}
+		write(int(number), dupletBytesSize)
 	}
 
 	return nil
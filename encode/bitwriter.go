@@ -0,0 +1,107 @@
+package encode
+
+// BitWriter packs the values of any bit length into bytes, the most significant bit first.
+// The zero value is an empty writer ready to use, Reset allows to reuse the buffer for the next QR code.
+type BitWriter struct {
+	data []byte
+	bits int
+}
+
+// BitEncoder is a QREncoder, which writes the data bits directly to the BitWriter.
+// The built-in encoders implement it, the encoders with only the channel-based Encode
+// are run in a goroutine and their blocks are copied to the writer.
+type BitEncoder interface {
+	QREncoder
+
+	// EncodeBits writes the data bits of the string to the writer.
+	EncodeBits(content string, w *BitWriter) error
+}
+
+// NewBitWriter creates a writer with the buffer for the capacity bytes.
+func NewBitWriter(capacity int) *BitWriter {
+	return &BitWriter{data: make([]byte, 0, capacity)}
+}
+
+// WriteBits appends the lowest bits of the value (up to the size of int).
+func (w *BitWriter) WriteBits(value, bits int) {
+	for bits > 0 {
+		free := 8 - w.bits%8
+		if free == 8 {
+			w.data = append(w.data, 0)
+		}
+
+		n := min(bits, free)
+		chunk := (value >> (bits - n)) & (1<<n - 1)
+		w.data[len(w.data)-1] |= byte(chunk << (free - n))
+
+		w.bits += n
+		bits -= n
+	}
+}
+
+// WriteBytes appends the bytes (8 bits each).
+func (w *BitWriter) WriteBytes(data []byte) {
+	if w.bits%8 == 0 {
+		w.data = append(w.data, data...)
+		w.bits += len(data) * 8
+		return
+	}
+
+	for _, b := range data {
+		w.WriteBits(int(b), 8)
+	}
+}
+
+// Len returns the number of written bits.
+func (w *BitWriter) Len() int {
+	return w.bits
+}
+
+// Pad fills the rest of the last byte with zero bits and returns the number of the added bits.
+func (w *BitWriter) Pad() int {
+	padding := (8 - w.bits%8) % 8
+	w.bits += padding
+	return padding
+}
+
+// Bytes returns the written bits packed into bytes, the last byte is padded with zero bits.
+// The slice is valid until the next write or Reset.
+func (w *BitWriter) Bytes() []byte {
+	return w.data
+}
+
+// Reset clears the writer, keeping the allocated buffer.
+func (w *BitWriter) Reset() {
+	w.data = w.data[:0]
+	w.bits = 0
+}
+
+// sendTo sends the written bits to the queue as 8-bit blocks and the block with the rest of the bits,
+// it's used by the channel-based methods kept for compatibility.
+func (w *BitWriter) sendTo(queue chan ValueBlock) {
+	full := w.bits / 8
+	for _, b := range w.data[:full] {
+		queue <- ValueBlock{
+			Value: int(b),
+			Bits:  8,
+		}
+	}
+
+	if rest := w.bits % 8; rest != 0 {
+		queue <- ValueBlock{
+			Value: int(w.data[full] >> (8 - rest)),
+			Bits:  rest,
+		}
+	}
+}
+
+// queueWriter returns the function, which sends the values to the queue,
+// so the built-in encoders share the code of Encode and EncodeBits.
+func queueWriter(queue chan ValueBlock) func(value, bits int) {
+	return func(value, bits int) {
+		queue <- ValueBlock{
+			Value: value,
+			Bits:  bits,
+		}
+	}
+}
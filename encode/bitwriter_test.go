@@ -0,0 +1,177 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBitWriterWriteBits(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []ValueBlock
+		expected []byte
+		bits     int
+	}{
+		{"empty", nil, nil, 0},
+		{"zero bits", []ValueBlock{{Value: 1, Bits: 0}}, nil, 0},
+		{"partial byte", []ValueBlock{{Value: 0b101, Bits: 3}}, []byte{0b10100000}, 3},
+		{"full byte", []ValueBlock{{Value: 0b0100, Bits: 4}, {Value: 0b1011, Bits: 4}}, []byte{0b01001011}, 8},
+		{"spanning bytes", []ValueBlock{{Value: 0b11, Bits: 2}, {Value: 123, Bits: 10}}, []byte{0b11000111, 0b10110000}, 12},
+		{"more than 16 bits", []ValueBlock{{Value: 1, Bits: 1}, {Value: 0xABCDE, Bits: 20}}, []byte{0b11010101, 0b11100110, 0b11110000}, 21},
+		{"value wider than bits", []ValueBlock{{Value: 0xFF, Bits: 4}}, []byte{0xF0}, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w BitWriter
+			for _, v := range test.blocks {
+				w.WriteBits(v.Value, v.Bits)
+			}
+
+			if !bytes.Equal(w.Bytes(), test.expected) {
+				t.Errorf("Expected %08b, got %08b", test.expected, w.Bytes())
+			}
+			if w.Len() != test.bits {
+				t.Errorf("Expected %v, got %v", test.bits, w.Len())
+			}
+		})
+	}
+}
+
+func TestBitWriterWriteBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   ValueBlock
+		data     []byte
+		expected []byte
+	}{
+		{"aligned", ValueBlock{}, []byte{0xAB, 0xCD}, []byte{0xAB, 0xCD}},
+		{"unaligned", ValueBlock{Value: 0b0100, Bits: 4}, []byte{0xAB, 0xCD}, []byte{0x4A, 0xBC, 0xD0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewBitWriter(4)
+			w.WriteBits(test.prefix.Value, test.prefix.Bits)
+			w.WriteBytes(test.data)
+
+			if !bytes.Equal(w.Bytes(), test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, w.Bytes())
+			}
+			if expected := test.prefix.Bits + len(test.data)*8; w.Len() != expected {
+				t.Errorf("Expected %v, got %v", expected, w.Len())
+			}
+		})
+	}
+}
+
+func TestBitWriterPad(t *testing.T) {
+	var w BitWriter
+	if padding := w.Pad(); padding != 0 {
+		t.Errorf("Expected %v, got %v", 0, padding)
+	}
+
+	w.WriteBits(0b111, 3)
+	if padding := w.Pad(); padding != 5 {
+		t.Errorf("Expected %v, got %v", 5, padding)
+	}
+	if w.Len() != 8 {
+		t.Errorf("Expected %v, got %v", 8, w.Len())
+	}
+
+	w.WriteBits(0b1, 1)
+	expected := []byte{0b11100000, 0b10000000}
+	if !bytes.Equal(w.Bytes(), expected) {
+		t.Errorf("Expected %08b, got %08b", expected, w.Bytes())
+	}
+
+	w.Reset()
+	if w.Len() != 0 || len(w.Bytes()) != 0 {
+		t.Errorf("Expected empty writer, got %v bits", w.Len())
+	}
+	w.WriteBits(0b1, 1)
+	if !bytes.Equal(w.Bytes(), []byte{0b10000000}) {
+		t.Errorf("Expected %08b, got %08b", []byte{0b10000000}, w.Bytes())
+	}
+}
+
+func TestBitWriterSendTo(t *testing.T) {
+	var w BitWriter
+	w.WriteBits(0b1011, 4)
+	w.WriteBits(0x3FF, 10)
+
+	queue := make(chan ValueBlock, 10)
+	result := make(chan []byte)
+	go GenerateData(queue, result)
+
+	w.sendTo(queue)
+	close(queue)
+
+	if data := <-result; !bytes.Equal(data, w.Bytes()) {
+		t.Errorf("Expected %08b, got %08b", w.Bytes(), data)
+	}
+}
+
+func TestBitEncoders(t *testing.T) {
+	tests := []struct {
+		enc     QREncoder
+		content string
+	}{
+		{&numericEncoder{}, "0123456789"},
+		{&alphaNumericEncoder{}, "HELLO WORLD"},
+		{&byteEncoder{}, "Hello, Wörld"},
+		{&kanjiEncoder{}, "あア亜"},
+		{eciEncoder{AssignmentNumber: UTF8, DataMode: EncodingModeByte}, "привет"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%T", test.enc), func(t *testing.T) {
+			bitEnc, ok := test.enc.(BitEncoder)
+			if !ok {
+				t.Fatalf("Expected %T to implement BitEncoder", test.enc)
+			}
+
+			queue := make(chan ValueBlock, 100)
+			result := make(chan []byte)
+			go GenerateData(queue, result)
+			if err := test.enc.Encode(test.content, queue); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			close(queue)
+			expected := <-result
+
+			var w BitWriter
+			if err := bitEnc.EncodeBits(test.content, &w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(w.Bytes(), expected) {
+				t.Errorf("Expected %v, got %v", expected, w.Bytes())
+			}
+			if w.Len() != test.enc.Size(test.content) {
+				t.Errorf("Expected %v, got %v", test.enc.Size(test.content), w.Len())
+			}
+		})
+	}
+}
+
+func TestEncodeToChannelEncoder(t *testing.T) {
+	// the test encoders have only the channel-based Encode
+	var w BitWriter
+	w.WriteBits(0b1, 1)
+	if err := encodeTo(utf8ByteEncoder{}, "é", &w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 1 + 0xC3 0xA9
+	expected := []byte{0b11100001, 0b11010100, 0b10000000}
+	if !bytes.Equal(w.Bytes(), expected) {
+		t.Errorf("Expected %08b, got %08b", expected, w.Bytes())
+	}
+
+	err := encodeTo(brokenEncoder{}, "é", &w)
+	if !errors.Is(err, ErrEncoderSizeMismatch) {
+		t.Errorf("Expected %v, got %v", ErrEncoderSizeMismatch, err)
+	}
+}
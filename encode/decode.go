@@ -0,0 +1,383 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var ErrInvalidData = errors.New("invalid data")
+
+// alphaNumericChars is a list of alphanumeric characters in the order of their values.
+const alphaNumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// bitReader reads values with an arbitrary number of bits from a byte array.
+type bitReader struct {
+	data   []byte
+	offset int
+}
+
+// Available returns the number of bits left to read.
+func (r *bitReader) Available() int {
+	return len(r.data)*8 - r.offset
+}
+
+// Read reads the value with the given number of bits (big-endian).
+func (r *bitReader) Read(bits int) (int, error) {
+	if bits > r.Available() {
+		return 0, fmt.Errorf("failed to read %d bits: %w", bits, ErrInvalidData)
+	}
+
+	value := 0
+	for i := 0; i < bits; i++ {
+		bit := r.data[r.offset/8] >> (7 - r.offset%8) & 1
+		value = value<<1 | int(bit)
+		r.offset++
+	}
+
+	return value, nil
+}
+
+// readMode reads the mode indicator. The terminator is returned as the zero mode.
+func readMode(reader *bitReader, version int) (EncodingMode, error) {
+	if IsRMQRVersion(version) {
+		return readModeRMQR(reader)
+	}
+
+	if version > 0 {
+		value, err := reader.Read(4)
+		if err != nil {
+			return 0, err
+		}
+		return EncodingMode(value), nil
+	}
+
+	bits := -version - 1
+	value, err := reader.Read(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	for mode, blocks := range modeVersionValueBlockMap {
+		block := blocks[-version-1]
+		if block.Bits == bits && block.Value == value && (mode == EncodingModeNumeric || bits > 0) {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown mode indicator %b: %w", value, ErrInvalidData)
+}
+
+// readAssignmentNumber reads the ECI designator (1, 2 or 3 bytes).
+func readAssignmentNumber(reader *bitReader) (uint, error) {
+	first, err := reader.Read(8)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case first&0x80 == 0:
+		return uint(first), nil
+	case first&0xC0 == 0x80:
+		second, err := reader.Read(8)
+		if err != nil {
+			return 0, err
+		}
+		return uint(first&0x3F)<<8 | uint(second), nil
+	case first&0xE0 == 0xC0:
+		rest, err := reader.Read(16)
+		if err != nil {
+			return 0, err
+		}
+		return uint(first&0x1F)<<16 | uint(rest), nil
+	}
+
+	return 0, fmt.Errorf("invalid ECI designator %b: %w", first, ErrInvalidData)
+}
+
+// readStructuredAppend reads the header of the Structured Append symbol after the mode indicator.
+func readStructuredAppend(reader *bitReader) (*EncodeBlock, error) {
+	header, err := reader.Read(structuredAppendBits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncodeBlock{
+		Mode:          EncodingModeStructuredAppend,
+		SequenceIndex: header >> 12,
+		SequenceTotal: header>>8&0x0F + 1,
+		Parity:        byte(header),
+	}, nil
+}
+
+// decodeNumeric reads count digits packed by groups of three.
+func decodeNumeric(reader *bitReader, count int) (string, error) {
+	var sb strings.Builder
+	for count > 0 {
+		digits := min(count, 3)
+		value, err := reader.Read(1 + digits*3)
+		if err != nil {
+			return "", err
+		}
+
+		group := fmt.Sprintf("%0*d", digits, value)
+		if len(group) != digits {
+			return "", fmt.Errorf("invalid numeric value %d: %w", value, ErrInvalidData)
+		}
+		sb.WriteString(group)
+		count -= digits
+	}
+
+	return sb.String(), nil
+}
+
+// decodeAlphaNumeric reads count alphanumeric characters packed by pairs.
+func decodeAlphaNumeric(reader *bitReader, count int) (string, error) {
+	var sb strings.Builder
+	for count > 0 {
+		if count == 1 {
+			value, err := reader.Read(6)
+			if err != nil {
+				return "", err
+			}
+			if value >= len(alphaNumericChars) {
+				return "", fmt.Errorf("invalid alphanumeric value %d: %w", value, ErrInvalidData)
+			}
+			sb.WriteByte(alphaNumericChars[value])
+			break
+		}
+
+		value, err := reader.Read(11)
+		if err != nil {
+			return "", err
+		}
+		if value >= len(alphaNumericChars)*len(alphaNumericChars) {
+			return "", fmt.Errorf("invalid alphanumeric value %d: %w", value, ErrInvalidData)
+		}
+		sb.WriteByte(alphaNumericChars[value/45])
+		sb.WriteByte(alphaNumericChars[value%45])
+		count -= 2
+	}
+
+	return sb.String(), nil
+}
+
+// decodeBytes reads count bytes and decodes them with the given character set.
+func decodeBytes(reader *bitReader, count int, enc encoding.Encoding) (string, error) {
+	buf := make([]byte, count)
+	for i := range buf {
+		value, err := reader.Read(8)
+		if err != nil {
+			return "", err
+		}
+		buf[i] = byte(value)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode bytes: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+// decodeKanji reads count kanji characters (13 bits each) and converts them from Shift JIS.
+func decodeKanji(reader *bitReader, count int) (string, error) {
+	buf := make([]byte, 0, count*2)
+	for i := 0; i < count; i++ {
+		value, err := reader.Read(13)
+		if err != nil {
+			return "", err
+		}
+
+		sjis := (value/0xC0)<<8 | value%0xC0
+		if sjis+0x8140 <= 0x9FFC {
+			sjis += 0x8140
+		} else {
+			sjis += 0xC140
+		}
+		buf = append(buf, byte(sjis>>8), byte(sjis))
+	}
+
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode kanji: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+// decodeHanzi reads count hanzi characters (13 bits each) and converts them from GB 2312.
+func decodeHanzi(reader *bitReader, count int) (string, error) {
+	buf := make([]byte, 0, count*2)
+	for i := 0; i < count; i++ {
+		value, err := reader.Read(13)
+		if err != nil {
+			return "", err
+		}
+
+		gb := (value/0x60)<<8 | value%0x60
+		if gb < 0x0A00 {
+			gb += 0xA1A1
+		} else {
+			gb += 0xA6A1
+		}
+		buf = append(buf, byte(gb>>8), byte(gb))
+	}
+
+	decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode hanzi: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+// DecodeData parses the data codewords of the QR Code with the given version back to the encode blocks.
+// It's the reverse operation for the EncodeBlock.Encode: the data is read until the terminator or the end of the data.
+// Byte blocks after an ECI designator are returned as ECI blocks with the assignment number of the designator.
+// The Structured Append and FNC1 headers are returned as blocks without data. In the FNC1 mode,
+// "%" of the alphanumeric blocks is returned as GS and "%%" as "%".
+func DecodeData(data []byte, version int) ([]*EncodeBlock, error) {
+	if !isValidVersion(version) {
+		return nil, ErrVersionInvalid{version}
+	}
+
+	reader := &bitReader{data: data}
+	var blocks []*EncodeBlock
+
+	eciActive := false
+	fnc1 := false
+	var assignmentNumber uint
+	var charset encoding.Encoding = charmap.ISO8859_1
+
+	for {
+		modeBits := 4
+		if IsRMQRVersion(version) {
+			modeBits = rmqrModeBits
+		} else if version < 0 {
+			modeBits = -version - 1
+		}
+		if reader.Available() < modeBits {
+			break
+		}
+
+		mode, err := readMode(reader, version)
+		if err != nil {
+			return nil, err
+		}
+
+		// terminator
+		if mode == 0 && version > 0 {
+			break
+		}
+
+		if mode == EncodingModeStructuredAppend && version > 0 {
+			block, err := readStructuredAppend(reader)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if (mode == EncodingModeFNC1First || mode == EncodingModeFNC1Second) && version > 0 {
+			block := &EncodeBlock{Mode: mode}
+			if mode == EncodingModeFNC1Second {
+				indicator, err := reader.Read(headerModeBits[mode])
+				if err != nil {
+					return nil, err
+				}
+				block.ApplicationIndicator = byte(indicator)
+			}
+			fnc1 = true
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if mode == EncodingModeECI {
+			assignmentNumber, err = readAssignmentNumber(reader)
+			if err != nil {
+				return nil, err
+			}
+
+			enc, ok := lookupECIEncoding(assignmentNumber)
+			if !ok || enc == nil {
+				return nil, fmt.Errorf("%w: %d", ErrUnknownAssignmentNumber, assignmentNumber)
+			}
+			charset = enc
+			eciActive = true
+			continue
+		}
+
+		block := &EncodeBlock{Mode: mode}
+		lengthBits, err := block.GetLengthBits(version)
+		if err != nil {
+			if errors.Is(err, ErrUnknownEncodingMode) {
+				return nil, fmt.Errorf("unknown mode %d: %w", mode, ErrInvalidData)
+			}
+			return nil, err
+		}
+
+		if mode == EncodingModeHanzi {
+			subset, err := reader.Read(hanziSubsetBits)
+			if err != nil {
+				return nil, err
+			}
+			if subset != HanziSubsetGB2312 {
+				return nil, fmt.Errorf("unknown hanzi subset %d: %w", subset, ErrInvalidData)
+			}
+		}
+
+		// Micro QR codes have no terminator mode, the terminator is the rest of zero bits
+		if reader.Available() < lengthBits {
+			if version < 0 {
+				break
+			}
+			return nil, fmt.Errorf("failed to read length: %w", ErrInvalidData)
+		}
+
+		count, err := reader.Read(lengthBits)
+		if err != nil {
+			return nil, err
+		}
+
+		if count == 0 && version < 0 {
+			break
+		}
+
+		switch mode {
+		case EncodingModeNumeric:
+			block.Data, err = decodeNumeric(reader, count)
+		case EncodingModeAlphaNumeric:
+			block.Data, err = decodeAlphaNumeric(reader, count)
+			if fnc1 {
+				block.Data = fnc1AlphaNumericDecode(block.Data)
+			}
+		case EncodingModeByte:
+			block.Data, err = decodeBytes(reader, count, charset)
+			if eciActive {
+				block.Mode = EncodingModeECI
+				block.SubMode = EncodingModeByte
+				block.AssignmentNumber = assignmentNumber
+			}
+		case EncodingModeKanji:
+			block.Data, err = decodeKanji(reader, count)
+		case EncodingModeHanzi:
+			block.Data, err = decodeHanzi(reader, count)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
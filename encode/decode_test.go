@@ -0,0 +1,162 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBitReader(t *testing.T) {
+	reader := &bitReader{data: []byte{0b10110011, 0b01010101}}
+
+	tests := []struct {
+		bits  int
+		value int
+	}{
+		{1, 1},
+		{3, 0b011},
+		{6, 0b001101},
+		{6, 0b010101},
+	}
+
+	for _, test := range tests {
+		value, err := reader.Read(test.bits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if value != test.value {
+			t.Errorf("Expected %b, got %b", test.value, value)
+		}
+	}
+
+	if _, err := reader.Read(1); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("Expected %v, got %v", ErrInvalidData, err)
+	}
+}
+
+func TestReadAssignmentNumber(t *testing.T) {
+	tests := []struct {
+		data   []byte
+		number uint
+	}{
+		{[]byte{0b00011010}, 26},
+		{[]byte{0b10000011, 0b11100111}, 999},
+		{[]byte{0b11001111, 0b01000010, 0b00111111}, 999999},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.number), func(t *testing.T) {
+			number, err := readAssignmentNumber(&bitReader{data: test.data})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if number != test.number {
+				t.Errorf("Expected %v, got %v", test.number, number)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := readAssignmentNumber(&bitReader{data: []byte{0b11100000, 0, 0}})
+		if !errors.Is(err, ErrInvalidData) {
+			t.Errorf("Expected %v, got %v", ErrInvalidData, err)
+		}
+	})
+}
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		version int
+		blocks  []EncodeBlock
+	}{
+		{1, []EncodeBlock{{Mode: EncodingModeNumeric, Data: "01234567"}}},
+		{1, []EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "HELLO WORLD"}}},
+		{1, []EncodeBlock{{Mode: EncodingModeByte, Data: "hello, wörld"}}},
+		{1, []EncodeBlock{{Mode: EncodingModeKanji, Data: "茗荷あア亜"}}},
+		{10, []EncodeBlock{{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: UTF8, Data: "привет мир"}}},
+		{2, []EncodeBlock{{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: ISO8859_16, Data: "Știință €"}}},
+		{
+			27,
+			[]EncodeBlock{
+				{Mode: EncodingModeNumeric, Data: "123"},
+				{Mode: EncodingModeAlphaNumeric, Data: "ABC"},
+				{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: ISO8859_5, Data: "АВГДЕ"},
+			},
+		},
+		{
+			2,
+			[]EncodeBlock{
+				{Mode: EncodingModeStructuredAppend, SequenceIndex: 2, SequenceTotal: 16, Parity: 0xA5},
+				{Mode: EncodingModeAlphaNumeric, Data: "PART"},
+			},
+		},
+		{1, []EncodeBlock{{Mode: EncodingModeFNC1First}, {Mode: EncodingModeNumeric, Data: "0109506000134352"}}},
+		{1, []EncodeBlock{{Mode: EncodingModeFNC1Second, ApplicationIndicator: 37}, {Mode: EncodingModeByte, Data: "abc"}}},
+
+		// Micro QR
+		{-1, []EncodeBlock{{Mode: EncodingModeNumeric, Data: "12345"}}},
+		{-2, []EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "AB"}, {Mode: EncodingModeNumeric, Data: "1"}}},
+		{-3, []EncodeBlock{{Mode: EncodingModeByte, Data: "abc"}}},
+		{-4, []EncodeBlock{{Mode: EncodingModeKanji, Data: "亜亜"}, {Mode: EncodingModeAlphaNumeric, Data: "A"}}},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("version %v, blocks %v", test.version, test.blocks)
+		t.Run(name, func(t *testing.T) {
+			queue := make(chan ValueBlock, 100)
+			result := make(chan []byte)
+			go GenerateData(queue, result)
+
+			for idx := range test.blocks {
+				if _, err := test.blocks[idx].Encode(test.version, queue); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			close(queue)
+			data := <-result
+
+			// add the terminator and padding as a Micro QR code would have
+			data = append(data, 0, 0b11101100)
+
+			blocks, err := DecodeData(data, test.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(blocks) != len(test.blocks) {
+				t.Fatalf("Expected %v blocks, got %v", len(test.blocks), len(blocks))
+			}
+
+			for idx, block := range blocks {
+				if *block != test.blocks[idx] {
+					t.Errorf("Expected %v, got %v", test.blocks[idx], *block)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeDataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		version int
+		err     error
+	}{
+		{"invalid version", []byte{0}, 0, ErrVersionInvalid{0}},
+		{"unknown mode", []byte{0b11110000}, 1, ErrInvalidData},
+		{"truncated length", []byte{0b00010000}, 1, ErrInvalidData},
+		{"truncated data", []byte{0b01000000, 0b10000000}, 1, ErrInvalidData},
+		{"invalid alphanumeric", []byte{0b00100000, 0b00111111, 0b11111111}, 1, ErrInvalidData},
+		{"unknown assignment number", []byte{0b01110111, 0b11110000}, 1, ErrUnknownAssignmentNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DecodeData(test.data, test.version)
+			if !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
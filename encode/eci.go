@@ -50,7 +50,8 @@ const (
 	UTF32LittleEndian = 35
 )
 
-// assigmentNumbersEncodings is a map of ECI assignment numbers to their respective encodings.
+// assigmentNumbersEncodings is a map of ECI assignment numbers to their respective encodings,
+// see RegisterECIEncoding.
 var assigmentNumbersEncodings = map[uint]encoding.Encoding{
 	CP437:             charmap.CodePage437,
 	ISO8859_1:         charmap.ISO8859_1,
@@ -70,7 +71,7 @@ var assigmentNumbersEncodings = map[uint]encoding.Encoding{
 	ISO8859_13:        charmap.ISO8859_13,
 	ISO8859_14:        charmap.ISO8859_14,
 	ISO8859_15:        charmap.ISO8859_15,
-	ISO8859_16:        charmap.Windows1258,
+	ISO8859_16:        charmap.ISO8859_16,
 	REVERSED_19:       nil, // does not support
 	ShiftJIS:          japanese.ShiftJIS,
 	Windows1250:       charmap.Windows1250,
@@ -90,7 +91,24 @@ var assigmentNumbersEncodings = map[uint]encoding.Encoding{
 	UTF32LittleEndian: utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM),
 }
 
+// MaxAssignmentNumber is the largest ECI assignment number, which can be written with the 3-byte designator.
+const MaxAssignmentNumber = 999999
+
 var ErrUnknownAssignmentNumber = fmt.Errorf("unknown assignment number")
+var ErrInvalidAssignmentNumber = fmt.Errorf("assignment number must be between 0 and %d", MaxAssignmentNumber)
+
+// eciDesignator returns the bytes of the ECI designator of the assignment number:
+// 0bbbbbbb for 0-127, 10bbbbbb bbbbbbbb for 128-16383 and 110bbbbb bbbbbbbb bbbbbbbb for 16384-999999.
+func eciDesignator(assignmentNumber uint) []byte {
+	switch {
+	case assignmentNumber < 1<<7:
+		return []byte{byte(assignmentNumber)}
+	case assignmentNumber < 1<<14:
+		return []byte{0b10<<6 | byte(assignmentNumber>>8), byte(assignmentNumber)}
+	default:
+		return []byte{0b110<<5 | byte(assignmentNumber>>16), byte(assignmentNumber >> 8), byte(assignmentNumber)}
+	}
+}
 
 // eciEncoder is an encoder for ECI (Extended Channel Interpretation) mode.
 type eciEncoder struct {
@@ -99,7 +117,16 @@ type eciEncoder struct {
 }
 
 func (e eciEncoder) Encode(content string, queue chan ValueBlock) error {
-	enc, ok := assigmentNumbersEncodings[e.AssignmentNumber]
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e eciEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode converts the content to the charset of the assignment number and writes each byte with 8 bits.
+func (e eciEncoder) encode(content string, write func(value, bits int)) error {
+	enc, ok := lookupECIEncoding(e.AssignmentNumber)
 	if !ok {
 		return fmt.Errorf("unknown assignment number: %d", e.AssignmentNumber)
 	}
@@ -114,17 +141,14 @@ func (e eciEncoder) Encode(content string, queue chan ValueBlock) error {
 	}
 
 	for _, b := range buf {
-		queue <- ValueBlock{
-			Bits:  8,
-			Value: int(b),
-		}
+		write(int(b), 8)
 	}
 
 	return nil
 }
 
 func (e eciEncoder) CanEncode(content string) bool {
-	enc, ok := assigmentNumbersEncodings[e.AssignmentNumber]
+	enc, ok := lookupECIEncoding(e.AssignmentNumber)
 	if !ok || enc == nil {
 		return false
 	}
@@ -135,7 +159,7 @@ func (e eciEncoder) CanEncode(content string) bool {
 }
 
 func (e eciEncoder) Size(content string) int {
-	enc, ok := assigmentNumbersEncodings[e.AssignmentNumber]
+	enc, ok := lookupECIEncoding(e.AssignmentNumber)
 	if !ok || enc == nil {
 		return 0
 	}
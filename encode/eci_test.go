@@ -1,6 +1,11 @@
 package encode
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
 
 func TestEciEncoder_Encode(t *testing.T) {
 	tests := []struct {
@@ -21,6 +26,16 @@ func TestEciEncoder_Encode(t *testing.T) {
 				{Bits: 8, Value: 162},
 			},
 		},
+		{
+			assignmentNumber: 18,
+			content:          "ȘțĂ€",
+			expected: []ValueBlock{
+				{Bits: 8, Value: 0xAA},
+				{Bits: 8, Value: 0xFE},
+				{Bits: 8, Value: 0xC3},
+				{Bits: 8, Value: 0xA4},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -172,3 +187,45 @@ func TestEciEncoder_Mode(t *testing.T) {
 		t.Errorf("expected %v, got %v", EncodingModeECI, eci.Mode())
 	}
 }
+
+func TestEciDesignator(t *testing.T) {
+	tests := []struct {
+		assignmentNumber uint
+		designator       []byte
+	}{
+		{0, []byte{0b00000000}},
+		{127, []byte{0b01111111}},
+		{128, []byte{0b10000000, 0b10000000}},
+		{16383, []byte{0b10111111, 0b11111111}},
+		{16384, []byte{0b11000000, 0b01000000, 0b00000000}},
+		{MaxAssignmentNumber, []byte{0b11001111, 0b01000010, 0b00111111}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.assignmentNumber), func(t *testing.T) {
+			designator := eciDesignator(test.assignmentNumber)
+			if !bytes.Equal(designator, test.designator) {
+				t.Errorf("Expected %08b, got %08b", test.designator, designator)
+			}
+
+			// the designator is read back by the decoder
+			number, err := readAssignmentNumber(&bitReader{data: designator})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if number != test.assignmentNumber {
+				t.Errorf("Expected %v, got %v", test.assignmentNumber, number)
+			}
+
+			block := &EncodeBlock{Mode: EncodingModeECI, AssignmentNumber: test.assignmentNumber}
+			if bits := block.GetModeBits(1); bits != 8+len(test.designator)*8 {
+				t.Errorf("Expected %v mode bits, got %v", 8+len(test.designator)*8, bits)
+			}
+		})
+	}
+
+	block := &EncodeBlock{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: MaxAssignmentNumber + 1, Data: "abc"}
+	if _, err := block.CalculateDataBitsCount(); !errors.Is(err, ErrInvalidAssignmentNumber) {
+		t.Errorf("Expected %v, got %v", ErrInvalidAssignmentNumber, err)
+	}
+}
@@ -16,8 +16,29 @@ const (
 	EncodingModeByte         EncodingMode = 4
 	EncodingModeKanji        EncodingMode = 8
 	EncodingModeECI          EncodingMode = 7
+	// EncodingModeHanzi is the mode of the GB 2312 characters (GB/T 18284), it's supported only by QR Codes
+	// (not Micro QR or rMQR) and not all readers can decode it.
+	EncodingModeHanzi EncodingMode = 13
+
+	// Header modes have no data and no length, they are written before the data blocks.
+	// EncodingModeStructuredAppend is the header of a symbol in a Structured Append sequence,
+	// see NewStructuredAppendBlock.
+	EncodingModeStructuredAppend EncodingMode = 3
+	// EncodingModeFNC1First marks the data formatted according to the GS1 General Specifications,
+	// see NewGS1Blocks.
+	EncodingModeFNC1First EncodingMode = 5
+	// EncodingModeFNC1Second marks the data formatted according to an industry application
+	// with the application indicator assigned by AIM, see NewFNC1SecondBlock.
+	EncodingModeFNC1Second EncodingMode = 9
 )
 
+// Count of bits after the mode indicator for the header modes.
+var headerModeBits = map[EncodingMode]int{
+	EncodingModeStructuredAppend: structuredAppendBits,
+	EncodingModeFNC1First:        0,
+	EncodingModeFNC1Second:       8,
+}
+
 // Count of length bits for each version and encoding mode.
 // Structure: [encoding mode][version range number]
 // Version range number is:
@@ -30,14 +51,16 @@ var encodingModeLengthMap = map[EncodingMode][7]int{
 	EncodingModeAlphaNumeric: {0, 3, 4, 5, 9, 11, 13},
 	EncodingModeByte:         {0, 0, 4, 5, 8, 16, 16},
 	EncodingModeKanji:        {0, 0, 3, 4, 8, 10, 12},
+	EncodingModeHanzi:        {0, 0, 0, 0, 8, 10, 12},
 }
 
-// Map of encoding mode to encoder.
+// Map of encoding mode to encoder, see RegisterEncoder.
 var encodingModeEncoderMap = map[EncodingMode]QREncoder{
 	EncodingModeNumeric:      &numericEncoder{},
 	EncodingModeAlphaNumeric: &alphaNumericEncoder{},
 	EncodingModeByte:         &byteEncoder{},
 	EncodingModeKanji:        &kanjiEncoder{},
+	EncodingModeHanzi:        &hanziEncoder{},
 }
 
 // Map of encoding mode and version to value block for Micro QR code.
@@ -115,11 +138,41 @@ type EncodeBlock struct {
 	// Only for ECI mode
 	SubMode          EncodingMode
 	AssignmentNumber uint
+
+	// Only for Structured Append mode
+	SequenceIndex int
+	SequenceTotal int
+	Parity        byte
+
+	// Only for FNC1 second position mode
+	ApplicationIndicator byte
+
+	// Only for byte mode: Data holds the octets, which are written as is, without the charset conversion
+	// and the registered byte encoder, see NewBytesBlock.
+	Raw bool
+}
+
+// NewBytesBlock creates the byte mode block with the binary data (e.g. protobuf or compressed data).
+func NewBytesBlock(data []byte) *EncodeBlock {
+	return &EncodeBlock{
+		Mode: EncodingModeByte,
+		Data: string(data),
+		Raw:  true,
+	}
+}
+
+// isRaw returns true if the block has the binary data instead of the text.
+func (b *EncodeBlock) isRaw() bool {
+	return b.Mode == EncodingModeByte && b.Raw
 }
 
 // GetSymbolsCount returns the number of symbols in the block.
-// The number of symbols is the number of characters for all modes except ECI (it's the number of bytes).
+// The number of symbols is the number of characters for all modes except ECI and byte (it's the number of bytes).
 func (b *EncodeBlock) GetSymbolsCount() int {
+	if b.isRaw() {
+		return len(b.Data)
+	}
+
 	if b.Mode == EncodingModeECI {
 		enc := eciEncoder{
 			AssignmentNumber: b.AssignmentNumber,
@@ -128,11 +181,33 @@ func (b *EncodeBlock) GetSymbolsCount() int {
 		return enc.Size(b.Data) / 8
 	}
 
+	// a registered byte encoder may convert the content to another charset
+	if b.Mode == EncodingModeByte {
+		if enc, ok := lookupEncoder(b.Mode); ok {
+			return enc.Size(b.Data) / 8
+		}
+	}
+
 	return utf8.RuneCountInString(b.Data)
 }
 
 // CalculateDataBitsCount returns the number of data bits for the block.
 func (b *EncodeBlock) CalculateDataBitsCount() (int, error) {
+	if bits, ok := headerModeBits[b.Mode]; ok {
+		return bits, nil
+	}
+
+	if b.Mode == EncodingModeECI && b.AssignmentNumber > MaxAssignmentNumber {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidAssignmentNumber, b.AssignmentNumber)
+	}
+
+	if b.isRaw() {
+		if len(b.Data) == 0 {
+			return 0, fmt.Errorf("failed to calculate data bits count: %w", ErrCannotDeterminEncodingMode)
+		}
+		return len(b.Data) * 8, nil
+	}
+
 	var enc QREncoder
 
 	if b.Mode == EncodingModeECI {
@@ -142,7 +217,7 @@ func (b *EncodeBlock) CalculateDataBitsCount() (int, error) {
 		}
 	} else {
 		var ok bool
-		enc, ok = encodingModeEncoderMap[b.Mode]
+		enc, ok = lookupEncoder(b.Mode)
 		if !ok {
 			return 0, ErrUnknownEncodingMode
 		}
@@ -159,7 +234,7 @@ func (b *EncodeBlock) CalculateDataBitsCount() (int, error) {
 
 // GetLengthBits returns the number of length bits for the block.
 func (b *EncodeBlock) GetLengthBits(version int) (int, error) {
-	if version < -4 || version > 40 || version == 0 {
+	if !isValidVersion(version) {
 		return 0, ErrVersionInvalid{version}
 	}
 
@@ -168,11 +243,26 @@ func (b *EncodeBlock) GetLengthBits(version int) (int, error) {
 		mode = b.SubMode
 	}
 
+	if _, ok := headerModeBits[mode]; ok {
+		if version < 0 || (IsRMQRVersion(version) && mode == EncodingModeStructuredAppend) {
+			return 0, ErrVersionDoesNotSupportEncodingMode
+		}
+		return 0, nil
+	}
+
 	dataLength, ok := encodingModeLengthMap[mode]
 	if !ok {
 		return 0, ErrUnknownEncodingMode
 	}
 
+	if IsRMQRVersion(version) {
+		idx, ok := rmqrLengthBitsIndex[mode]
+		if !ok {
+			return 0, ErrVersionDoesNotSupportEncodingMode
+		}
+		return rmqrLengthBits[version-RMQRVersionMin][idx], nil
+	}
+
 	if version <= 0 {
 		bits := dataLength[-version-1]
 		if bits == 0 {
@@ -191,53 +281,92 @@ func (b *EncodeBlock) GetLengthBits(version int) (int, error) {
 }
 
 // GetModeBits returns the number of mode bits for the block.
+// The ECI mode indicator is followed by the designator (1-3 bytes) and the mode indicator of the data.
 func (b *EncodeBlock) GetModeBits(version int) int {
+	if IsRMQRVersion(version) {
+		if b.Mode == EncodingModeECI {
+			return 2*rmqrModeBits + len(eciDesignator(b.AssignmentNumber))*8
+		}
+		return rmqrModeBits
+	}
+
 	if version < 0 {
 		return -version - 1
 	}
 
 	if b.Mode == EncodingModeECI {
-		return 16
+		return 8 + len(eciDesignator(b.AssignmentNumber))*8
+	}
+	if b.Mode == EncodingModeHanzi {
+		return 4 + hanziSubsetBits
 	}
 	return 4
 }
 
+// WritePrefix writes the prefix of the block: the mode, the mode specific header and the count of items.
+func (b *EncodeBlock) WritePrefix(version, lengthBits, itemsCount int, w *BitWriter) {
+	if IsRMQRVersion(version) {
+		w.WriteBits(rmqrModeIndicators[b.Mode], rmqrModeBits)
+	} else if version < 0 {
+		prefix := modeVersionValueBlockMap[b.Mode][-version-1]
+		w.WriteBits(prefix.Value, prefix.Bits)
+	} else {
+		w.WriteBits(int(b.Mode), 4)
+	}
+
+	if b.Mode == EncodingModeStructuredAppend {
+		w.WriteBits(b.SequenceIndex, 4)
+		w.WriteBits(b.SequenceTotal-1, 4)
+		w.WriteBits(int(b.Parity), 8)
+	}
+
+	if b.Mode == EncodingModeFNC1Second {
+		w.WriteBits(int(b.ApplicationIndicator), 8)
+	}
+
+	if b.Mode == EncodingModeHanzi {
+		w.WriteBits(HanziSubsetGB2312, hanziSubsetBits)
+	}
+
+	if b.Mode == EncodingModeECI {
+		w.WriteBytes(eciDesignator(b.AssignmentNumber))
+		if IsRMQRVersion(version) {
+			w.WriteBits(rmqrModeIndicators[b.SubMode], rmqrModeBits)
+		} else {
+			w.WriteBits(int(b.SubMode), 4)
+		}
+	}
+
+	w.WriteBits(itemsCount, lengthBits)
+}
+
 // GetBytesPrefix returns the prefix of the block in bytes.
 // The prefix consists of the mode and the count of items.
+//
+// Deprecated: use WritePrefix.
 func (b *EncodeBlock) GetBytesPrefix(
 	version,
 	lengthBits,
 	itemsCount int,
 	queue chan ValueBlock,
 ) {
-	if version < 0 {
-		queue <- modeVersionValueBlockMap[b.Mode][-version-1]
-	} else {
-		queue <- ValueBlock{
-			Value: int(b.Mode),
-			Bits:  4,
-		}
-	}
+	var w BitWriter
+	b.WritePrefix(version, lengthBits, itemsCount, &w)
+	w.sendTo(queue)
+}
 
-	if b.Mode == EncodingModeECI {
-		queue <- ValueBlock{
-			Value: int(b.AssignmentNumber),
-			Bits:  8,
-		}
-		queue <- ValueBlock{
-			Value: int(b.SubMode),
-			Bits:  4,
-		}
+// WriteData writes the content transformed according to the encoding mode.
+func (b *EncodeBlock) WriteData(w *BitWriter) error {
+	// the headers are written with the prefix
+	if _, ok := headerModeBits[b.Mode]; ok {
+		return nil
 	}
 
-	queue <- ValueBlock{
-		Value: itemsCount,
-		Bits:  lengthBits,
+	if b.isRaw() {
+		w.WriteBytes([]byte(b.Data))
+		return nil
 	}
-}
 
-// EncodeData transforms the content to the byte array according to the encoding mode.
-func (b *EncodeBlock) EncodeData(queue chan ValueBlock) error {
 	var enc QREncoder
 	if b.Mode == EncodingModeECI {
 		enc = eciEncoder{
@@ -246,22 +375,34 @@ func (b *EncodeBlock) EncodeData(queue chan ValueBlock) error {
 		}
 	} else {
 		var ok bool
-		enc, ok = encodingModeEncoderMap[b.Mode]
+		enc, ok = lookupEncoder(b.Mode)
 		if !ok {
 			return ErrUnknownEncodingMode
 		}
 	}
 
-	err := enc.Encode(b.Data, queue)
-	if err != nil {
+	if err := encodeTo(enc, b.Data, w); err != nil {
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
 
 	return nil
 }
 
-// Encode encodes the block data to bytes.
-func (b *EncodeBlock) Encode(version int, queue chan ValueBlock) (int, error) {
+// EncodeData transforms the content to the byte array according to the encoding mode.
+//
+// Deprecated: use WriteData.
+func (b *EncodeBlock) EncodeData(queue chan ValueBlock) error {
+	var w BitWriter
+	if err := b.WriteData(&w); err != nil {
+		return err
+	}
+	w.sendTo(queue)
+
+	return nil
+}
+
+// EncodeBits writes the block (the prefix and the data) and returns the number of the written bits.
+func (b *EncodeBlock) EncodeBits(version int, w *BitWriter) (int, error) {
 	symbolsCount := b.GetSymbolsCount()
 	lengthBits, err := b.GetLengthBits(version)
 
@@ -273,8 +414,8 @@ func (b *EncodeBlock) Encode(version int, queue chan ValueBlock) (int, error) {
 		return 0, fmt.Errorf("failed to calculate data bits count: %w", err)
 	}
 
-	b.GetBytesPrefix(version, lengthBits, symbolsCount, queue)
-	err = b.EncodeData(queue)
+	b.WritePrefix(version, lengthBits, symbolsCount, w)
+	err = b.WriteData(w)
 	if err != nil {
 		return 0, fmt.Errorf("failed to encode data: %w", err)
 	}
@@ -284,7 +425,22 @@ func (b *EncodeBlock) Encode(version int, queue chan ValueBlock) (int, error) {
 	return allBits, nil
 }
 
+// Encode encodes the block data to bytes.
+//
+// Deprecated: use EncodeBits.
+func (b *EncodeBlock) Encode(version int, queue chan ValueBlock) (int, error) {
+	var w BitWriter
+	allBits, err := b.EncodeBits(version, &w)
+	if err != nil {
+		return 0, err
+	}
+	w.sendTo(queue)
+
+	return allBits, nil
+}
+
 // GetEncodingMode returns the encoding mode for the given string.
+// The hanzi mode is returned only for the characters, which can't be encoded with the kanji mode.
 func GetEncodingMode(s string) (EncodingMode, error) {
 	if regexpNumeric.MatchString(s) {
 		return EncodingModeNumeric, nil
@@ -296,55 +452,29 @@ func GetEncodingMode(s string) (EncodingMode, error) {
 		return EncodingModeByte, nil
 	}
 	if regexpKanji.MatchString(s) {
+		// the characters out of Shift JIS (e.g. simplified Chinese) can be encoded with the hanzi mode
+		buf := make([]byte, 0, utf8.UTFMax)
+		for _, r := range s {
+			if !isKanjiRune(r, buf) {
+				if (&hanziEncoder{}).CanEncode(s) {
+					return EncodingModeHanzi, nil
+				}
+				break
+			}
+		}
 		return EncodingModeKanji, nil
 	}
 	return 0, ErrCannotDeterminEncodingMode
 }
 
 // GenerateData is a helper function to pack a sequence of ValueBlocks into a byte array.
+//
+// Deprecated: use BitWriter.
 func GenerateData(queue chan ValueBlock, result chan []byte) {
-	var data []byte
-	freeBits := 0
-
+	var w BitWriter
 	for v := range queue {
-		if v.Bits == 0 {
-			continue
-		}
-
-		var b byte
-
-		blockSize := v.Bits
-		value := v.Value
-
-		if freeBits == 0 {
-			data = append(data, 0)
-			freeBits = 8
-		}
-
-		if blockSize > freeBits {
-			b = byte(value >> (blockSize - freeBits))
-		} else {
-			b = byte(value << (freeBits - blockSize) & 0xff)
-		}
-
-		data[len(data)-1] |= b
-
-		if blockSize > freeBits {
-			if blockSize > freeBits+8 {
-				b = byte(value >> ((blockSize - freeBits) - 8))
-			} else {
-				b = byte(value << (8 - (blockSize - freeBits)) & 0xff)
-			}
-
-			data = append(data, b)
-		}
-
-		if blockSize > freeBits+8 {
-			data = append(data, byte(value<<(16-(blockSize-freeBits))&0xff))
-		}
-
-		freeBits = (freeBits + (16 - blockSize)) % 8
+		w.WriteBits(v.Value, v.Bits)
 	}
 
-	result <- data
+	result <- w.Bytes()
 }
@@ -169,6 +169,8 @@ func TestPrefixBytes(t *testing.T) {
 		{EncodingModeKanji, 0, 0, 1, 8, 10, []byte{0b10000000, 0b10100000}},
 		{EncodingModeByte, 0, 0, 1, 8, 23, []byte{0b01000001, 0b01110000}},
 		{EncodingModeECI, EncodingModeByte, 26, 1, 10, 8, []byte{0b01110001, 0b10100100, 0b00000010, 0b00000000}},
+		{EncodingModeECI, EncodingModeByte, 999, 1, 8, 1, []byte{0b01111000, 0b00111110, 0b01110100, 0b00000001}},
+		{EncodingModeECI, EncodingModeByte, 999999, 1, 8, 1, []byte{0b01111100, 0b11110100, 0b00100011, 0b11110100, 0b00000001}},
 
 		// Micro QR
 		{EncodingModeNumeric, 0, 0, -1, 3, 2, []byte{0b01000000}},
@@ -536,3 +538,49 @@ func TestEncode(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"latin1", []byte("hello")},
+		// not valid UTF-8, the byte encoder converts the string by runes
+		{"binary", []byte{0x00, 0xff, 0xfe, 0x80, 0x0a}},
+		// valid UTF-8, the byte encoder would write 2 runes instead of 4 bytes
+		{"utf-8", []byte("ÿé")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := NewBytesBlock(test.data)
+
+			if count := block.GetSymbolsCount(); count != len(test.data) {
+				t.Errorf("Expected %v, got %v", len(test.data), count)
+			}
+
+			bits, err := block.CalculateDataBitsCount()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bits != len(test.data)*8 {
+				t.Errorf("Expected %v, got %v", len(test.data)*8, bits)
+			}
+
+			data, err := EncodeDataWrapper(block)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(data, test.data) {
+				t.Errorf("Expected %v, got %v", test.data, data)
+			}
+		})
+	}
+}
+
+func TestBytesBlockEmpty(t *testing.T) {
+	_, err := NewBytesBlock(nil).CalculateDataBitsCount()
+	if !errors.Is(err, ErrCannotDeterminEncodingMode) {
+		t.Errorf("Expected %v, got %v", ErrCannotDeterminEncodingMode, err)
+	}
+}
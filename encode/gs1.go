@@ -0,0 +1,317 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GS is the ASCII group separator, which ends the variable length elements of the GS1 element string
+// (FNC1 as the data separator).
+const GS = "\x1d"
+
+var (
+	ErrInvalidApplicationIndicator  = errors.New("invalid application indicator")
+	ErrUnknownApplicationIdentifier = errors.New("unknown application identifier")
+	ErrInvalidGS1Element            = errors.New("invalid GS1 element")
+	ErrInvalidGS1Character          = errors.New("character is not in GS1 character set")
+	ErrInvalidGS1CheckDigit         = errors.New("invalid check digit")
+	ErrInvalidGS1Date               = errors.New("invalid date")
+	ErrEmptyGS1ElementString        = errors.New("empty GS1 element string")
+)
+
+// gs1Chars is the GS1 AI encodable character set 82.
+const gs1Chars = "!\"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+
+// gs1Format is the format of the data field of the application identifier.
+type gs1Format struct {
+	// numeric is true for the digits only
+	numeric bool
+	// min and max length of the data field, they are equal for the fixed length fields
+	min, max int
+	// checkDigit is true if the last digit is the GS1 check digit
+	checkDigit bool
+	// date is true for the dates in the YYMMDD format
+	date bool
+}
+
+// gs1ApplicationIdentifiers is the list of the supported application identifiers.
+// The AIs with the decimal point indicator (e.g. 310n) are stored with the first three digits.
+var gs1ApplicationIdentifiers = map[string]gs1Format{
+	"00":   {numeric: true, min: 18, max: 18, checkDigit: true}, // SSCC
+	"01":   {numeric: true, min: 14, max: 14, checkDigit: true}, // GTIN
+	"02":   {numeric: true, min: 14, max: 14, checkDigit: true}, // GTIN of contained trade items
+	"10":   {min: 1, max: 20},                                   // batch or lot number
+	"11":   {numeric: true, min: 6, max: 6, date: true},         // production date
+	"12":   {numeric: true, min: 6, max: 6, date: true},         // due date
+	"13":   {numeric: true, min: 6, max: 6, date: true},         // packaging date
+	"15":   {numeric: true, min: 6, max: 6, date: true},         // best before date
+	"16":   {numeric: true, min: 6, max: 6, date: true},         // sell by date
+	"17":   {numeric: true, min: 6, max: 6, date: true},         // expiration date
+	"20":   {numeric: true, min: 2, max: 2},                     // internal product variant
+	"21":   {min: 1, max: 20},                                   // serial number
+	"22":   {min: 1, max: 20},                                   // consumer product variant
+	"30":   {numeric: true, min: 1, max: 8},                     // variable count of items
+	"37":   {numeric: true, min: 1, max: 8},                     // count of trade items
+	"400":  {min: 1, max: 30},                                   // customer's purchase order number
+	"401":  {min: 1, max: 30},                                   // global identification number for consignment
+	"402":  {numeric: true, min: 17, max: 17, checkDigit: true}, // global shipment identification number
+	"403":  {min: 1, max: 30},                                   // routing code
+	"410":  {numeric: true, min: 13, max: 13, checkDigit: true}, // ship to location
+	"411":  {numeric: true, min: 13, max: 13, checkDigit: true}, // bill to location
+	"412":  {numeric: true, min: 13, max: 13, checkDigit: true}, // purchased from location
+	"413":  {numeric: true, min: 13, max: 13, checkDigit: true}, // ship for location
+	"414":  {numeric: true, min: 13, max: 13, checkDigit: true}, // physical location
+	"415":  {numeric: true, min: 13, max: 13, checkDigit: true}, // invoicing party
+	"420":  {min: 1, max: 20},                                   // ship to postal code
+	"422":  {numeric: true, min: 3, max: 3},                     // country of origin
+	"8004": {min: 1, max: 30},                                   // global individual asset identifier
+	"8020": {min: 1, max: 25},                                   // payment slip reference number
+	"90":   {min: 1, max: 30},                                   // information agreed between partners
+	"91":   {min: 1, max: 90},                                   // company internal information
+	"92":   {min: 1, max: 90},
+	"93":   {min: 1, max: 90},
+	"94":   {min: 1, max: 90},
+	"95":   {min: 1, max: 90},
+	"96":   {min: 1, max: 90},
+	"97":   {min: 1, max: 90},
+	"98":   {min: 1, max: 90},
+	"99":   {min: 1, max: 90},
+
+	// trade measures with the decimal point position as the fourth digit
+	"310": {numeric: true, min: 6, max: 6},  // net weight, kg
+	"311": {numeric: true, min: 6, max: 6},  // length, m
+	"312": {numeric: true, min: 6, max: 6},  // width, m
+	"313": {numeric: true, min: 6, max: 6},  // depth, m
+	"314": {numeric: true, min: 6, max: 6},  // area, m2
+	"315": {numeric: true, min: 6, max: 6},  // net volume, l
+	"316": {numeric: true, min: 6, max: 6},  // net volume, m3
+	"330": {numeric: true, min: 6, max: 6},  // logistic weight, kg
+	"392": {numeric: true, min: 1, max: 15}, // amount payable, single monetary area
+}
+
+// gs1DecimalAIs are the first three digits of the application identifiers with the decimal point indicator.
+var gs1DecimalAIs = map[string]bool{
+	"310": true, "311": true, "312": true, "313": true, "314": true, "315": true, "316": true,
+	"330": true, "392": true,
+}
+
+// gs1PredefinedLengths are the first two digits of the application identifiers with the predefined length,
+// these elements don't need the separator even if they aren't the last ones.
+var gs1PredefinedLengths = map[string]bool{
+	"00": true, "01": true, "02": true, "03": true, "04": true,
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true, "20": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "36": true, "41": true,
+}
+
+// GS1Element is an element of the GS1 element string: the application identifier and its data field.
+type GS1Element struct {
+	// AI is the application identifier without parentheses, e.g. "01".
+	AI string
+
+	// Value is the data field.
+	Value string
+}
+
+// lookupApplicationIdentifier returns the format of the application identifier.
+func lookupApplicationIdentifier(ai string) (gs1Format, bool) {
+	if len(ai) == 4 && gs1DecimalAIs[ai[:3]] && isDigits(ai[3:]) {
+		return gs1ApplicationIdentifiers[ai[:3]], true
+	}
+	if gs1DecimalAIs[ai] {
+		return gs1Format{}, false
+	}
+
+	format, ok := gs1ApplicationIdentifiers[ai]
+	return format, ok
+}
+
+// isDigits returns true if the string contains only digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GS1CheckDigit returns the GS1 check digit (modulo 10) for the digits without the check digit.
+func GS1CheckDigit(digits string) byte {
+	sum := 0
+	for idx := 0; idx < len(digits); idx++ {
+		digit := int(digits[len(digits)-1-idx] - '0')
+		// the weights are 3 and 1 from the right
+		if idx%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// isValidGS1Date checks the date in the YYMMDD format. The day can be 00 (the last day of the month).
+func isValidGS1Date(value string) bool {
+	month := int(value[2]-'0')*10 + int(value[3]-'0')
+	day := int(value[4]-'0')*10 + int(value[5]-'0')
+	if month < 1 || month > 12 {
+		return false
+	}
+
+	// the century is ignored: a year divisible by 4 (including 00) is a leap year in 2000-2099
+	year := 2000 + int(value[0]-'0')*10 + int(value[1]-'0')
+	days := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return day <= days
+}
+
+// Validate checks the application identifier and the data field: the length, the character set,
+// the check digit and the date.
+func (e GS1Element) Validate() error {
+	format, ok := lookupApplicationIdentifier(e.AI)
+	if !ok {
+		return fmt.Errorf("%w: (%s)", ErrUnknownApplicationIdentifier, e.AI)
+	}
+
+	if len(e.Value) < format.min || len(e.Value) > format.max {
+		if format.min == format.max {
+			return fmt.Errorf("%w: (%s) must have %d characters, got %d", ErrInvalidGS1Element, e.AI, format.min, len(e.Value))
+		}
+		return fmt.Errorf("%w: (%s) must have %d-%d characters, got %d", ErrInvalidGS1Element, e.AI, format.min, format.max, len(e.Value))
+	}
+
+	if format.numeric && !isDigits(e.Value) {
+		return fmt.Errorf("%w: (%s) must have only digits", ErrInvalidGS1Element, e.AI)
+	}
+	for _, r := range e.Value {
+		if !strings.ContainsRune(gs1Chars, r) {
+			return fmt.Errorf("%w: (%s) %q", ErrInvalidGS1Character, e.AI, r)
+		}
+	}
+
+	if format.checkDigit {
+		last := len(e.Value) - 1
+		if expected := GS1CheckDigit(e.Value[:last]); e.Value[last] != expected {
+			return fmt.Errorf("%w: (%s) expected %c, got %c", ErrInvalidGS1CheckDigit, e.AI, expected, e.Value[last])
+		}
+	}
+
+	if format.date && !isValidGS1Date(e.Value) {
+		return fmt.Errorf("%w: (%s) %s", ErrInvalidGS1Date, e.AI, e.Value)
+	}
+
+	return nil
+}
+
+// GS1ElementString validates the elements and concatenates them into the GS1 element string.
+// The elements with a variable length are followed by GS, unless they are the last ones.
+func GS1ElementString(elements []GS1Element) (string, error) {
+	if len(elements) == 0 {
+		return "", ErrEmptyGS1ElementString
+	}
+
+	var sb strings.Builder
+	for idx, element := range elements {
+		if err := element.Validate(); err != nil {
+			return "", err
+		}
+
+		sb.WriteString(element.AI)
+		sb.WriteString(element.Value)
+		if idx < len(elements)-1 && !gs1PredefinedLengths[element.AI[:2]] {
+			sb.WriteString(GS)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// FNC1AlphaNumeric converts the data of the alphanumeric block in the FNC1 mode:
+// GS (the FNC1 separator) is encoded as "%" and "%" is encoded as "%%".
+// The decoders read "%%" first, so GS followed by "%" can't be restored, but in the GS1 element string
+// GS is always followed by the next application identifier.
+func FNC1AlphaNumeric(data string) string {
+	return strings.NewReplacer("%", "%%", GS, "%").Replace(data)
+}
+
+// fnc1AlphaNumericDecode is the reverse operation for FNC1AlphaNumeric.
+func fnc1AlphaNumericDecode(data string) string {
+	return strings.NewReplacer("%%", "%", "%", GS).Replace(data)
+}
+
+// NewFNC1SecondBlock creates the header block of the FNC1 second position mode with the AIM application indicator:
+// a single letter (encoded as its ASCII value + 100) or two digits.
+func NewFNC1SecondBlock(indicator string) (*EncodeBlock, error) {
+	var value int
+	switch {
+	case len(indicator) == 1 && (indicator[0] >= 'a' && indicator[0] <= 'z' || indicator[0] >= 'A' && indicator[0] <= 'Z'):
+		value = int(indicator[0]) + 100
+	case len(indicator) == 2 && isDigits(indicator):
+		value = int(indicator[0]-'0')*10 + int(indicator[1]-'0')
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidApplicationIndicator, indicator)
+	}
+
+	return &EncodeBlock{
+		Mode:                 EncodingModeFNC1Second,
+		ApplicationIndicator: byte(value),
+	}, nil
+}
+
+// NewGS1Blocks validates the elements and returns the blocks of the GS1 QR Code for the version:
+// the FNC1 first position header and the segmented element string.
+// GS separators are translated to "%" (and "%" to "%%") before the segmentation, so the element string
+// is segmented as it's written in the alphanumeric blocks. The byte blocks get GS as the byte 0x1D and "%" back.
+func NewGS1Blocks(elements []GS1Element, version int) ([]*EncodeBlock, error) {
+	elementString, err := GS1ElementString(elements)
+	if err != nil {
+		return nil, err
+	}
+
+	translated := FNC1AlphaNumeric(elementString)
+	segments, err := Segment(translated, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to segment element string: %w", err)
+	}
+	segments = joinPercentPairs(translated, segments)
+
+	blocks := []*EncodeBlock{{Mode: EncodingModeFNC1First}}
+	for _, block := range segments {
+		if block.Mode == EncodingModeByte {
+			block.Data = fnc1AlphaNumericDecode(block.Data)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// joinPercentPairs moves the second "%" of the "%%" pair split between the blocks of the translated string
+// to the end of the first block, so the pair is decoded as "%" by one block.
+// The blocks hold the consecutive parts of the translated string.
+func joinPercentPairs(translated string, blocks []*EncodeBlock) []*EncodeBlock {
+	// pairSecond marks the second characters of the "%%" pairs, the decoders read the pairs from the left
+	pairSecond := make([]bool, len(translated))
+	for i := 0; i < len(translated); i++ {
+		if translated[i] == '%' && i+1 < len(translated) && translated[i+1] == '%' {
+			pairSecond[i+1] = true
+			i++
+		}
+	}
+
+	joined := make([]*EncodeBlock, 0, len(blocks))
+	offset := 0
+	for _, block := range blocks {
+		if len(joined) > 0 && offset < len(translated) && pairSecond[offset] {
+			joined[len(joined)-1].Data += "%"
+			block.Data = block.Data[1:]
+			offset++
+		}
+		offset += len(block.Data)
+		if block.Data != "" {
+			joined = append(joined, block)
+		}
+	}
+
+	return joined
+}
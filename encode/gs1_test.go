@@ -0,0 +1,298 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGS1CheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		check  byte
+	}{
+		{"0950600013435", '2'},
+		{"0400638133393", '1'},
+		{"00000000000000000", '0'},
+		{"1234567", '0'},
+	}
+
+	for _, test := range tests {
+		t.Run(test.digits, func(t *testing.T) {
+			if check := GS1CheckDigit(test.digits); check != test.check {
+				t.Errorf("Expected %c, got %c", test.check, check)
+			}
+		})
+	}
+}
+
+func TestGS1ElementValidate(t *testing.T) {
+	tests := []struct {
+		element GS1Element
+		err     error
+	}{
+		{GS1Element{"01", "09506000134352"}, nil},
+		{GS1Element{"01", "09506000134353"}, ErrInvalidGS1CheckDigit},
+		{GS1Element{"01", "0950600013435"}, ErrInvalidGS1Element},
+		{GS1Element{"01", "0950600013435A"}, ErrInvalidGS1Element},
+		{GS1Element{"00", "106141411234567897"}, nil},
+		{GS1Element{"17", "251231"}, nil},
+		{GS1Element{"17", "250200"}, nil},
+		{GS1Element{"17", "240229"}, nil},
+		{GS1Element{"17", "250229"}, ErrInvalidGS1Date},
+		{GS1Element{"17", "251301"}, ErrInvalidGS1Date},
+		{GS1Element{"11", "250431"}, ErrInvalidGS1Date},
+		{GS1Element{"10", "ABC-123/x"}, nil},
+		{GS1Element{"10", "ABC 123"}, ErrInvalidGS1Character},
+		{GS1Element{"10", strings.Repeat("A", 21)}, ErrInvalidGS1Element},
+		{GS1Element{"3103", "000750"}, nil},
+		{GS1Element{"310", "000750"}, ErrUnknownApplicationIdentifier},
+		{GS1Element{"3925", "1999"}, nil},
+		{GS1Element{"05", "123"}, ErrUnknownApplicationIdentifier},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("(%v)%v", test.element.AI, test.element.Value), func(t *testing.T) {
+			if err := test.element.Validate(); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestGS1ElementString(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements []GS1Element
+		expected string
+		err      error
+	}{
+		{"single", []GS1Element{{"01", "09506000134352"}}, "0109506000134352", nil},
+		{
+			"predefined length without separators",
+			[]GS1Element{{"01", "09506000134352"}, {"17", "251231"}, {"10", "ABC123"}},
+			"010950600013435217251231" + "10ABC123",
+			nil,
+		},
+		{
+			"variable length with separators",
+			[]GS1Element{{"10", "ABC123"}, {"21", "12%"}, {"17", "251231"}},
+			"10ABC123" + GS + "2112%" + GS + "17251231",
+			nil,
+		},
+		{"empty", nil, "", ErrEmptyGS1ElementString},
+		{"invalid element", []GS1Element{{"01", "09506000134352"}, {"17", "251232"}}, "", ErrInvalidGS1Date},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := GS1ElementString(test.elements)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestFNC1AlphaNumeric(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"ABC", "ABC"},
+		{"AB" + GS + "C", "AB%C"},
+		{"50%", "50%%"},
+		{"5%" + GS + "9", "5%%%9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			encoded := FNC1AlphaNumeric(test.data)
+			if encoded != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, encoded)
+			}
+			if decoded := fnc1AlphaNumericDecode(encoded); decoded != test.data {
+				t.Errorf("Expected %q, got %q", test.data, decoded)
+			}
+		})
+	}
+}
+
+func TestNewFNC1SecondBlock(t *testing.T) {
+	tests := []struct {
+		indicator string
+		value     byte
+		err       error
+	}{
+		{"37", 37, nil},
+		{"00", 0, nil},
+		{"a", 197, nil},
+		{"Z", 190, nil},
+		{"", 0, ErrInvalidApplicationIndicator},
+		{"123", 0, ErrInvalidApplicationIndicator},
+		{"%", 0, ErrInvalidApplicationIndicator},
+	}
+
+	for _, test := range tests {
+		t.Run(test.indicator, func(t *testing.T) {
+			block, err := NewFNC1SecondBlock(test.indicator)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if err == nil && block.ApplicationIndicator != test.value {
+				t.Errorf("Expected %v, got %v", test.value, block.ApplicationIndicator)
+			}
+		})
+	}
+
+	block, _ := NewFNC1SecondBlock("37")
+	data, err := EncodeWrapper(block, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// mode 1001, application indicator 00100101
+	if expected := []byte{0b10010010, 0b01010000}; string(data) != string(expected) {
+		t.Errorf("Expected %08b, got %08b", expected, data)
+	}
+}
+
+func TestNewGS1Blocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements []GS1Element
+		expected string
+		modes    []EncodingMode
+	}{
+		{
+			"percent",
+			[]GS1Element{{"01", "09506000134352"}, {"10", "AB-12%"}, {"21", "12345678"}},
+			"0109506000134352" + "10AB-12%" + GS + "2112345678",
+			nil,
+		},
+		{
+			"separator in alphanumeric",
+			[]GS1Element{{"10", "ABC-123"}, {"21", "XYZ"}},
+			"10ABC-123" + GS + "21XYZ",
+			[]EncodingMode{EncodingModeFNC1First, EncodingModeAlphaNumeric},
+		},
+		{
+			"separator in byte",
+			[]GS1Element{{"10", "abc%"}, {"21", "xyz"}},
+			"10abc%" + GS + "21xyz",
+			[]EncodingMode{EncodingModeFNC1First, EncodingModeByte},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blocks, err := NewGS1Blocks(test.elements, 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if blocks[0].Mode != EncodingModeFNC1First {
+				t.Fatalf("Expected FNC1 first position header, got %v", blocks[0].Mode)
+			}
+			if test.modes != nil {
+				if len(blocks) != len(test.modes) {
+					t.Fatalf("Expected %v blocks, got %+v", len(test.modes), blocks)
+				}
+				for idx, block := range blocks {
+					if block.Mode != test.modes[idx] {
+						t.Errorf("Expected mode %v of block %v, got %v", test.modes[idx], idx, block.Mode)
+					}
+				}
+			}
+
+			queue := make(chan ValueBlock, 100)
+			result := make(chan []byte)
+			go GenerateData(queue, result)
+			for _, block := range blocks {
+				if _, err := block.Encode(2, queue); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			close(queue)
+
+			decoded, err := DecodeData(<-result, 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var content strings.Builder
+			for _, block := range decoded {
+				content.WriteString(block.Data)
+			}
+			if content.String() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, content.String())
+			}
+		})
+	}
+
+	if _, err := NewGS1Blocks([]GS1Element{{"01", "09506000134353"}}, 2); !errors.Is(err, ErrInvalidGS1CheckDigit) {
+		t.Errorf("Expected %v, got %v", ErrInvalidGS1CheckDigit, err)
+	}
+}
+
+func TestJoinPercentPairs(t *testing.T) {
+	tests := []struct {
+		name       string
+		translated string
+		blocks     []EncodeBlock
+		expected   []EncodeBlock
+	}{
+		{
+			"not split",
+			"A%%b",
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%%"}, {Mode: EncodingModeByte, Data: "b"}},
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%%"}, {Mode: EncodingModeByte, Data: "b"}},
+		},
+		{
+			"split before byte",
+			"A%%b",
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%"}, {Mode: EncodingModeByte, Data: "%b"}},
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%%"}, {Mode: EncodingModeByte, Data: "b"}},
+		},
+		{
+			"split before alphanumeric",
+			"a%%B",
+			[]EncodeBlock{{Mode: EncodingModeByte, Data: "a%"}, {Mode: EncodingModeAlphaNumeric, Data: "%B"}},
+			[]EncodeBlock{{Mode: EncodingModeByte, Data: "a%%"}, {Mode: EncodingModeAlphaNumeric, Data: "B"}},
+		},
+		{
+			"separator after pair",
+			"a%%%B",
+			[]EncodeBlock{{Mode: EncodingModeByte, Data: "a%%"}, {Mode: EncodingModeAlphaNumeric, Data: "%B"}},
+			[]EncodeBlock{{Mode: EncodingModeByte, Data: "a%%"}, {Mode: EncodingModeAlphaNumeric, Data: "%B"}},
+		},
+		{
+			"empty block",
+			"A%%",
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%"}, {Mode: EncodingModeByte, Data: "%"}},
+			[]EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A%%"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blocks := make([]*EncodeBlock, len(test.blocks))
+			for idx := range test.blocks {
+				blocks[idx] = &test.blocks[idx]
+			}
+
+			joined := joinPercentPairs(test.translated, blocks)
+			if len(joined) != len(test.expected) {
+				t.Fatalf("Expected %v blocks, got %v", len(test.expected), len(joined))
+			}
+			for idx, block := range joined {
+				if *block != test.expected[idx] {
+					t.Errorf("Expected %v, got %v", test.expected[idx], *block)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package encode
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// HanziSubsetGB2312 is the subset indicator of the Hanzi mode for the GB 2312 double-byte characters,
+// it's written after the mode indicator.
+const HanziSubsetGB2312 = 1
+
+// hanziSubsetBits is the number of bits of the Hanzi subset indicator.
+const hanziSubsetBits = 4
+
+// hanziEncoder is a struct that uses for converting string to hanzi data (GB/T 18284).
+type hanziEncoder struct{}
+
+func (e *hanziEncoder) Encode(content string, queue chan ValueBlock) error {
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e *hanziEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode converts the content to GB 2312 and writes each character with 13 bits.
+func (*hanziEncoder) encode(content string, write func(value, bits int)) error {
+	buf, err := gb2312Bytes(content)
+	if err != nil {
+		return fmt.Errorf("failed to encode string to hanzi: %w", err)
+	}
+
+	for i := 0; i < len(buf); i += 2 {
+		high, low := buf[i], buf[i+1]
+
+		// subtract 0xA1A1 from each character between 0xA1A1 and 0xAAFE
+		// subtract 0xA6A1 from each character between 0xB0A1 and 0xFAFE
+		if high >= 0xA1 && high <= 0xAA {
+			high -= 0xA1
+		} else if high >= 0xB0 && high <= 0xFA {
+			high -= 0xA6
+		} else {
+			return fmt.Errorf("invalid byte: %v", high)
+		}
+		if low < 0xA1 || low > 0xFE {
+			return fmt.Errorf("invalid byte: %v", low)
+		}
+		low -= 0xA1
+
+		write(int(high)*0x60+int(low), 13)
+	}
+
+	return nil
+}
+
+func (e *hanziEncoder) CanEncode(content string) bool {
+	return content != "" && e.encode(content, func(int, int) {}) == nil
+}
+
+func (*hanziEncoder) Size(content string) int {
+	return utf8.RuneCountInString(content) * 13
+}
+
+func (*hanziEncoder) Mode() EncodingMode {
+	return EncodingModeHanzi
+}
+
+// gb2312Bytes converts the content to the double-byte characters of GB 2312.
+// GBK is a superset of GB 2312 with the same codes, the characters out of GB 2312 are rejected by the ranges.
+func gb2312Bytes(content string) ([]byte, error) {
+	buf, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != utf8.RuneCountInString(content)*2 {
+		return nil, fmt.Errorf("content has single-byte characters: %w", ErrCannotDeterminEncodingMode)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,171 @@
+package encode
+
+import "testing"
+
+func TestHanziEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []ValueBlock
+	}{
+		{
+			// 0xB0A1 - 0xA6A1 = 0x0A00, 0x0A * 0x60 + 0x00
+			content: "啊",
+			expected: []ValueBlock{
+				{Bits: 13, Value: 960},
+			},
+		},
+		{
+			// 0xA1A3 - 0xA1A1 = 0x0002 and 0xB5E3 - 0xA6A1 = 0x0F42
+			content: "。点",
+			expected: []ValueBlock{
+				{Bits: 13, Value: 2},
+				{Bits: 13, Value: 1506},
+			},
+		},
+	}
+
+	enc := &hanziEncoder{}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			queue := make(chan ValueBlock, 100)
+			err := enc.Encode(test.content, queue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			close(queue)
+
+			i := 0
+			for block := range queue {
+				if i >= len(test.expected) {
+					t.Fatalf("unexpected block: %v", block)
+				}
+
+				if block != test.expected[i] {
+					t.Errorf("expected %v, got %v", test.expected[i], block)
+				}
+				i++
+			}
+		})
+	}
+
+	// Cannot encode to GB 2312
+	t.Run("cannot encode", func(t *testing.T) {
+		queue := make(chan ValueBlock, 100)
+		err := enc.Encode("Ä", queue)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	// GBK character out of GB 2312
+	t.Run("not GB 2312", func(t *testing.T) {
+		queue := make(chan ValueBlock, 100)
+		err := enc.Encode("丂", queue)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestHanziEncoder_CanEncode(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{
+			content:  "简体中文",
+			expected: true,
+		},
+		{
+			content:  "あア",
+			expected: true,
+		},
+		{
+			content:  "abc",
+			expected: false,
+		},
+		{
+			content:  "",
+			expected: false,
+		},
+	}
+
+	enc := &hanziEncoder{}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			canEncode := enc.CanEncode(test.content)
+			if canEncode != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, canEncode)
+			}
+		})
+	}
+}
+
+func TestHanziEncoder_Size(t *testing.T) {
+	enc := &hanziEncoder{}
+	if size := enc.Size("简体中文"); size != 52 {
+		t.Errorf("expected %v, got %v", 52, size)
+	}
+}
+
+func TestHanziEncoder_Mode(t *testing.T) {
+	enc := &hanziEncoder{}
+	if enc.Mode() != EncodingModeHanzi {
+		t.Errorf("expected %v, got %v", EncodingModeHanzi, enc.Mode())
+	}
+}
+
+func TestHanziBlock(t *testing.T) {
+	block := &EncodeBlock{Mode: EncodingModeHanzi, Data: "简体中文"}
+
+	tests := []struct {
+		version    int
+		lengthBits int
+		err        error
+	}{
+		{1, 8, nil},
+		{10, 10, nil},
+		{27, 12, nil},
+		{-4, 0, ErrVersionDoesNotSupportEncodingMode},
+		{RMQRVersionMax, 0, ErrVersionDoesNotSupportEncodingMode},
+	}
+
+	for _, test := range tests {
+		lengthBits, err := block.GetLengthBits(test.version)
+		if err != test.err {
+			t.Errorf("Expected %v, got %v", test.err, err)
+		}
+		if lengthBits != test.lengthBits {
+			t.Errorf("Expected %v, got %v", test.lengthBits, lengthBits)
+		}
+	}
+
+	if modeBits := block.GetModeBits(1); modeBits != 8 {
+		t.Errorf("Expected %v, got %v", 8, modeBits)
+	}
+
+	w := NewBitWriter(16)
+	bits, err := block.EncodeBits(1, w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// mode 1101, subset 0001, length 8 bits and 4 characters of 13 bits
+	if bits != 4+4+8+4*13 || w.Len() != bits {
+		t.Errorf("Expected %v, got %v (%v written)", 4+4+8+4*13, bits, w.Len())
+	}
+	if prefix := w.Bytes()[:2]; prefix[0] != 0b11010001 || prefix[1] != 4 {
+		t.Errorf("Expected %08b, got %08b", []byte{0b11010001, 4}, prefix)
+	}
+
+	w.Pad()
+	blocks, err := DecodeData(w.Bytes(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(blocks) != 1 || *blocks[0] != *block {
+		t.Errorf("Expected %v, got %v", block, blocks)
+	}
+}
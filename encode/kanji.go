@@ -10,7 +10,16 @@ import (
 // kanjiEncoder is a struct that uses for converting string to kanji data.
 type kanjiEncoder struct{}
 
-func (*kanjiEncoder) Encode(content string, queue chan ValueBlock) error {
+func (e *kanjiEncoder) Encode(content string, queue chan ValueBlock) error {
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e *kanjiEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode converts the content to Shift JIS and writes each character with 13 bits.
+func (*kanjiEncoder) encode(content string, write func(value, bits int)) error {
 	enc := japanese.ShiftJIS.NewEncoder()
 	buf, err := enc.Bytes([]byte(content))
 
@@ -34,10 +43,7 @@ func (*kanjiEncoder) Encode(content string, queue chan ValueBlock) error {
 		}
 
 		value := uint(high)*0xC0 + uint(low)
-		queue <- ValueBlock{
-			Bits:  13,
-			Value: int(value),
-		}
+		write(int(value), 13)
 	}
 
 	return nil
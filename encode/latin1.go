@@ -10,7 +10,16 @@ import (
 // byteEncoder is a struct that uses for converting string to byte data.
 type byteEncoder struct{}
 
-func (byteEncoder) Encode(content string, queue chan ValueBlock) error {
+func (e *byteEncoder) Encode(content string, queue chan ValueBlock) error {
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e *byteEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode converts the content to ISO 8859-1 and writes each byte with 8 bits.
+func (*byteEncoder) encode(content string, write func(value, bits int)) error {
 	enc := charmap.ISO8859_1.NewEncoder()
 	buf, err := enc.Bytes([]byte(content))
 	if err != nil {
@@ -18,10 +27,7 @@ func (byteEncoder) Encode(content string, queue chan ValueBlock) error {
 	}
 
 	for _, b := range buf {
-		queue <- ValueBlock{
-			Bits:  8,
-			Value: int(b),
-		}
+		write(int(b), 8)
 	}
 
 	return nil
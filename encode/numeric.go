@@ -9,7 +9,17 @@ import (
 // numericEncoder is a struct that uses for converting string to numeric data.
 type numericEncoder struct{}
 
-func (*numericEncoder) Encode(content string, queue chan ValueBlock) error {
+func (e *numericEncoder) Encode(content string, queue chan ValueBlock) error {
+	return e.encode(content, queueWriter(queue))
+}
+
+func (e *numericEncoder) EncodeBits(content string, w *BitWriter) error {
+	return e.encode(content, w.WriteBits)
+}
+
+// encode splits the content into triplets of digits and writes them with 10 bits
+// (the last one or two digits take 4 or 7 bits).
+func (*numericEncoder) encode(content string, write func(value, bits int)) error {
 	triplets := len(content) / 3
 	if len(content)%3 != 0 {
 		triplets++
@@ -28,10 +38,7 @@ func (*numericEncoder) Encode(content string, queue chan ValueBlock) error {
 			return fmt.Errorf("failed to convert string to int: %w", err)
 		}
 
-		queue <- ValueBlock{
-			Bits:  tripletBytesSize,
-			Value: number,
-		}
+		write(number, tripletBytesSize)
 	}
 
 	return nil
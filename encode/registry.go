@@ -0,0 +1,153 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/text/encoding"
+)
+
+var ErrInvalidEncoder = errors.New("invalid encoder")
+var ErrEncoderSizeMismatch = errors.New("encoder size doesn't match the encoded data")
+
+// registryMutex guards encodingModeEncoderMap and assigmentNumbersEncodings.
+var registryMutex sync.RWMutex
+
+// registrationSamples are the contents of each mode, which the encoder is validated with at the registration.
+// The samples cover the remainders of the numeric triplets and the alphanumeric pairs.
+var registrationSamples = map[EncodingMode][]string{
+	EncodingModeNumeric:      {"1", "12", "123", "1234"},
+	EncodingModeAlphaNumeric: {"A", "AB", "HELLO WORLD $%*+-./:"},
+	EncodingModeByte:         {"a", "qrcode", "Ï"},
+	EncodingModeKanji:        {"点", "漢字"},
+	EncodingModeHanzi:        {"条", "条形码"},
+}
+
+// RegisterEncoder sets the encoder for the data of its mode (numeric, alphanumeric, byte or kanji),
+// replacing the built-in one. The mode indicator and the length of the block are written as usual,
+// the encoder only produces the data bits.
+// For the byte mode the count of symbols is Size/8, so the encoder can convert the content to any charset.
+// The encoder is validated with the sample contents of the mode, which it can encode (see ValidateEncoder),
+// the encoder with the wrong Size isn't registered.
+// The encoder is used by all QR codes created after the registration.
+func RegisterEncoder(enc QREncoder) error {
+	if enc == nil {
+		return fmt.Errorf("%w: nil", ErrInvalidEncoder)
+	}
+
+	mode := enc.Mode()
+	if _, ok := encodingModeLengthMap[mode]; !ok {
+		return fmt.Errorf("%w: mode %d", ErrUnknownEncodingMode, mode)
+	}
+
+	for _, content := range registrationSamples[mode] {
+		if !enc.CanEncode(content) {
+			continue
+		}
+		if err := ValidateEncoder(enc, content); err != nil {
+			return fmt.Errorf("failed to validate encoder with %q: %w", content, err)
+		}
+		if size := enc.Size(content); mode == EncodingModeByte && size%8 != 0 {
+			return fmt.Errorf("%w: %T reported %d bits, not whole bytes", ErrEncoderSizeMismatch, enc, size)
+		}
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	encodingModeEncoderMap[mode] = enc
+
+	return nil
+}
+
+// RegisterECIEncoding sets the charset of the ECI assignment number, replacing the built-in one.
+// It allows to use the assignment numbers unknown to the package (e.g. a vendor code page),
+// both for encoding and decoding.
+func RegisterECIEncoding(assignmentNumber uint, enc encoding.Encoding) error {
+	if assignmentNumber > MaxAssignmentNumber {
+		return fmt.Errorf("%w: %d", ErrInvalidAssignmentNumber, assignmentNumber)
+	}
+	if enc == nil {
+		return fmt.Errorf("%w: nil encoding for assignment number %d", ErrInvalidEncoder, assignmentNumber)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	assigmentNumbersEncodings[assignmentNumber] = enc
+
+	return nil
+}
+
+// ValidateEncoder encodes the content and checks that the encoder emits exactly Size bits.
+func ValidateEncoder(enc QREncoder, content string) error {
+	var w BitWriter
+	return encodeTo(enc, content, &w)
+}
+
+// lookupEncoder returns the encoder for the mode.
+func lookupEncoder(mode EncodingMode) (QREncoder, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	enc, ok := encodingModeEncoderMap[mode]
+	return enc, ok
+}
+
+// lookupECIEncoding returns the charset of the assignment number.
+// The charset is nil if the assignment number is known, but not supported.
+func lookupECIEncoding(assignmentNumber uint) (encoding.Encoding, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	enc, ok := assigmentNumbersEncodings[assignmentNumber]
+	return enc, ok
+}
+
+// encodeTo encodes the content to the writer and checks that the size of the data matches
+// the size reported by the encoder. The encoders without EncodeBits are run through the channel.
+func encodeTo(enc QREncoder, content string, w *BitWriter) error {
+	start := w.Len()
+
+	if bitEnc, ok := enc.(BitEncoder); ok {
+		if err := bitEnc.EncodeBits(content, w); err != nil {
+			return err
+		}
+	} else {
+		blocks, err := encodeQueued(enc, content)
+		if err != nil {
+			return err
+		}
+		for _, v := range blocks {
+			w.WriteBits(v.Value, v.Bits)
+		}
+	}
+
+	if bits, size := w.Len()-start, enc.Size(content); bits != size {
+		return fmt.Errorf("%w: %T emitted %d bits, but reported %d", ErrEncoderSizeMismatch, enc, bits, size)
+	}
+
+	return nil
+}
+
+// encodeQueued runs the channel-based Encode and returns the emitted blocks.
+func encodeQueued(enc QREncoder, content string) ([]ValueBlock, error) {
+	queue := make(chan ValueBlock)
+	done := make(chan struct{})
+
+	var blocks []ValueBlock
+	go func() {
+		for v := range queue {
+			blocks = append(blocks, v)
+		}
+		close(done)
+	}()
+
+	err := enc.Encode(content, queue)
+	close(queue)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,150 @@
+package encode
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// utf8ByteEncoder writes the content as UTF-8 bytes in the byte mode.
+type utf8ByteEncoder struct{}
+
+func (utf8ByteEncoder) Encode(content string, queue chan ValueBlock) error {
+	for _, b := range []byte(content) {
+		queue <- ValueBlock{Bits: 8, Value: int(b)}
+	}
+	return nil
+}
+
+func (utf8ByteEncoder) CanEncode(content string) bool { return true }
+func (utf8ByteEncoder) Size(content string) int       { return len(content) * 8 }
+func (utf8ByteEncoder) Mode() EncodingMode            { return EncodingModeByte }
+
+// brokenEncoder reports one bit less than it writes.
+type brokenEncoder struct{ utf8ByteEncoder }
+
+func (brokenEncoder) Size(content string) int { return len(content)*8 - 1 }
+
+// bitsEncoder writes 7 bits for each byte of the content.
+type bitsEncoder struct{ utf8ByteEncoder }
+
+func (bitsEncoder) Encode(content string, queue chan ValueBlock) error {
+	for _, b := range []byte(content) {
+		queue <- ValueBlock{Bits: 7, Value: int(b) & 0x7f}
+	}
+	return nil
+}
+
+func (bitsEncoder) Size(content string) int { return len(content) * 7 }
+
+// restoreEncoder brings back the built-in encoder of the mode after the test.
+func restoreEncoder(t *testing.T, mode EncodingMode) {
+	original := encodingModeEncoderMap[mode]
+	t.Cleanup(func() {
+		encodingModeEncoderMap[mode] = original
+	})
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  QREncoder
+		err  error
+	}{
+		{"nil", nil, ErrInvalidEncoder},
+		{"eci", eciEncoder{}, ErrUnknownEncodingMode},
+		{"byte", utf8ByteEncoder{}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			restoreEncoder(t, EncodingModeByte)
+
+			if err := RegisterEncoder(test.enc); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestCustomEncoder(t *testing.T) {
+	restoreEncoder(t, EncodingModeByte)
+	if err := RegisterEncoder(utf8ByteEncoder{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	block := &EncodeBlock{Mode: EncodingModeByte, Data: "Ï"}
+	if count := block.GetSymbolsCount(); count != 2 {
+		t.Errorf("Expected 2 symbols, got %v", count)
+	}
+
+	// 0100 00000010 11000011 10001111
+	data, err := EncodeWrapper(block, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []byte{0b01000000, 0b00101100, 0b00111000, 0b11110000}
+	if string(data) != string(expected) {
+		t.Errorf("Expected %08b, got %08b", expected, data)
+	}
+}
+
+func TestEncoderSizeMismatch(t *testing.T) {
+	if err := ValidateEncoder(utf8ByteEncoder{}, "abc"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateEncoder(brokenEncoder{}, "abc"); !errors.Is(err, ErrEncoderSizeMismatch) {
+		t.Errorf("Expected %v, got %v", ErrEncoderSizeMismatch, err)
+	}
+
+	// the broken encoder fails at the registration, the built-in one is kept
+	restoreEncoder(t, EncodingModeByte)
+	if err := RegisterEncoder(brokenEncoder{}); !errors.Is(err, ErrEncoderSizeMismatch) {
+		t.Errorf("Expected %v, got %v", ErrEncoderSizeMismatch, err)
+	}
+	enc, _ := lookupEncoder(EncodingModeByte)
+	if _, ok := enc.(*byteEncoder); !ok {
+		t.Errorf("Expected the built-in encoder, got %T", enc)
+	}
+
+	// the size, which isn't whole bytes, doesn't give the count of byte symbols
+	if err := RegisterEncoder(bitsEncoder{}); !errors.Is(err, ErrEncoderSizeMismatch) {
+		t.Errorf("Expected %v, got %v", ErrEncoderSizeMismatch, err)
+	}
+}
+
+func TestRegisterECIEncoding(t *testing.T) {
+	const vendor = 899
+
+	if err := RegisterECIEncoding(MaxAssignmentNumber+1, charmap.KOI8R); !errors.Is(err, ErrInvalidAssignmentNumber) {
+		t.Errorf("Expected %v, got %v", ErrInvalidAssignmentNumber, err)
+	}
+	if err := RegisterECIEncoding(vendor, nil); !errors.Is(err, ErrInvalidEncoder) {
+		t.Errorf("Expected %v, got %v", ErrInvalidEncoder, err)
+	}
+
+	t.Cleanup(func() {
+		delete(assigmentNumbersEncodings, vendor)
+	})
+	if err := RegisterECIEncoding(vendor, charmap.KOI8R); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	block := &EncodeBlock{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: vendor, Data: "привет"}
+	if count := block.GetSymbolsCount(); count != 6 {
+		t.Errorf("Expected 6 symbols, got %v", count)
+	}
+
+	data, err := EncodeWrapper(block, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := DecodeData(data, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Data != block.Data || decoded[0].AssignmentNumber != vendor {
+		t.Errorf("Expected %q with assignment number %v, got %+v", block.Data, vendor, decoded)
+	}
+}
@@ -0,0 +1,97 @@
+package encode
+
+// rMQR (rectangular Micro QR Code, ISO/IEC 23941) versions R7x43-R17x139 are numbered
+// from RMQRVersionMin to RMQRVersionMax in the order of their version indicators.
+const (
+	RMQRVersionMin = 101
+	RMQRVersionMax = 132
+)
+
+// rmqrModeBits is the number of bits of the rMQR mode indicator (and the terminator).
+const rmqrModeBits = 3
+
+// Mode indicators of rMQR codes, the terminator is 000.
+// Structured Append isn't supported by rMQR codes.
+var rmqrModeIndicators = map[EncodingMode]int{
+	EncodingModeNumeric:      1,
+	EncodingModeAlphaNumeric: 2,
+	EncodingModeByte:         3,
+	EncodingModeKanji:        4,
+	EncodingModeFNC1First:    5,
+	EncodingModeFNC1Second:   6,
+	EncodingModeECI:          7,
+}
+
+// Index of the encoding mode in rmqrLengthBits.
+var rmqrLengthBitsIndex = map[EncodingMode]int{
+	EncodingModeNumeric:      0,
+	EncodingModeAlphaNumeric: 1,
+	EncodingModeByte:         2,
+	EncodingModeKanji:        3,
+}
+
+// Count of length bits for each rMQR version and encoding mode.
+// Structure: [version - RMQRVersionMin][numeric, alphanumeric, byte, kanji]
+var rmqrLengthBits = [32][4]int{
+	{4, 3, 3, 2}, // R7x43
+	{5, 5, 4, 3}, // R7x59
+	{6, 5, 5, 4}, // R7x77
+	{7, 6, 5, 5}, // R7x99
+	{7, 6, 6, 5}, // R7x139
+	{5, 5, 4, 3}, // R9x43
+	{6, 5, 5, 4}, // R9x59
+	{7, 6, 5, 5}, // R9x77
+	{7, 6, 6, 5}, // R9x99
+	{8, 7, 6, 6}, // R9x139
+	{4, 4, 3, 2}, // R11x27
+	{6, 5, 5, 4}, // R11x43
+	{7, 6, 5, 5}, // R11x59
+	{7, 6, 6, 5}, // R11x77
+	{8, 7, 6, 6}, // R11x99
+	{8, 7, 7, 6}, // R11x139
+	{5, 5, 4, 3}, // R13x27
+	{6, 6, 5, 5}, // R13x43
+	{7, 6, 6, 5}, // R13x59
+	{7, 7, 6, 6}, // R13x77
+	{8, 7, 7, 6}, // R13x99
+	{8, 8, 7, 7}, // R13x139
+	{7, 6, 6, 5}, // R15x43
+	{7, 7, 6, 5}, // R15x59
+	{8, 7, 7, 6}, // R15x77
+	{8, 7, 7, 6}, // R15x99
+	{9, 8, 7, 7}, // R15x139
+	{7, 6, 6, 5}, // R17x43
+	{8, 7, 6, 6}, // R17x59
+	{8, 7, 7, 6}, // R17x77
+	{8, 8, 7, 6}, // R17x99
+	{9, 8, 8, 7}, // R17x139
+}
+
+// IsRMQRVersion checks if the version is one of the rMQR versions.
+func IsRMQRVersion(version int) bool {
+	return version >= RMQRVersionMin && version <= RMQRVersionMax
+}
+
+// isValidVersion checks if the version is a QR Code (1-40), Micro QR Code (M1-M4) or rMQR version.
+func isValidVersion(version int) bool {
+	return (version >= -4 && version <= 40 && version != 0) || IsRMQRVersion(version)
+}
+
+// readModeRMQR reads the 3-bit rMQR mode indicator. The terminator is returned as the zero mode.
+func readModeRMQR(reader *bitReader) (EncodingMode, error) {
+	value, err := reader.Read(rmqrModeBits)
+	if err != nil {
+		return 0, err
+	}
+	if value == 0 {
+		return 0, nil
+	}
+
+	for mode, indicator := range rmqrModeIndicators {
+		if indicator == value {
+			return mode, nil
+		}
+	}
+
+	return 0, ErrInvalidData
+}
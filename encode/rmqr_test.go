@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRMQRLengthBits(t *testing.T) {
+	tests := []struct {
+		block   *EncodeBlock
+		version int
+		bits    int
+		err     error
+	}{
+		{&EncodeBlock{Mode: EncodingModeNumeric}, RMQRVersionMin, 4, nil},
+		{&EncodeBlock{Mode: EncodingModeKanji}, RMQRVersionMin, 2, nil},
+		{&EncodeBlock{Mode: EncodingModeByte}, RMQRVersionMax, 8, nil},
+		{&EncodeBlock{Mode: EncodingModeECI, SubMode: EncodingModeByte}, RMQRVersionMax, 8, nil},
+		{&EncodeBlock{Mode: EncodingModeFNC1First}, RMQRVersionMin, 0, nil},
+		{&EncodeBlock{Mode: EncodingModeStructuredAppend}, RMQRVersionMin, 0, ErrVersionDoesNotSupportEncodingMode},
+		{&EncodeBlock{Mode: EncodingModeNumeric}, RMQRVersionMax + 1, 0, ErrVersionInvalid{RMQRVersionMax + 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v %v", test.block.Mode, test.version), func(t *testing.T) {
+			bits, err := test.block.GetLengthBits(test.version)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if bits != test.bits {
+				t.Errorf("Expected %v, got %v", test.bits, bits)
+			}
+		})
+	}
+}
+
+func TestRMQREncode(t *testing.T) {
+	tests := []struct {
+		block    *EncodeBlock
+		expected []byte
+	}{
+		// 001 0011 0001111011
+		{&EncodeBlock{Mode: EncodingModeNumeric, Data: "123"}, []byte{0b00100110, 0b00111101, 0b10000000}},
+		// 111 00000011 011 001 01000001
+		{&EncodeBlock{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: 3, Data: "A"}, []byte{0b11100000, 0b01101100, 0b10100000, 0b10000000}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.block.Data, func(t *testing.T) {
+			data, err := EncodeWrapper(test.block, RMQRVersionMin)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(data, test.expected) {
+				t.Errorf("Expected %08b, got %08b", test.expected, data)
+			}
+			expectedModeBits := rmqrModeBits
+			if test.block.Mode == EncodingModeECI {
+				expectedModeBits = 2*rmqrModeBits + 8
+			}
+			if modeBits := test.block.GetModeBits(RMQRVersionMin); modeBits != expectedModeBits {
+				t.Errorf("Expected %v mode bits, got %v", expectedModeBits, modeBits)
+			}
+		})
+	}
+}
+
+func TestRMQRDecodeData(t *testing.T) {
+	blocks := []*EncodeBlock{
+		{Mode: EncodingModeFNC1First},
+		{Mode: EncodingModeNumeric, Data: "01234567"},
+		{Mode: EncodingModeAlphaNumeric, Data: "ABC"},
+		{Mode: EncodingModeByte, Data: "xyz"},
+		{Mode: EncodingModeKanji, Data: "茗荷"},
+	}
+
+	version := RMQRVersionMax
+	queue := make(chan ValueBlock, 100)
+	result := make(chan []byte)
+	go GenerateData(queue, result)
+	for _, block := range blocks {
+		if _, err := block.Encode(version, queue); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// terminator
+	queue <- ValueBlock{Value: 0, Bits: rmqrModeBits}
+	close(queue)
+
+	decoded, err := DecodeData(<-result, version)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded) != len(blocks) {
+		t.Fatalf("Expected %v blocks, got %v", len(blocks), len(decoded))
+	}
+	for idx, block := range blocks {
+		if decoded[idx].Mode != block.Mode || decoded[idx].Data != block.Data {
+			t.Errorf("Expected %v %q, got %v %q", block.Mode, block.Data, decoded[idx].Mode, decoded[idx].Data)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package encode
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// segmentModes is a list of modes considered by the segmentation algorithm.
+// The order is used as a mode index in the dynamic programming tables.
+var segmentModes = [5]EncodingMode{
+	EncodingModeNumeric,
+	EncodingModeAlphaNumeric,
+	EncodingModeByte,
+	EncodingModeKanji,
+	EncodingModeHanzi,
+}
+
+// segmentScale is a multiplier for the bit costs.
+// Numeric and alphanumeric modes spend a fractional number of bits per character (10/3 and 11/2),
+// so all costs are kept in 1/6 of a bit to stay in integers.
+const segmentScale = 6
+
+// isKanjiRune returns true if the rune can be encoded with the kanji mode.
+func isKanjiRune(r rune, buf []byte) bool {
+	if !regexpKanji.MatchString(string(r)) {
+		return false
+	}
+
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes(utf8.AppendRune(buf[:0], r))
+	if err != nil || len(sjis) != 2 {
+		return false
+	}
+
+	high := sjis[0]
+	return (high >= 0x81 && high <= 0x9F) || (high >= 0xE0 && high <= 0xEB)
+}
+
+// isHanziRune returns true if the rune is a Chinese character, which can be encoded with the hanzi mode.
+// The other GB 2312 characters (full-width symbols, Cyrillic, Greek and kana) are left to the byte and kanji modes,
+// so the hanzi mode is chosen only for the Chinese text.
+func isHanziRune(r rune) bool {
+	if !unicode.Is(unicode.Han, r) {
+		return false
+	}
+
+	_, err := gb2312Bytes(string(r))
+	return err == nil
+}
+
+// segmentRune contains the rune and the cost of the rune in each mode (-1 if the mode can't encode it).
+type segmentRune struct {
+	r    rune
+	cost [len(segmentModes)]int
+}
+
+// newSegmentBlock creates an empty block for the mode index.
+// Byte mode is encoded as UTF-8 with ECI, if the content can't be represented with ISO-8859-1.
+func newSegmentBlock(modeIdx int, utf8Mode bool) *EncodeBlock {
+	mode := segmentModes[modeIdx]
+	if mode == EncodingModeByte && utf8Mode {
+		return &EncodeBlock{
+			Mode:             EncodingModeECI,
+			SubMode:          EncodingModeByte,
+			AssignmentNumber: UTF8,
+		}
+	}
+
+	return &EncodeBlock{Mode: mode}
+}
+
+// Segment splits the content into blocks with different encoding modes,
+// so that the encoded data takes the minimal number of bits for the given version.
+// The version is required, because the count of length bits depends on it.
+//
+// The algorithm is a dynamic programming over the characters of the content:
+// for every character and every mode it keeps the cheapest encoding of the prefix
+// which ends with a block in that mode.
+// The Chinese characters of GB 2312 are encoded with the hanzi mode, if it's cheaper than the kanji mode
+// (the simplified characters out of Shift JIS or the text, which takes fewer blocks), and the version supports it.
+// If the content has characters which can't be encoded with byte (ISO-8859-1), kanji or hanzi modes,
+// byte blocks are encoded as UTF-8 with ECI.
+func Segment(content string, version int) ([]*EncodeBlock, error) {
+	if content == "" {
+		return nil, ErrCannotDeterminEncodingMode
+	}
+
+	// Micro QR and rMQR codes don't support the hanzi mode
+	_, hanziErr := (&EncodeBlock{Mode: EncodingModeHanzi}).GetLengthBits(version)
+	hanziMode := hanziErr == nil
+
+	buf := make([]byte, 0, utf8.UTFMax)
+	runes := make([]segmentRune, 0, len(content))
+	utf8Mode := false
+	for _, r := range content {
+		sr := segmentRune{r: r, cost: [len(segmentModes)]int{-1, -1, -1, -1, -1}}
+		if r >= '0' && r <= '9' {
+			sr.cost[0] = 10 * segmentScale / 3
+		}
+		if _, ok := utfToAlphaNumeric[r]; ok {
+			sr.cost[1] = 11 * segmentScale / 2
+		}
+		if r <= 0xFF {
+			sr.cost[2] = 8 * segmentScale
+		}
+		if isKanjiRune(r, buf) {
+			sr.cost[3] = 13 * segmentScale
+		}
+		if hanziMode && isHanziRune(r) {
+			sr.cost[4] = 13 * segmentScale
+		}
+		if sr.cost[2] == -1 && sr.cost[3] == -1 && sr.cost[4] == -1 {
+			utf8Mode = true
+		}
+		runes = append(runes, sr)
+	}
+
+	// In UTF-8 mode each character is encoded with 1-4 bytes
+	if utf8Mode {
+		for i := range runes {
+			runes[i].cost[2] = utf8.RuneLen(runes[i].r) * 8 * segmentScale
+		}
+	}
+
+	// Cost of the block header (mode and length bits) for each mode, -1 if the version doesn't support the mode
+	var headerCost [len(segmentModes)]int
+	for idx := range segmentModes {
+		block := newSegmentBlock(idx, utf8Mode)
+		lengthBits, err := block.GetLengthBits(version)
+		if errors.Is(err, ErrVersionDoesNotSupportEncodingMode) {
+			headerCost[idx] = -1
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		headerCost[idx] = (lengthBits + block.GetModeBits(version)) * segmentScale
+	}
+
+	// ECI isn't supported by Micro QR codes
+	if utf8Mode && version < 0 {
+		headerCost[2] = -1
+	}
+
+	// costs[i][m] is the minimal cost of the first i+1 characters, where the last character is encoded with mode m.
+	// prevModes[i][m] is the mode of the previous character for this encoding.
+	costs := make([][len(segmentModes)]int, len(runes))
+	prevModes := make([][len(segmentModes)]int, len(runes))
+
+	for i, sr := range runes {
+		for m := range segmentModes {
+			costs[i][m] = -1
+			if sr.cost[m] == -1 || headerCost[m] == -1 {
+				continue
+			}
+
+			if i == 0 {
+				costs[i][m] = headerCost[m] + sr.cost[m]
+				prevModes[i][m] = -1
+				continue
+			}
+
+			best, bestMode := -1, -1
+			for prev := range segmentModes {
+				prevCost := costs[i-1][prev]
+				if prevCost == -1 {
+					continue
+				}
+
+				// switching to a new block: round the previous block up to the whole bits and add the header
+				if prev != m {
+					prevCost = roundUpScaled(prevCost) + headerCost[m]
+				}
+
+				if best == -1 || prevCost < best {
+					best, bestMode = prevCost, prev
+				}
+			}
+
+			if best == -1 {
+				continue
+			}
+			costs[i][m] = best + sr.cost[m]
+			prevModes[i][m] = bestMode
+		}
+	}
+
+	last := len(runes) - 1
+	mode := -1
+	for m := range segmentModes {
+		if costs[last][m] == -1 {
+			continue
+		}
+		if mode == -1 || roundUpScaled(costs[last][m]) < roundUpScaled(costs[last][mode]) {
+			mode = m
+		}
+	}
+
+	if mode == -1 {
+		return nil, ErrCannotDeterminEncodingMode
+	}
+
+	// Restore the mode of each character
+	modes := make([]int, len(runes))
+	for i := last; i >= 0; i-- {
+		modes[i] = mode
+		mode = prevModes[i][mode]
+	}
+
+	var blocks []*EncodeBlock
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && modes[i] == modes[start] {
+			continue
+		}
+
+		segmentBlocks, err := splitSegment(runes[start:i], modes[start], utf8Mode, version)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, segmentBlocks...)
+		start = i
+	}
+
+	return blocks, nil
+}
+
+// roundUpScaled rounds the scaled cost up to the whole number of bits.
+func roundUpScaled(cost int) int {
+	return (cost + segmentScale - 1) / segmentScale * segmentScale
+}
+
+// splitSegment creates blocks for the characters encoded with the same mode.
+// The characters are split into several blocks, if their count doesn't fit into the length bits.
+func splitSegment(runes []segmentRune, modeIdx int, utf8Mode bool, version int) ([]*EncodeBlock, error) {
+	block := newSegmentBlock(modeIdx, utf8Mode)
+	lengthBits, err := block.GetLengthBits(version)
+	if err != nil {
+		return nil, err
+	}
+	maxCount := 1<<lengthBits - 1
+
+	var blocks []*EncodeBlock
+	var data []byte
+	count := 0
+	for _, sr := range runes {
+		symbols := 1
+		if block.Mode == EncodingModeECI {
+			symbols = utf8.RuneLen(sr.r)
+		}
+
+		if count+symbols > maxCount {
+			block.Data = string(data)
+			blocks = append(blocks, block)
+			block = newSegmentBlock(modeIdx, utf8Mode)
+			data, count = nil, 0
+		}
+
+		data = utf8.AppendRune(data, sr.r)
+		count += symbols
+	}
+
+	block.Data = string(data)
+	blocks = append(blocks, block)
+
+	return blocks, nil
+}
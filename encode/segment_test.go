@@ -0,0 +1,176 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		content string
+		version int
+		blocks  []EncodeBlock
+	}{
+		{"1234567890", 1, []EncodeBlock{{Mode: EncodingModeNumeric, Data: "1234567890"}}},
+		{"HELLO WORLD", 1, []EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "HELLO WORLD"}}},
+		{"hello", 1, []EncodeBlock{{Mode: EncodingModeByte, Data: "hello"}}},
+		{"あア亜", 1, []EncodeBlock{{Mode: EncodingModeKanji, Data: "あア亜"}}},
+		// the simplified characters out of Shift JIS take the whole text to the hanzi mode
+		{"条形码标签", 1, []EncodeBlock{{Mode: EncodingModeHanzi, Data: "条形码标签"}}},
+		{
+			"条形码 12345678", 1,
+			[]EncodeBlock{
+				{Mode: EncodingModeHanzi, Data: "条形码"},
+				{Mode: EncodingModeAlphaNumeric, Data: " "},
+				{Mode: EncodingModeNumeric, Data: "12345678"},
+			},
+		},
+		{
+			"ORDER 12345678901234 café", 1,
+			[]EncodeBlock{
+				{Mode: EncodingModeAlphaNumeric, Data: "ORDER "},
+				{Mode: EncodingModeNumeric, Data: "12345678901234"},
+				{Mode: EncodingModeByte, Data: " café"},
+			},
+		},
+		// short numeric run isn't worth a new block
+		{"abc1def", 1, []EncodeBlock{{Mode: EncodingModeByte, Data: "abc1def"}}},
+		{"A1", 1, []EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "A1"}}},
+		{
+			"привет 12345678", 1,
+			[]EncodeBlock{
+				{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: UTF8, Data: "привет "},
+				{Mode: EncodingModeNumeric, Data: "12345678"},
+			},
+		},
+
+		// Micro QR
+		{"12345", -1, []EncodeBlock{{Mode: EncodingModeNumeric, Data: "12345"}}},
+		{"AB12", -2, []EncodeBlock{{Mode: EncodingModeAlphaNumeric, Data: "AB12"}}},
+		{"ab12345678", -3, []EncodeBlock{{Mode: EncodingModeByte, Data: "ab"}, {Mode: EncodingModeNumeric, Data: "12345678"}}},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("%v, version %v", test.content, test.version)
+		t.Run(name, func(t *testing.T) {
+			blocks, err := Segment(test.content, test.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(blocks) != len(test.blocks) {
+				t.Fatalf("expected %v blocks, got %v", len(test.blocks), len(blocks))
+			}
+
+			for idx, block := range blocks {
+				if *block != test.blocks[idx] {
+					t.Errorf("expected %v, got %v", test.blocks[idx], *block)
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentSplitLongBlock(t *testing.T) {
+	// Byte mode has 8 length bits for versions 1-9, so the block can contain up to 255 bytes
+	content := strings.Repeat("a", 300)
+	blocks, err := Segment(content, 9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(blocks) != 2 || len(blocks[0].Data) != 255 || len(blocks[1].Data) != 45 {
+		t.Errorf("expected blocks with 255 and 45 bytes, got %v blocks", len(blocks))
+	}
+
+	blocks, err = Segment(content, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(blocks) != 1 {
+		t.Errorf("expected 1 block, got %v", len(blocks))
+	}
+}
+
+func TestSegmentNotLongerThanSingleMode(t *testing.T) {
+	contents := []string{
+		"https://example.com/ORDER/1234567890",
+		"ABCDEF0123456789abcdef",
+		"00000000000000000000AAAAAAAAAAAAAAAAAAAAaaaaaaaaaaaaaaaaaa",
+		"亜亜亜亜1234567890ABC",
+	}
+
+	for _, content := range contents {
+		t.Run(content, func(t *testing.T) {
+			blocks, err := Segment(content, 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			segmentedBits := 0
+			var data string
+			for _, block := range blocks {
+				bits, err := block.CalculateDataBitsCount()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				lengthBits, err := block.GetLengthBits(1)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				segmentedBits += bits + lengthBits + block.GetModeBits(1)
+				data += block.Data
+			}
+
+			if data != content {
+				t.Errorf("expected %v, got %v", content, data)
+			}
+
+			single := &EncodeBlock{
+				Mode:             EncodingModeECI,
+				SubMode:          EncodingModeByte,
+				AssignmentNumber: UTF8,
+				Data:             content,
+			}
+			singleBits, _ := single.CalculateDataBitsCount()
+			singleBits += 8 + single.GetModeBits(1)
+
+			if segmentedBits > singleBits {
+				t.Errorf("segmented data (%v bits) is longer than single block (%v bits)", segmentedBits, singleBits)
+			}
+		})
+	}
+}
+
+func TestSegmentErrors(t *testing.T) {
+	t.Run("empty content", func(t *testing.T) {
+		_, err := Segment("", 1)
+		if !errors.Is(err, ErrCannotDeterminEncodingMode) {
+			t.Errorf("expected %v, got %v", ErrCannotDeterminEncodingMode, err)
+		}
+	})
+
+	t.Run("unsupported micro version", func(t *testing.T) {
+		_, err := Segment("abc", -1)
+		if !errors.Is(err, ErrCannotDeterminEncodingMode) {
+			t.Errorf("expected %v, got %v", ErrCannotDeterminEncodingMode, err)
+		}
+	})
+
+	t.Run("utf-8 in micro version", func(t *testing.T) {
+		_, err := Segment("привет", -4)
+		if !errors.Is(err, ErrCannotDeterminEncodingMode) {
+			t.Errorf("expected %v, got %v", ErrCannotDeterminEncodingMode, err)
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := Segment("123", 41)
+		if !errors.Is(err, ErrVersionInvalid{41}) {
+			t.Errorf("expected %v, got %v", ErrVersionInvalid{41}, err)
+		}
+	})
+}
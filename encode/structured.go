@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// MaxStructuredAppendSymbols is the maximum number of symbols in a Structured Append sequence.
+const MaxStructuredAppendSymbols = 16
+
+// structuredAppendBits is the number of bits of the Structured Append header after the mode indicator:
+// 4 bits of the symbol index, 4 bits of the total number of symbols minus one and 8 bits of the parity.
+const structuredAppendBits = 16
+
+var ErrInvalidSequence = errors.New("invalid structured append sequence")
+
+// NewStructuredAppendBlock creates the header block of the symbol with the index (from 0) in the sequence
+// of total symbols. The block should be the first block of the symbol.
+func NewStructuredAppendBlock(index, total int, parity byte) (*EncodeBlock, error) {
+	if total < 1 || total > MaxStructuredAppendSymbols || index < 0 || index >= total {
+		return nil, fmt.Errorf("%w: symbol %d of %d", ErrInvalidSequence, index, total)
+	}
+
+	return &EncodeBlock{
+		Mode:          EncodingModeStructuredAppend,
+		SequenceIndex: index,
+		SequenceTotal: total,
+		Parity:        parity,
+	}, nil
+}
+
+// StructuredAppendParity returns the parity of the whole payload of the sequence: XOR of all bytes.
+func StructuredAppendParity(data []byte) byte {
+	var parity byte
+	for _, b := range data {
+		parity ^= b
+	}
+	return parity
+}
+
+// ParityBytes returns the bytes of the block data, which are counted in the Structured Append parity:
+// the written bytes of the byte and ECI modes (after the charset conversion), Shift JIS bytes of the kanji mode,
+// GB 2312 bytes of the hanzi mode and ASCII bytes of the numeric and alphanumeric modes.
+// Header blocks have no data bytes.
+func (b *EncodeBlock) ParityBytes() ([]byte, error) {
+	if _, ok := headerModeBits[b.Mode]; ok {
+		return nil, nil
+	}
+
+	switch b.Mode {
+	case EncodingModeByte, EncodingModeECI:
+		var w BitWriter
+		if err := b.WriteData(&w); err != nil {
+			return nil, err
+		}
+		return w.Bytes(), nil
+	case EncodingModeKanji:
+		buf, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(b.Data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode string to kanji: %w", err)
+		}
+		return buf, nil
+	case EncodingModeHanzi:
+		buf, err := gb2312Bytes(b.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode string to hanzi: %w", err)
+		}
+		return buf, nil
+	default:
+		return []byte(b.Data), nil
+	}
+}
+
+// BlocksParity returns the Structured Append parity of the data of the blocks (see EncodeBlock.ParityBytes).
+func BlocksParity(blocks []*EncodeBlock) (byte, error) {
+	var parity byte
+	for _, block := range blocks {
+		buf, err := block.ParityBytes()
+		if err != nil {
+			return 0, err
+		}
+		parity ^= StructuredAppendParity(buf)
+	}
+	return parity, nil
+}
@@ -0,0 +1,124 @@
+package encode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewStructuredAppendBlock(t *testing.T) {
+	tests := []struct {
+		index int
+		total int
+		err   error
+	}{
+		{0, 1, nil},
+		{3, 4, nil},
+		{15, 16, nil},
+		{0, 0, ErrInvalidSequence},
+		{0, 17, ErrInvalidSequence},
+		{4, 4, ErrInvalidSequence},
+		{-1, 4, ErrInvalidSequence},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v of %v", test.index, test.total), func(t *testing.T) {
+			block, err := NewStructuredAppendBlock(test.index, test.total, 0)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if err == nil && (block.SequenceIndex != test.index || block.SequenceTotal != test.total) {
+				t.Errorf("Expected %v of %v, got %v of %v", test.index, test.total, block.SequenceIndex, block.SequenceTotal)
+			}
+		})
+	}
+}
+
+func TestStructuredAppendParity(t *testing.T) {
+	tests := []struct {
+		data   []byte
+		parity byte
+	}{
+		{nil, 0},
+		{[]byte("A"), 0x41},
+		{[]byte("AB"), 0x03},
+		{[]byte("ABC"), 0x40},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.data), func(t *testing.T) {
+			if parity := StructuredAppendParity(test.data); parity != test.parity {
+				t.Errorf("Expected %08b, got %08b", test.parity, parity)
+			}
+		})
+	}
+}
+
+func TestParityBytes(t *testing.T) {
+	header, err := NewStructuredAppendBlock(0, 2, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		block *EncodeBlock
+		bytes []byte
+	}{
+		{"header", header, nil},
+		{"numeric", &EncodeBlock{Mode: EncodingModeNumeric, Data: "0123"}, []byte("0123")},
+		{"alphanumeric", &EncodeBlock{Mode: EncodingModeAlphaNumeric, Data: "AB:"}, []byte("AB:")},
+		{"byte", &EncodeBlock{Mode: EncodingModeByte, Data: "café"}, []byte{'c', 'a', 'f', 0xe9}},
+		{"raw bytes", NewBytesBlock([]byte{0xc3, 0x00}), []byte{0xc3, 0x00}},
+		{"eci", &EncodeBlock{Mode: EncodingModeECI, SubMode: EncodingModeByte, AssignmentNumber: UTF8, Data: "é"}, []byte{0xc3, 0xa9}},
+		{"kanji", &EncodeBlock{Mode: EncodingModeKanji, Data: "漢字"}, []byte{0x8a, 0xbf, 0x8e, 0x9a}},
+		{"hanzi", &EncodeBlock{Mode: EncodingModeHanzi, Data: "中文"}, []byte{0xd6, 0xd0, 0xce, 0xc4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := test.block.ParityBytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(buf, test.bytes) {
+				t.Errorf("Expected %x, got %x", test.bytes, buf)
+			}
+		})
+	}
+
+	blocks := []*EncodeBlock{header, tests[3].block, tests[6].block}
+	parity, err := BlocksParity(blocks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := StructuredAppendParity([]byte{'c', 'a', 'f', 0xe9, 0x8a, 0xbf, 0x8e, 0x9a}); parity != expected {
+		t.Errorf("Expected %08b, got %08b", expected, parity)
+	}
+}
+
+func TestEncodeStructuredAppend(t *testing.T) {
+	block, err := NewStructuredAppendBlock(1, 3, 0b10100101)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// mode 0011, index 0001, total-1 0010, parity 10100101
+	expected := []byte{0b00110001, 0b00101010, 0b01010000}
+	data, err := EncodeWrapper(block, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected %08b, got %08b", expected, data)
+	}
+
+	if bits, err := block.CalculateDataBitsCount(); err != nil || bits+block.GetModeBits(1) != 20 {
+		t.Errorf("Expected 20 bits, got %v (%v)", bits+block.GetModeBits(1), err)
+	}
+
+	if _, err := block.GetLengthBits(-4); !errors.Is(err, ErrVersionDoesNotSupportEncodingMode) {
+		t.Errorf("Expected %v, got %v", ErrVersionDoesNotSupportEncodingMode, err)
+	}
+}
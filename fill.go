@@ -1,5 +1,7 @@
 package qrcode
 
+import "qrcode/encode"
+
 type CellType int
 
 // Cell type represents the type of a cell in the QR code matrix.
@@ -486,11 +488,13 @@ func fillDataBlockMicro(field [][]Cell, data []byte, version int, errorLevel Err
 	}
 }
 
-// generateField creates a QR code matrix based on the given data, version and error correction level.
-func generateField(data []byte, version int, errorCorrectionLevel ErrorCorrectionLevel) [][]Cell {
-	if version < 0 {
-		return generateMicroQRField(data, version, errorCorrectionLevel)
+// createFunctionField creates a QR code matrix with the function patterns (search, sync, alignment and version patterns)
+// and empty format blocks for the given version. The rest of the cells have the CellTypeData type.
+func createFunctionField(version int) [][]Cell {
+	if encode.IsRMQRVersion(version) {
+		return createRMQRFunctionField(version)
 	}
+
 	size := getSize(version)
 	field := make([][]Cell, size)
 	for i := range field {
@@ -499,34 +503,50 @@ func generateField(data []byte, version int, errorCorrectionLevel ErrorCorrectio
 
 	fillSearchPattern(version, field)
 	fillSyncPattern(version, field)
+
+	if version < 0 {
+		fillEmptyFormatBlockMicro(field)
+		return field
+	}
+
 	fillAlignmentPattern(field, version)
 	fillVersionBlock(field, version)
 	fillEmptyFormatBlock(field)
+
+	return field
+}
+
+// generateField creates a QR code matrix based on the given data, version and error correction level.
+// The mask is the mask pattern or autoMask for the mask with the lowest penalty (rMQR codes have the only mask).
+func generateField(data []byte, version int, errorCorrectionLevel ErrorCorrectionLevel, mask int) [][]Cell {
+	if encode.IsRMQRVersion(version) {
+		return generateRMQRField(data, version, errorCorrectionLevel)
+	}
+	if version < 0 {
+		return generateMicroQRField(data, version, errorCorrectionLevel, mask)
+	}
+	field := createFunctionField(version)
 	fillDataBlock(field, data)
 
-	bestMask := determineBestMask(field, errorCorrectionLevel)
-	fillFormatBlock(field, errorCorrectionLevel, bestMask)
-	applyMask(field, bestMask)
+	if mask == autoMask {
+		mask = determineBestMask(field, errorCorrectionLevel)
+	}
+	fillFormatBlock(field, errorCorrectionLevel, mask)
+	applyMask(field, mask)
 
 	return field
 }
 
 // generateMicroQRField creates a micro QR code matrix based on the given data, version and error correction level.
-func generateMicroQRField(data []byte, version int, errorCorrectionLevel ErrorCorrectionLevel) [][]Cell {
-	size := getSize(version)
-	field := make([][]Cell, size)
-	for i := range field {
-		field[i] = make([]Cell, size)
-	}
-
-	fillSearchPattern(version, field)
-	fillSyncPattern(version, field)
-	fillEmptyFormatBlockMicro(field)
+func generateMicroQRField(data []byte, version int, errorCorrectionLevel ErrorCorrectionLevel, mask int) [][]Cell {
+	field := createFunctionField(version)
 	fillDataBlockMicro(field, data, version, errorCorrectionLevel)
 
-	bestMask := determineBestMaskMicro(field, version, errorCorrectionLevel)
-	fillFormatBlockMicro(field, version, errorCorrectionLevel, bestMask)
-	applyMask(field, microToNormalMask[bestMask].normalMask)
+	if mask == autoMask {
+		mask = determineBestMaskMicro(field, version, errorCorrectionLevel)
+	}
+	fillFormatBlockMicro(field, version, errorCorrectionLevel, mask)
+	applyMask(field, microToNormalMask[mask].normalMask)
 
 	return field
 }
@@ -0,0 +1,278 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"qrcode/encode"
+)
+
+var (
+	ErrLogoCoversPatterns = errors.New("logo covers function patterns, format or version information")
+	ErrLogoRMQR           = errors.New("rMQR codes don't support logos")
+	ErrInvalidLogoSize    = errors.New("logo size should be greater than 0 and less than 1")
+	ErrLogoFormat         = errors.New("logo required by the standard can't be drawn in the output format")
+)
+
+// logoCorrectionShare is the share of the error correction capacity of each block, which the logo can use.
+// The rest is kept for the real damage of the printed code.
+const logoCorrectionShare = 0.75
+
+// ErrLogoTooLarge is returned when the logo covers more codewords of an error correction block
+// than the error correction level can safely recover.
+type ErrLogoTooLarge struct {
+	Version    int
+	ErrorLevel ErrorCorrectionLevel
+
+	// Block is the index of the first error correction block, which can't be recovered.
+	Block int
+
+	// Covered is the number of codewords of the block covered by the logo.
+	Covered int
+
+	// Allowed is the number of codewords of the block, which the logo can cover.
+	Allowed int
+}
+
+func (e ErrLogoTooLarge) Error() string {
+	return fmt.Sprintf("logo is too large: it covers %d codewords of block %d, version %d with error level %d allows %d",
+		e.Covered, e.Block, e.Version, e.ErrorLevel, e.Allowed)
+}
+
+// Logo is an image placed at the center of the QR code. The modules under the logo are cleared.
+type Logo struct {
+	// Image is the logo image. It's scaled to fit the cleared area with the same aspect ratio.
+	// PDF, EPS and text formats only clear the area (the logos required by the standards, such as the Swiss cross,
+	// are drawn in PDF and EPS, the text formats return ErrLogoFormat).
+	Image image.Image
+
+	// Size is the size of the cleared area relative to the size of the QR code (without the border),
+	// it should be greater than 0 and less than 1. The area can't cover the finder patterns,
+	// the format or version information, the alignment and timing patterns under it are kept.
+	// Default: DEFAULT_LOGO_SIZE.
+	Size float64
+
+	// AutoAdjust increases the error correction level and then the version (if it isn't fixed),
+	// until the logo fits. It's applied only by the creation with QRCodeOptions.Logo,
+	// Plot doesn't rebuild the QR code.
+	// Default: false, the ErrLogoTooLarge error is returned.
+	AutoAdjust bool
+
+	// vector draws the logo in the square area (in pixels) for PDF and EPS,
+	// it's set for the logo required by the standard.
+	vector func(w *vectorWriter, x, y, size float64)
+}
+
+// normalizeLogo returns a copy of the logo with the default size and checks the size.
+func normalizeLogo(logo *Logo) (*Logo, error) {
+	normalized := *logo
+	if normalized.Size == 0 {
+		normalized.Size = DEFAULT_LOGO_SIZE
+	}
+	if !(normalized.Size > 0 && normalized.Size < 1) {
+		return nil, ErrInvalidLogoSize
+	}
+	return &normalized, nil
+}
+
+// logoArea returns the first module and the number of modules of the centered square cleared for the logo.
+// The square has the same parity as the QR code size, so it's centered on the modules grid.
+func logoArea(size int, ratio float64) (int, int) {
+	side := int(math.Ceil(float64(size) * ratio))
+	if (size-side)%2 != 0 {
+		side++
+	}
+	side = min(side, size)
+	return (size - side) / 2, side
+}
+
+// checkLogo checks that the logo area doesn't cover the finder patterns, the format or version information
+// and that the error correction can recover the data and error correction codewords covered by the area.
+// Each codeword with at least one covered module is counted as damaged.
+func checkLogo(version int, errorLevel ErrorCorrectionLevel, start, side int) error {
+	functionField := createFunctionField(version)
+	for y := start; y < start+side; y++ {
+		for x := start; x < start+side; x++ {
+			switch functionField[y][x].Type {
+			case CellTypeSearchPattern, CellTypeDelimiter, CellTypeFormat, CellTypeVersion:
+				return ErrLogoCoversPatterns
+			}
+		}
+	}
+
+	var codewords int
+	var blocks []ecBlock
+	if version < 0 {
+		codewords = microCodewordsCount[-version]
+		blocks = microErrorCorrectionBlocks[-version][errorLevel]
+	} else {
+		codewords = codewordsCount[version]
+		blocks = errorCorrectionBlocks[version][errorLevel]
+	}
+
+	covered := make(map[int]bool)
+	walkDataModules(functionField, codewords, version, errorLevel, func(x, y, byteIdx, bitIdx int) {
+		if x >= start && x < start+side && y >= start && y < start+side {
+			covered[byteIdx] = true
+		}
+	})
+
+	indexes, dataSizes, errorSizes := interleavedBlockIndexes(blocks)
+	coveredByBlock := make([]int, len(dataSizes))
+	for idx := range covered {
+		coveredByBlock[indexes[idx]]++
+	}
+
+	for block, count := range coveredByBlock {
+		allowed := int(float64(errorSizes[block]/2) * logoCorrectionShare)
+		if count > allowed {
+			return ErrLogoTooLarge{Version: version, ErrorLevel: errorLevel, Block: block, Covered: count, Allowed: allowed}
+		}
+	}
+
+	return nil
+}
+
+// clearLogoArea returns a copy of the matrix with the light data modules in the logo area,
+// the function patterns of the version are kept.
+func clearLogoArea(data [][]Cell, version, start, side int) [][]Cell {
+	functionField := createFunctionField(version)
+	cleared := make([][]Cell, len(data))
+	for y, row := range data {
+		cleared[y] = make([]Cell, len(row))
+		copy(cleared[y], row)
+		for x := range row {
+			if x >= start && x < start+side && y >= start && y < start+side && functionField[y][x].Type == CellTypeData {
+				cleared[y][x].Value = false
+			}
+		}
+	}
+	return cleared
+}
+
+// adjustLogo returns the version and the error correction level, which fit the blocks and the logo.
+// With AutoAdjust the error correction level and then the version (if it isn't fixed) are increased,
+// until the logo fits.
+func adjustLogo(blocks []*encode.EncodeBlock, version int, errorLevel ErrorCorrectionLevel, logo *Logo, fixedVersion bool) (int, ErrorCorrectionLevel, error) {
+	if encode.IsRMQRVersion(version) {
+		return 0, 0, ErrLogoRMQR
+	}
+
+	start, side := logoArea(getSize(version), logo.Size)
+	fitErr := checkLogo(version, errorLevel, start, side)
+	if fitErr == nil || !logo.AutoAdjust {
+		return version, errorLevel, fitErr
+	}
+
+	end, step := 40, 1
+	if version < 0 {
+		end, step = M4, -1
+	}
+	if fixedVersion {
+		end = version
+	}
+
+	for v := version; v != end+step; v += step {
+		for level := errorLevel; level <= ErrorCorrectionLevelHigh; level++ {
+			if ok, err := blocksFit(blocks, v, level); err != nil || !ok {
+				continue
+			}
+
+			start, side := logoArea(getSize(v), logo.Size)
+			if checkLogo(v, level, start, side) == nil {
+				return v, level, nil
+			}
+		}
+	}
+
+	return 0, 0, fitErr
+}
+
+// logoField returns the matrix of the QR code with the cleared logo area.
+func (qr *QRCode) logoField(logo *Logo) ([][]Cell, error) {
+	size := len(qr.Data)
+	if size > 0 && len(qr.Data[0]) != size {
+		return nil, ErrLogoRMQR
+	}
+	version, err := getVersionBySize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	errorLevel := qr.ErrorLevel
+	if qr.options == nil {
+		if version < 0 {
+			_, errorLevel, _, err = decodeFormatMicro(qr.Data)
+		} else {
+			errorLevel, _, err = decodeFormat(qr.Data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start, side := logoArea(size, logo.Size)
+	if err := checkLogo(version, errorLevel, start, side); err != nil {
+		return nil, err
+	}
+	return clearLogoArea(qr.Data, version, start, side), nil
+}
+
+// fitLogo returns the rectangle (in pixels) of the logo scaled to fit the area with the same aspect ratio.
+func fitLogo(logo image.Image, area image.Rectangle) image.Rectangle {
+	bounds := logo.Bounds()
+	if bounds.Empty() {
+		return image.Rectangle{}
+	}
+
+	ratio := math.Min(float64(area.Dx())/float64(bounds.Dx()), float64(area.Dy())/float64(bounds.Dy()))
+	width, height := int(float64(bounds.Dx())*ratio), int(float64(bounds.Dy())*ratio)
+	x, y := area.Min.X+(area.Dx()-width)/2, area.Min.Y+(area.Dy()-height)/2
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// drawLogo draws the logo scaled (nearest neighbour) to the area of the image over the cleared modules.
+func drawLogo(img draw.Image, logo image.Image, area image.Rectangle) {
+	target := fitLogo(logo, area)
+	if target.Empty() {
+		return
+	}
+
+	bounds := logo.Bounds()
+	scaled := image.NewRGBA(target)
+	for y := target.Min.Y; y < target.Max.Y; y++ {
+		for x := target.Min.X; x < target.Max.X; x++ {
+			sx := bounds.Min.X + (x-target.Min.X)*bounds.Dx()/target.Dx()
+			sy := bounds.Min.Y + (y-target.Min.Y)*bounds.Dy()/target.Dy()
+			scaled.Set(x, y, logo.At(sx, sy))
+		}
+	}
+
+	draw.Draw(img, target, scaled, target.Min, draw.Over)
+}
+
+// logoPalette returns the palette of the style colors extended with the web-safe colors for the GIF with the logo.
+func logoPalette(styleColors color.Palette) color.Palette {
+	colors := append(color.Palette{}, styleColors...)
+	colors = append(colors, palette.WebSafe...)
+	return colors[:min(len(colors), 256)]
+}
+
+// svgLogo returns the SVG image element with the logo encoded as PNG.
+func svgLogo(logo image.Image, x, y, size int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		return "", fmt.Errorf("failed to encode logo: %w", err)
+	}
+
+	return fmt.Sprintf(`<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+		x, y, size, size, base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
@@ -0,0 +1,322 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testLogo creates a red logo with the given size.
+func testLogo(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{200, 0, 0, 255})
+		}
+	}
+	return img
+}
+
+func TestLogoArea(t *testing.T) {
+	tests := []struct {
+		size  int
+		ratio float64
+		start int
+		side  int
+	}{
+		{21, 0.2, 8, 5},
+		{25, 0.2, 10, 5},
+		{25, 0.25, 9, 7},
+		{57, 0.3, 19, 19},
+		{11, 1.0, 0, 11},
+		{21, 0, 10, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("size %v, ratio %v", test.size, test.ratio), func(t *testing.T) {
+			start, side := logoArea(test.size, test.ratio)
+			if start != test.start || side != test.side {
+				t.Errorf("Expected %v %v, got %v %v", test.start, test.side, start, side)
+			}
+		})
+	}
+}
+
+func TestCheckLogo(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		level   ErrorCorrectionLevel
+		ratio   float64
+		err     error
+	}{
+		{"small logo", 5, ErrorCorrectionLevelHigh, 0.2, nil},
+		{"large logo", 5, ErrorCorrectionLevelHigh, 0.4, ErrLogoTooLarge{}},
+		{"low level", 5, ErrorCorrectionLevelLow, 0.2, ErrLogoTooLarge{}},
+		{"covers finder patterns", 5, ErrorCorrectionLevelHigh, 0.8, ErrLogoCoversPatterns},
+		{"micro format information", M4, ErrorCorrectionLevelLow, 0.2, ErrLogoCoversPatterns},
+		{"version information", 7, ErrorCorrectionLevelHigh, 0.7, ErrLogoCoversPatterns},
+		{"center alignment pattern", 7, ErrorCorrectionLevelHigh, 0.2, nil},
+		{"center alignment pattern of version 40", 40, ErrorCorrectionLevelHigh, 0.2, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, side := logoArea(getSize(test.version), test.ratio)
+			err := checkLogo(test.version, test.level, start, side)
+
+			var tooLarge ErrLogoTooLarge
+			switch test.err.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			case ErrLogoTooLarge:
+				if !errors.As(err, &tooLarge) {
+					t.Fatalf("Expected ErrLogoTooLarge, got %v", err)
+				}
+				if tooLarge.Version != test.version || tooLarge.ErrorLevel != test.level || tooLarge.Covered <= tooLarge.Allowed {
+					t.Errorf("Unexpected error details: %+v", tooLarge)
+				}
+			default:
+				if !errors.Is(err, test.err) {
+					t.Errorf("Expected %v, got %v", test.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPlotLogo(t *testing.T) {
+	content := "https://example.com/logo"
+
+	t.Run("readable", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		options := &PlotOptions{Scale: 8, Border: 32, Logo: &Logo{Image: testLogo(40, 20)}}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// the center of the code is covered by the logo
+		center := img.Bounds().Dx() / 2
+		if clr := color.NRGBAModel.Convert(img.At(center, center)); clr != (color.NRGBA{200, 0, 0, 255}) {
+			t.Errorf("Expected logo color, got %v", clr)
+		}
+
+		result, err := ReadImage(img)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Content != content {
+			t.Errorf("Expected %v, got %v", content, result.Content)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelLow})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		err = qr.Plot(&buf, &PlotOptions{Logo: &Logo{Image: testLogo(10, 10)}})
+		if !errors.As(err, &ErrLogoTooLarge{}) {
+			t.Errorf("Expected ErrLogoTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("auto adjust at plot", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelLow})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// the QR code isn't rebuilt by Plot
+		var buf bytes.Buffer
+		err = qr.Plot(&buf, &PlotOptions{Logo: &Logo{Image: testLogo(10, 10), AutoAdjust: true}})
+		if !errors.As(err, &ErrLogoTooLarge{}) {
+			t.Errorf("Expected ErrLogoTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("keeps options", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		logo := &Logo{Image: testLogo(10, 10)}
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{Logo: logo}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if logo.Size != 0 {
+			t.Errorf("Expected %v, got %v", 0, logo.Size)
+		}
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, size := range []float64{-0.1, 1, 1.5, math.NaN()} {
+			var buf bytes.Buffer
+			err := qr.Plot(&buf, &PlotOptions{Logo: &Logo{Image: testLogo(10, 10), Size: size}})
+			if !errors.Is(err, ErrInvalidLogoSize) {
+				t.Errorf("Expected %v for size %v, got %v", ErrInvalidLogoSize, size, err)
+			}
+		}
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: SVG, Logo: &Logo{Image: testLogo(10, 10)}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), `<image x="44" y="44" width="28" height="28" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,`) {
+			t.Errorf("Expected logo image, got %v", buf.String())
+		}
+	})
+
+	t.Run("gif", func(t *testing.T) {
+		qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: GIF, Logo: &Logo{Image: testLogo(10, 10)}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(img.(*image.Paletted).Palette) > 256 {
+			t.Errorf("Expected at most 256 colors, got %v", len(img.(*image.Paletted).Palette))
+		}
+	})
+}
+
+func TestCreateLogo(t *testing.T) {
+	content := "https://example.com/logo"
+
+	tests := []struct {
+		name    string
+		options *QRCodeOptions
+		version int
+		level   ErrorCorrectionLevel
+		err     error
+	}{
+		{"fits", &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelHigh, Logo: &Logo{}}, 3, ErrorCorrectionLevelHigh, nil},
+		{"too large", &QRCodeOptions{Logo: &Logo{}}, 0, 0, ErrLogoTooLarge{}},
+		{"adjusts level", &QRCodeOptions{Logo: &Logo{AutoAdjust: true}}, 2, ErrorCorrectionLevelMedium, nil},
+		{"adjusts version", &QRCodeOptions{Logo: &Logo{Size: 0.3, AutoAdjust: true}}, 3, ErrorCorrectionLevelHigh, nil},
+		{"fixed version", &QRCodeOptions{Version: 2, Logo: &Logo{Size: 0.25, AutoAdjust: true}}, 0, 0, ErrLogoTooLarge{}},
+		{"center alignment pattern", &QRCodeOptions{Version: 7, ErrorLevel: ErrorCorrectionLevelHigh, Logo: &Logo{}}, 7, ErrorCorrectionLevelHigh, nil},
+		{"invalid size", &QRCodeOptions{Logo: &Logo{Size: 2}}, 0, 0, ErrInvalidLogoSize},
+		{"rMQR", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium, Logo: &Logo{}}, 0, 0, ErrLogoRMQR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Logo.Image = testLogo(10, 10)
+			qr, err := Create(content, test.options)
+
+			switch test.err.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case ErrLogoTooLarge:
+				if !errors.As(err, &ErrLogoTooLarge{}) {
+					t.Errorf("Expected ErrLogoTooLarge, got %v", err)
+				}
+				return
+			default:
+				if !errors.Is(err, test.err) {
+					t.Errorf("Expected %v, got %v", test.err, err)
+				}
+				return
+			}
+
+			if version, _ := getVersionBySize(len(qr.Data)); version != test.version {
+				t.Errorf("Expected version %v, got %v", test.version, version)
+			}
+			if qr.ErrorLevel != test.level || qr.options.ErrorLevel != test.level || qr.options.Version != test.version {
+				t.Errorf("Expected level %v, got %v (options %+v)", test.level, qr.ErrorLevel, qr.options)
+			}
+
+			// the logo of the options is plotted and the plotted code is readable
+			var buf bytes.Buffer
+			if err := qr.Plot(&buf, &PlotOptions{Scale: 8, Border: 32}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			img, _, err := image.Decode(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			center := img.Bounds().Dx() / 2
+			if clr := color.NRGBAModel.Convert(img.At(center, center)); clr != (color.NRGBA{200, 0, 0, 255}) {
+				t.Errorf("Expected logo color, got %v", clr)
+			}
+			result, err := ReadImage(img)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Content != content || result.ErrorLevel != test.level {
+				t.Errorf("Expected %v with level %v, got %v with level %v", content, test.level, result.Content, result.ErrorLevel)
+			}
+		})
+	}
+
+	t.Run("adjusts version past 6", func(t *testing.T) {
+		// the content fits version 6, the logo over it needs version 7 with the alignment pattern at the center
+		long := content + "/" + strings.Repeat("a", 95)
+		qr, err := Create(long, &QRCodeOptions{Logo: &Logo{Image: testLogo(10, 10), AutoAdjust: true}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if version, _ := getVersionBySize(len(qr.Data)); version != 7 {
+			t.Errorf("Expected version %v, got %v", 7, version)
+		}
+
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{Scale: 8, Border: 32}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := ReadImage(img)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Content != long {
+			t.Errorf("Expected %v, got %v", long, result.Content)
+		}
+	})
+}
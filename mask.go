@@ -1,5 +1,78 @@
 package qrcode
 
+import (
+	"errors"
+	"fmt"
+
+	"qrcode/encode"
+)
+
+var ErrInvalidMask = errors.New("invalid mask")
+var ErrNoEncodedBlocks = errors.New("QR code has no encoded blocks, it should be created by Create or CreateMultiMode")
+
+// autoMask is the mask value, which selects the mask with the lowest penalty.
+const autoMask = -1
+
+// MaskPenalty is the penalty scores of one mask by the rules of the mask evaluation.
+type MaskPenalty struct {
+	// Mask is the mask pattern (0-7 for QR Codes, 0-3 for Micro QR Codes).
+	Mask int
+
+	// Rule1 is the penalty for 5 or more same color modules in a row or column.
+	Rule1 int
+
+	// Rule2 is the penalty for 2x2 blocks of the same color.
+	Rule2 int
+
+	// Rule3 is the penalty for the finder-like patterns (1:1:3:1:1 with 4 light modules).
+	Rule3 int
+
+	// Rule4 is the penalty for the deviation of the dark modules share from 50%.
+	Rule4 int
+
+	// Score is the evaluation score of the Micro QR Code mask (the rules 1-4 are not used for them):
+	// the lesser of the dark modules counts at the right and the bottom edges * 16 + the greater one.
+	// The highest score is the best.
+	Score int
+}
+
+// Total returns the sum of the penalties of all rules.
+func (p MaskPenalty) Total() int {
+	return p.Rule1 + p.Rule2 + p.Rule3 + p.Rule4
+}
+
+// PenaltyReport is the penalty scores of all masks of the QR Code.
+type PenaltyReport struct {
+	// Masks are the penalties of each mask, indexed by the mask pattern.
+	Masks []MaskPenalty
+
+	// Best is the mask with the lowest penalty (the highest score for Micro QR Codes),
+	// it's chosen if the mask isn't set in the options.
+	Best int
+
+	// Selected is the mask of the QR Code.
+	Selected int
+}
+
+// maskCount returns the number of masks of the version: 8 for QR Codes, 4 for Micro QR Codes and 1 for rMQR codes.
+func maskCount(version int) int {
+	if encode.IsRMQRVersion(version) {
+		return 1
+	}
+	if version < 0 {
+		return len(microToNormalMask)
+	}
+	return len(maskFuncs)
+}
+
+// validateMask checks that the mask exists for the version.
+func validateMask(mask, version int) error {
+	if count := maskCount(version); mask < 0 || mask >= count {
+		return fmt.Errorf("%w: %d, must be between 0 and %d for version %d", ErrInvalidMask, mask, count-1, version)
+	}
+	return nil
+}
+
 var (
 	// maskFuncs is a list of mask functions
 	maskFuncs = []func(row, col int) bool{
@@ -210,49 +283,151 @@ func calculatePenaltyRule4(data [][]Cell) int {
 }
 
 // calculatePenalty calculates the penalty for the given data using all rules
-func calculatePenalty(data [][]Cell) int {
-	rule1 := calculatePenaltyRule1(data)
-	rule2 := calculatePenaltyRule2(data)
-	rule3 := calculatePenaltyRule3(data)
-	rule4 := calculatePenaltyRule4(data)
-
-	return rule1 + rule2 + rule3 + rule4
+func calculatePenalty(data [][]Cell) MaskPenalty {
+	return MaskPenalty{
+		Rule1: calculatePenaltyRule1(data),
+		Rule2: calculatePenaltyRule2(data),
+		Rule3: calculatePenaltyRule3(data),
+		Rule4: calculatePenaltyRule4(data),
+	}
 }
 
-// determineBestMask determines the best mask for the given data and error correction level
-// The best mask is the one that gives the lowest penalty
-func determineBestMask(data [][]Cell, errorCorrectionLevel ErrorCorrectionLevel) int {
-	minPenalty := 1<<31 - 1
-	bestMask := 0
-	for maskType := 0; maskType < 8; maskType++ {
+// maskPenalties calculates the penalties of each mask for the given data and error correction level
+func maskPenalties(data [][]Cell, errorCorrectionLevel ErrorCorrectionLevel) []MaskPenalty {
+	penalties := make([]MaskPenalty, len(maskFuncs))
+	for maskType := range maskFuncs {
 		fillFormatBlock(data, errorCorrectionLevel, maskType)
 		applyMask(data, maskType)
-		penalty := calculatePenalty(data)
-		if penalty < minPenalty {
-			minPenalty = penalty
-			bestMask = maskType
-		}
+		penalties[maskType] = calculatePenalty(data)
+		penalties[maskType].Mask = maskType
 		applyMask(data, maskType)
 	}
 
-	return bestMask
+	return penalties
 }
 
-// determineBestMaskMicro determines the best mask for the given micro QR Code data, version and error correction level
-// The best mask is the one that gives the lowest penalty
-func determineBestMaskMicro(data [][]Cell, version int, errorCorrectionLevel ErrorCorrectionLevel) int {
-	minPenalty := 1<<31 - 1
-	bestMask := 0
+// calculateMicroScore calculates the evaluation score of the Micro QR Code mask.
+// SUM1 and SUM2 are the numbers of the dark modules at the right and the bottom edges
+// (without the modules of the timing patterns), the score is SUM1 * 16 + SUM2 if SUM1 <= SUM2
+// and SUM2 * 16 + SUM1 otherwise.
+func calculateMicroScore(data [][]Cell) int {
+	size := len(data)
+	sum1, sum2 := 0, 0
+	for idx := 1; idx < size; idx++ {
+		if data[idx][size-1].Value {
+			sum1++
+		}
+		if data[size-1][idx].Value {
+			sum2++
+		}
+	}
+
+	if sum1 <= sum2 {
+		return sum1*16 + sum2
+	}
+	return sum2*16 + sum1
+}
+
+// maskPenaltiesMicro calculates the evaluation scores of each mask for the given micro QR Code data, version
+// and error correction level
+func maskPenaltiesMicro(data [][]Cell, version int, errorCorrectionLevel ErrorCorrectionLevel) []MaskPenalty {
+	penalties := make([]MaskPenalty, len(microToNormalMask))
 	for _, maskMap := range microToNormalMask {
 		fillFormatBlockMicro(data, version, errorCorrectionLevel, maskMap.microMask)
 		applyMask(data, maskMap.normalMask)
-		penalty := calculatePenalty(data)
-		if penalty < minPenalty {
-			minPenalty = penalty
-			bestMask = maskMap.microMask
-		}
+		penalties[maskMap.microMask] = MaskPenalty{Mask: maskMap.microMask, Score: calculateMicroScore(data)}
 		applyMask(data, maskMap.normalMask)
 	}
 
+	return penalties
+}
+
+// lowestPenaltyMask returns the mask with the lowest total penalty, the first one for the equal penalties
+func lowestPenaltyMask(penalties []MaskPenalty) int {
+	bestMask := 0
+	for _, penalty := range penalties {
+		if penalty.Total() < penalties[bestMask].Total() {
+			bestMask = penalty.Mask
+		}
+	}
+
 	return bestMask
 }
+
+// highestScoreMask returns the mask with the highest evaluation score, the first one for the equal scores
+func highestScoreMask(penalties []MaskPenalty) int {
+	bestMask := 0
+	for _, penalty := range penalties {
+		if penalty.Score > penalties[bestMask].Score {
+			bestMask = penalty.Mask
+		}
+	}
+
+	return bestMask
+}
+
+// determineBestMask determines the best mask for the given data and error correction level
+// The best mask is the one that gives the lowest penalty
+func determineBestMask(data [][]Cell, errorCorrectionLevel ErrorCorrectionLevel) int {
+	return lowestPenaltyMask(maskPenalties(data, errorCorrectionLevel))
+}
+
+// determineBestMaskMicro determines the best mask for the given micro QR Code data, version and error correction level
+// The best mask is the one that gives the highest evaluation score
+func determineBestMaskMicro(data [][]Cell, version int, errorCorrectionLevel ErrorCorrectionLevel) int {
+	return highestScoreMask(maskPenaltiesMicro(data, version, errorCorrectionLevel))
+}
+
+// mask returns the mask from the options of the QR Code or autoMask.
+func (qr *QRCode) mask() int {
+	if qr.options == nil || qr.options.Mask == nil {
+		return autoMask
+	}
+	return *qr.options.Mask
+}
+
+// PenaltyReport returns the penalties of all masks of the QR Code, the data is encoded again.
+// rMQR codes have the only mask with the penalties of the code.
+func (qr *QRCode) PenaltyReport() (*PenaltyReport, error) {
+	if qr.blocks == nil || qr.options == nil {
+		return nil, ErrNoEncodedBlocks
+	}
+
+	version, errorLevel := qr.options.Version, qr.options.ErrorLevel
+
+	var penalties []MaskPenalty
+	switch {
+	case encode.IsRMQRVersion(version):
+		penalties = []MaskPenalty{calculatePenalty(qr.Data)}
+	case version < 0:
+		buf, err := getBytesData(qr.blocks, errorLevel, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bytes data: %w", err)
+		}
+		field := createFunctionField(version)
+		fillDataBlockMicro(field, buf, version, errorLevel)
+		penalties = maskPenaltiesMicro(field, version, errorLevel)
+	default:
+		buf, err := getBytesData(qr.blocks, errorLevel, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bytes data: %w", err)
+		}
+		field := createFunctionField(version)
+		fillDataBlock(field, buf)
+		penalties = maskPenalties(field, errorLevel)
+	}
+
+	report := &PenaltyReport{
+		Masks: penalties,
+		Best:  lowestPenaltyMask(penalties),
+	}
+	if version < 0 {
+		report.Best = highestScoreMask(penalties)
+	}
+	report.Selected = report.Best
+	if mask := qr.mask(); mask != autoMask {
+		report.Selected = mask
+	}
+
+	return report, nil
+}
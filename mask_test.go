@@ -1,6 +1,8 @@
 package qrcode
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -415,3 +417,297 @@ func TestApplyMask(t *testing.T) {
 	}
 
 }
+
+func TestCreateMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *QRCodeOptions
+		masks   int
+	}{
+		{"QR", &QRCodeOptions{Version: 2}, 8},
+		{"micro QR", &QRCodeOptions{Version: M3}, 4},
+		{"rMQR", &QRCodeOptions{Version: R7x77, ErrorLevel: ErrorCorrectionLevelMedium}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for mask := 0; mask < test.masks; mask++ {
+				test.options.Mask = &mask
+				qr, err := Create("HELLO 123", test.options)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				result, err := Decode(qr.Data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Mask != mask || result.Content != "HELLO 123" {
+					t.Errorf("Expected mask %v, got %v with content %q", mask, result.Mask, result.Content)
+				}
+			}
+
+			for _, mask := range []int{-1, test.masks} {
+				test.options.Mask = &mask
+				if _, err := Create("HELLO 123", test.options); !errors.Is(err, ErrInvalidMask) {
+					t.Errorf("Expected %v for mask %v, got %v", ErrInvalidMask, mask, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPenaltyReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *QRCodeOptions
+		masks   int
+	}{
+		{"QR", &QRCodeOptions{}, 8},
+		{"micro QR", &QRCodeOptions{MicroQR: true}, 4},
+		{"rMQR", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := Create("HELLO 123", test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			report, err := qr.PenaltyReport()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(report.Masks) != test.masks {
+				t.Fatalf("Expected %v masks, got %v", test.masks, len(report.Masks))
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Best != result.Mask || report.Selected != result.Mask {
+				t.Errorf("Expected best and selected mask %v, got %v and %v", result.Mask, report.Best, report.Selected)
+			}
+
+			for idx, penalty := range report.Masks {
+				if penalty.Mask != idx {
+					t.Errorf("Expected mask %v, got %v", idx, penalty.Mask)
+				}
+				if penalty.Total() < report.Masks[report.Best].Total() {
+					t.Errorf("Expected the lowest penalty %v for mask %v, got %v", report.Masks[report.Best].Total(), idx, penalty.Total())
+				}
+				if penalty.Score > report.Masks[report.Best].Score {
+					t.Errorf("Expected the highest score %v for mask %v, got %v", report.Masks[report.Best].Score, idx, penalty.Score)
+				}
+			}
+
+			// the penalties of the selected mask are the penalties of the QR Code
+			selected := calculatePenalty(qr.Data)
+			if test.masks == len(microToNormalMask) {
+				selected = MaskPenalty{Score: calculateMicroScore(qr.Data)}
+			}
+			selected.Mask = report.Selected
+			if report.Masks[report.Selected] != selected {
+				t.Errorf("Expected %+v, got %+v", selected, report.Masks[report.Selected])
+			}
+		})
+	}
+
+	t.Run("forced mask", func(t *testing.T) {
+		mask := 5
+		qr, err := Create("https://example.com", &QRCodeOptions{Mask: &mask})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		report, err := qr.PenaltyReport()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Selected != mask {
+			t.Errorf("Expected %v, got %v", mask, report.Selected)
+		}
+	})
+
+	t.Run("no blocks", func(t *testing.T) {
+		if _, err := (&QRCode{}).PenaltyReport(); !errors.Is(err, ErrNoEncodedBlocks) {
+			t.Errorf("Expected %v, got %v", ErrNoEncodedBlocks, err)
+		}
+	})
+}
+
+func TestCalculateMicroScore(t *testing.T) {
+	// the top row and the left column are the timing patterns, they aren't counted
+	parse := func(rows ...string) [][]Cell {
+		data := make([][]Cell, len(rows))
+		for idx, row := range rows {
+			data[idx] = make([]Cell, len(row))
+			for jdx, value := range row {
+				data[idx][jdx].Value = value == '#'
+			}
+		}
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		data     [][]Cell
+		expected int
+	}{
+		{
+			"empty edges",
+			parse(
+				"#####",
+				"#....",
+				"#....",
+				"#....",
+				"#....",
+			),
+			0,
+		},
+		{
+			// SUM1 = 2 (right), SUM2 = 3 (bottom): 2 * 16 + 3
+			"right is lesser",
+			parse(
+				"#####",
+				"#...#",
+				"#....",
+				"#....",
+				"#.###",
+			),
+			35,
+		},
+		{
+			// SUM1 = 4 (right), SUM2 = 1 (bottom): 1 * 16 + 4
+			"bottom is lesser",
+			parse(
+				"#####",
+				"#...#",
+				"#...#",
+				"#...#",
+				"#...#",
+			),
+			20,
+		},
+		{
+			// SUM1 = SUM2 = 4, the corner module is counted in both sums
+			"equal",
+			parse(
+				"#....",
+				"#...#",
+				"#...#",
+				"#...#",
+				"#####",
+			),
+			68,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if score := calculateMicroScore(test.data); score != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, score)
+			}
+		})
+	}
+}
+
+func TestDetermineBestMaskMicro(t *testing.T) {
+	// the chosen mask has the highest score of the masked symbol
+	for _, version := range []int{M1, M2, M3, M4} {
+		for _, level := range []ErrorCorrectionLevel{ErrorCorrectionLevelLow, ErrorCorrectionLevelMedium, ErrorCorrectionLevelQuartile} {
+			if microErrorCorrectionCodeWords[-version][level] == 0 {
+				continue
+			}
+
+			qr, err := Create("12345", &QRCodeOptions{Version: version, ErrorLevel: level})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			selected := calculateMicroScore(qr.Data)
+			for mask := 0; mask < len(microToNormalMask); mask++ {
+				other, err := Create("12345", &QRCodeOptions{Version: version, ErrorLevel: level, Mask: &mask})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				score := calculateMicroScore(other.Data)
+				if score > selected || (score == selected && mask < result.Mask) {
+					t.Errorf("Version %v level %v: expected mask %v with score %v, got mask %v with score %v",
+						version, level, mask, score, result.Mask, selected)
+				}
+			}
+		}
+	}
+}
+
+func TestMicroQRSpecExample(t *testing.T) {
+	// ISO/IEC 18004 Annex I: "01234567" in the version M2 with the error correction level L
+	expected := []byte{
+		// data codewords
+		0b01000000, 0b00011000, 0b10101100, 0b11000011, 0b00000000,
+		// error correction codewords
+		0b10000110, 0b00001101, 0b00100010, 0b10101110, 0b00110000,
+	}
+
+	qr, err := Create("01234567", &QRCodeOptions{Version: M2, ErrorLevel: ErrorCorrectionLevelLow})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codewords, err := getBytesData(qr.blocks, ErrorCorrectionLevelLow, M2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(codewords, expected) {
+		t.Errorf("Expected %08b, got %08b", expected, codewords)
+	}
+
+	result, err := Decode(qr.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != "01234567" || result.Version != M2 || result.ErrorLevel != ErrorCorrectionLevelLow {
+		t.Errorf("Expected 01234567 M2-L, got %+v", result)
+	}
+}
+
+func TestMicroQRSnapshot(t *testing.T) {
+	// the regression snapshot of the symbol of the ISO/IEC 18004 example (see TestMicroQRSpecExample),
+	// it's the output of this package with the mask 01, not a reference symbol of the standard or another encoder:
+	// the changes of the module placement or the masking show up here
+	qr, err := Create("01234567", &QRCodeOptions{Version: M2, ErrorLevel: ErrorCorrectionLevelLow})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	symbol := []string{
+		"#######.#.#.#",
+		"#.....#.###.#",
+		"#.###.#..##.#",
+		"#.###.#..####",
+		"#.###.#.###..",
+		"#.....#.#...#",
+		"#######..####",
+		".........##..",
+		"##.#....#...#",
+		".##.#.#.#.#.#",
+		"###..#######.",
+		"...#.#....##.",
+		"###.#..##.###",
+	}
+	if len(qr.Data) != len(symbol) {
+		t.Fatalf("Expected size %v, got %v", len(symbol), len(qr.Data))
+	}
+	for y, row := range symbol {
+		for x, value := range row {
+			if dark := value == '#'; qr.Data[y][x].Value != dark {
+				t.Errorf("Expected dark %v at %v,%v, got %v", dark, x, y, qr.Data[y][x].Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VCardVersion is the version of the vCard format.
+type VCardVersion string
+
+const (
+	// VCard3 is vCard 3.0 (RFC 2426), it's supported by most of the readers.
+	VCard3 VCardVersion = "3.0"
+	// VCard4 is vCard 4.0 (RFC 6350).
+	VCard4 VCardVersion = "4.0"
+)
+
+// DEFAULT_VCARD_VERSION is the default version of the vCard format.
+const DEFAULT_VCARD_VERSION = VCard3
+
+// mecardSpecial is the list of the characters escaped in the MeCard fields.
+const mecardSpecial = `\;,:`
+
+// Address is the postal address of the contact.
+type Address struct {
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// isEmpty returns true if all fields of the address are empty.
+func (a Address) isEmpty() bool {
+	return a == Address{}
+}
+
+// Contact is the contact information shared by VCard and MeCard.
+// Only the name is required, the empty fields are omitted.
+type Contact struct {
+	FirstName string
+	LastName  string
+	Nickname  string
+
+	Organization string
+	Title        string
+
+	// Phones are the phone numbers, spaces, dashes, dots and parentheses are removed.
+	Phones []string
+	Emails []string
+
+	Address Address
+	URL     string
+	Note    string
+
+	// Birthday is the date of birth.
+	// Default: zero time, the birthday isn't written.
+	Birthday time.Time
+}
+
+// fullName returns the formatted name of the contact: the first and the last name or the organization.
+func (c *Contact) fullName() string {
+	name := strings.TrimSpace(c.FirstName + " " + c.LastName)
+	if name == "" {
+		return c.Organization
+	}
+	return name
+}
+
+// validate checks the name, the phone numbers and the e-mails and returns the normalized phone numbers.
+func (c *Contact) validate() ([]string, error) {
+	if c.fullName() == "" {
+		return nil, ErrEmptyName
+	}
+
+	phones := make([]string, 0, len(c.Phones))
+	for _, phone := range c.Phones {
+		number, err := normalizePhoneNumber(phone)
+		if err != nil {
+			return nil, err
+		}
+		phones = append(phones, number)
+	}
+
+	for _, email := range c.Emails {
+		if !regexpEmail.MatchString(email) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+		}
+	}
+
+	return phones, nil
+}
+
+// VCard is the contact in the vCard format.
+type VCard struct {
+	Contact
+
+	// Version is the version of the format, VCard3 or VCard4.
+	// Default: DEFAULT_VCARD_VERSION.
+	Version VCardVersion
+}
+
+// Content returns the vCard.
+func (v *VCard) Content() (string, error) {
+	version := v.Version
+	if version == "" {
+		version = DEFAULT_VCARD_VERSION
+	}
+	if version != VCard3 && version != VCard4 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidVCardVersion, version)
+	}
+
+	phones, err := v.validate()
+	if err != nil {
+		return "", err
+	}
+
+	var l lines
+	l.add("BEGIN", "VCARD")
+	l.add("VERSION", string(version))
+	// N is required in vCard 3.0: family name; given name; additional names; prefixes; suffixes
+	l.add("N", escapeText(v.LastName)+";"+escapeText(v.FirstName)+";;;")
+	l.add("FN", escapeText(v.fullName()))
+	l.add("NICKNAME", escapeText(v.Nickname))
+	l.add("ORG", escapeText(v.Organization))
+	l.add("TITLE", escapeText(v.Title))
+	for _, phone := range phones {
+		if version == VCard4 {
+			l.add("TEL;VALUE=uri", "tel:"+phone)
+		} else {
+			l.add("TEL", phone)
+		}
+	}
+	for _, email := range v.Emails {
+		l.add("EMAIL", email)
+	}
+	if !v.Address.isEmpty() {
+		// post office box; extended address; street; city; region; postal code; country
+		l.add("ADR", strings.Join([]string{
+			"",
+			"",
+			escapeText(v.Address.Street),
+			escapeText(v.Address.City),
+			escapeText(v.Address.Region),
+			escapeText(v.Address.PostalCode),
+			escapeText(v.Address.Country),
+		}, ";"))
+	}
+	l.add("URL", v.URL)
+	l.add("NOTE", escapeText(v.Note))
+	if !v.Birthday.IsZero() {
+		if version == VCard4 {
+			l.add("BDAY", v.Birthday.Format("20060102"))
+		} else {
+			l.add("BDAY", v.Birthday.Format("2006-01-02"))
+		}
+	}
+	l.add("END", "VCARD")
+
+	return l.String(), nil
+}
+
+// MeCard is the contact in the MeCard format: MECARD:N:last,first;TEL:number;;
+type MeCard struct {
+	Contact
+}
+
+// Content returns the MeCard.
+func (m *MeCard) Content() (string, error) {
+	phones, err := m.validate()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		sb.WriteString(name)
+		sb.WriteByte(':')
+		sb.WriteString(value)
+		sb.WriteByte(';')
+	}
+
+	sb.WriteString("MECARD:")
+	if m.FirstName == "" && m.LastName == "" {
+		add("N", escape(m.Organization, mecardSpecial))
+	} else if m.FirstName == "" || m.LastName == "" {
+		add("N", escape(m.FirstName+m.LastName, mecardSpecial))
+	} else {
+		add("N", escape(m.LastName, mecardSpecial)+","+escape(m.FirstName, mecardSpecial))
+	}
+	add("NICKNAME", escape(m.Nickname, mecardSpecial))
+	add("ORG", escape(m.Organization, mecardSpecial))
+	add("TITLE", escape(m.Title, mecardSpecial))
+	for _, phone := range phones {
+		add("TEL", phone)
+	}
+	for _, email := range m.Emails {
+		add("EMAIL", escape(email, mecardSpecial))
+	}
+	if !m.Address.isEmpty() {
+		// post office box, room number, street, city, region, postal code, country
+		add("ADR", strings.Join([]string{
+			"",
+			"",
+			escape(m.Address.Street, mecardSpecial),
+			escape(m.Address.City, mecardSpecial),
+			escape(m.Address.Region, mecardSpecial),
+			escape(m.Address.PostalCode, mecardSpecial),
+			escape(m.Address.Country, mecardSpecial),
+		}, ","))
+	}
+	add("URL", escape(m.URL, mecardSpecial))
+	add("NOTE", escape(m.Note, mecardSpecial))
+	if !m.Birthday.IsZero() {
+		add("BDAY", m.Birthday.Format("20060102"))
+	}
+	sb.WriteByte(';')
+
+	return sb.String(), nil
+}
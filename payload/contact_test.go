@@ -0,0 +1,123 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testContact = Contact{
+	FirstName:    "Jane",
+	LastName:     "Doe, Jr.",
+	Organization: "Acme; Inc.",
+	Title:        "CTO",
+	Phones:       []string{"+1 (555) 123-4567"},
+	Emails:       []string{"jane@example.com"},
+	Address:      Address{Street: "1 Main St., Apt 2", City: "Springfield", PostalCode: "12345", Country: "USA"},
+	URL:          "https://example.com/a,b",
+	Note:         "Line 1\nLine 2: more",
+	Birthday:     time.Date(1990, time.March, 4, 0, 0, 0, 0, time.UTC),
+}
+
+func TestVCard(t *testing.T) {
+	tests := []struct {
+		name     string
+		vcard    *VCard
+		expected []string
+		err      error
+	}{
+		{
+			"version 3",
+			&VCard{Contact: testContact},
+			[]string{
+				"BEGIN:VCARD",
+				"VERSION:3.0",
+				`N:Doe\, Jr.;Jane;;;`,
+				`FN:Jane Doe\, Jr.`,
+				`ORG:Acme\; Inc.`,
+				"TITLE:CTO",
+				"TEL:+15551234567",
+				"EMAIL:jane@example.com",
+				`ADR:;;1 Main St.\, Apt 2;Springfield;;12345;USA`,
+				"URL:https://example.com/a,b",
+				`NOTE:Line 1\nLine 2: more`,
+				"BDAY:1990-03-04",
+				"END:VCARD",
+			},
+			nil,
+		},
+		{
+			"version 4",
+			&VCard{Contact: Contact{FirstName: "Jane", Phones: []string{"555 1234"}, Birthday: testContact.Birthday}, Version: VCard4},
+			[]string{
+				"BEGIN:VCARD",
+				"VERSION:4.0",
+				"N:;Jane;;;",
+				"FN:Jane",
+				"TEL;VALUE=uri:tel:5551234",
+				"BDAY:19900304",
+				"END:VCARD",
+			},
+			nil,
+		},
+		{
+			"organization",
+			&VCard{Contact: Contact{Organization: "Acme"}},
+			[]string{"BEGIN:VCARD", "VERSION:3.0", "N:;;;;", "FN:Acme", "ORG:Acme", "END:VCARD"},
+			nil,
+		},
+		{"empty name", &VCard{Contact: Contact{Phones: []string{"123"}}}, nil, ErrEmptyName},
+		{"invalid version", &VCard{Contact: testContact, Version: "2.1"}, nil, ErrInvalidVCardVersion},
+		{"invalid phone", &VCard{Contact: Contact{FirstName: "Jane", Phones: []string{"call me"}}}, nil, ErrInvalidPhoneNumber},
+		{"invalid email", &VCard{Contact: Contact{FirstName: "Jane", Emails: []string{"jane"}}}, nil, ErrInvalidEmail},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.vcard.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			expected := ""
+			if test.expected != nil {
+				expected = strings.Join(test.expected, "\r\n") + "\r\n"
+			}
+			if content != expected {
+				t.Errorf("Expected %q, got %q", expected, content)
+			}
+		})
+	}
+}
+
+func TestMeCard(t *testing.T) {
+	tests := []struct {
+		name     string
+		mecard   *MeCard
+		expected string
+		err      error
+	}{
+		{
+			"contact",
+			&MeCard{Contact: testContact},
+			`MECARD:N:Doe\, Jr.,Jane;ORG:Acme\; Inc.;TITLE:CTO;TEL:+15551234567;EMAIL:jane@example.com;` +
+				`ADR:,,1 Main St.\, Apt 2,Springfield,,12345,USA;URL:https\://example.com/a\,b;NOTE:Line 1` + "\n" + `Line 2\: more;BDAY:19900304;;`,
+			nil,
+		},
+		{"first name", &MeCard{Contact: Contact{FirstName: "Jane", Nickname: "JD"}}, "MECARD:N:Jane;NICKNAME:JD;;", nil},
+		{"organization", &MeCard{Contact: Contact{Organization: "Acme"}}, "MECARD:N:Acme;ORG:Acme;;", nil},
+		{"empty name", &MeCard{}, "", ErrEmptyName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.mecard.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, content)
+			}
+		})
+	}
+}
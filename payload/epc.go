@@ -0,0 +1,115 @@
+package payload
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EPCMaxLength is the maximum length of the EPC payload in bytes: version 13 with the error correction level M
+// holds 331 bytes, the UTF-8 ECI header of qrcode.CreateEPC takes the space of one more byte.
+const EPCMaxLength = 330
+
+var regexpPurpose = regexp.MustCompile(`^[A-Z0-9]{4}$`)
+
+// EPCTransfer is the SEPA credit transfer in the EPC069-12 format (also known as GiroCode),
+// version 002 with UTF-8 character set. Use qrcode.CreateEPC to create the QR code with the
+// required error correction level and version.
+type EPCTransfer struct {
+	// Name is the name of the beneficiary, max 70 characters.
+	Name string
+
+	// IBAN is the account of the beneficiary, spaces are removed.
+	IBAN string
+
+	// BIC is the bank of the beneficiary, 8 or 11 characters. It's optional within the EEA.
+	BIC string
+
+	// Amount is the amount in euro, 0.01 to 999999999.99.
+	// Default: 0, the amount is entered by the payer.
+	Amount float64
+
+	// Purpose is the 4 characters purpose code (ISO 20022 ExternalPurpose1Code), e.g. CHAR or GDDS.
+	Purpose string
+
+	// Reference is the structured creditor reference (e.g. ISO 11649 RF...), max 35 characters.
+	// Only one of Reference and Text can be set.
+	Reference string
+
+	// Text is the unstructured remittance information, max 140 characters.
+	Text string
+
+	// Information is the beneficiary to originator information, max 70 characters.
+	Information string
+}
+
+// Content returns the EPC payload, the lines are separated by LF.
+func (e *EPCTransfer) Content() (string, error) {
+	if err := checkRequiredField("name", e.Name, 70); err != nil {
+		return "", err
+	}
+
+	iban, err := normalizeIBAN(e.IBAN)
+	if err != nil {
+		return "", err
+	}
+
+	bic := ""
+	if e.BIC != "" {
+		if bic, err = normalizeBIC(e.BIC); err != nil {
+			return "", err
+		}
+	}
+
+	amount := ""
+	if e.Amount != 0 {
+		if amount, err = formatAmount(e.Amount); err != nil {
+			return "", err
+		}
+		amount = "EUR" + amount
+	}
+
+	if e.Purpose != "" && !regexpPurpose.MatchString(e.Purpose) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPurpose, e.Purpose)
+	}
+
+	reference := normalizeCode(e.Reference)
+	if reference != "" && e.Text != "" {
+		return "", fmt.Errorf("%w: only one of reference and text can be set", ErrInvalidReference)
+	}
+	if strings.HasPrefix(reference, "RF") && !isCreditorReference(reference) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, e.Reference)
+	}
+	if err := checkField("reference", reference, 35); err != nil {
+		return "", err
+	}
+	if err := checkField("text", e.Text, 140); err != nil {
+		return "", err
+	}
+	if err := checkField("information", e.Information, 70); err != nil {
+		return "", err
+	}
+
+	content := strings.Join([]string{
+		"BCD", // service tag
+		"002", // version
+		"1",   // character set: UTF-8
+		"SCT", // identification: SEPA credit transfer
+		bic,
+		e.Name,
+		iban,
+		amount,
+		e.Purpose,
+		reference,
+		e.Text,
+		e.Information,
+	}, "\n")
+	// the empty trailing fields can be omitted
+	content = strings.TrimRight(content, "\n")
+
+	if len(content) > EPCMaxLength {
+		return "", fmt.Errorf("%w: %d bytes, max %d", ErrPayloadTooLong, len(content), EPCMaxLength)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,71 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEPCTransfer(t *testing.T) {
+	tests := []struct {
+		name     string
+		transfer *EPCTransfer
+		expected []string
+		err      error
+	}{
+		{
+			"full",
+			&EPCTransfer{
+				Name:        "Red Cross",
+				IBAN:        "DE89 3704 0044 0532 0130 00",
+				BIC:         "COBADEFFXXX",
+				Amount:      12.5,
+				Purpose:     "CHAR",
+				Text:        "Donation; thank you",
+				Information: "Sample EPC QR code",
+			},
+			[]string{"BCD", "002", "1", "SCT", "COBADEFFXXX", "Red Cross", "DE89370400440532013000", "EUR12.50", "CHAR", "", "Donation; thank you", "Sample EPC QR code"},
+			nil,
+		},
+		{
+			"minimal",
+			&EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000"},
+			[]string{"BCD", "002", "1", "SCT", "", "Jane Doe", "DE89370400440532013000"},
+			nil,
+		},
+		{
+			"reference",
+			&EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", Amount: 1, Reference: "RF18 5390 0754 7034"},
+			[]string{"BCD", "002", "1", "SCT", "", "Jane Doe", "DE89370400440532013000", "EUR1.00", "", "RF18539007547034"},
+			nil,
+		},
+		{"empty name", &EPCTransfer{IBAN: "DE89370400440532013000"}, nil, ErrEmptyField},
+		{"long name", &EPCTransfer{Name: strings.Repeat("a", 71), IBAN: "DE89370400440532013000"}, nil, ErrFieldTooLong},
+		{"line break", &EPCTransfer{Name: "Jane\nDoe", IBAN: "DE89370400440532013000"}, nil, ErrInvalidCharacter},
+		{"iban", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013001"}, nil, ErrInvalidIBAN},
+		{"bic", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", BIC: "COBA"}, nil, ErrInvalidBIC},
+		{"amount", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", Amount: 0.001}, nil, ErrInvalidAmount},
+		{"purpose", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", Purpose: "char"}, nil, ErrInvalidPurpose},
+		{"reference and text", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", Reference: "RF18539007547034", Text: "x"}, nil, ErrInvalidReference},
+		{"creditor reference", &EPCTransfer{Name: "Jane Doe", IBAN: "DE89370400440532013000", Reference: "RF18539007547035"}, nil, ErrInvalidReference},
+		{
+			"too long",
+			&EPCTransfer{Name: strings.Repeat("ä", 70), IBAN: "DE89370400440532013000", Text: strings.Repeat("ü", 140), Information: strings.Repeat("ö", 70)},
+			nil,
+			ErrPayloadTooLong,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.transfer.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			expected := strings.Join(test.expected, "\n")
+			if content != expected {
+				t.Errorf("Expected %q, got %q", expected, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package payload
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event is the calendar event in the iCalendar format (RFC 5545), the VEVENT component.
+type Event struct {
+	Summary     string
+	Location    string
+	Description string
+
+	// Start and End of the event, the times are written in UTC.
+	// End is optional, it must not be before Start.
+	Start time.Time
+	End   time.Time
+
+	// AllDay writes only the dates of Start and End, End is the day after the last day of the event.
+	// Default: false.
+	AllDay bool
+}
+
+// Content returns the VEVENT component.
+func (e *Event) Content() (string, error) {
+	if e.Summary == "" {
+		return "", ErrEmptySummary
+	}
+	if e.Start.IsZero() {
+		return "", fmt.Errorf("%w: empty start", ErrInvalidEventTime)
+	}
+	if !e.End.IsZero() && e.End.Before(e.Start) {
+		return "", fmt.Errorf("%w: end %v is before start %v", ErrInvalidEventTime, e.End, e.Start)
+	}
+
+	var l lines
+	l.add("BEGIN", "VEVENT")
+	l.add("SUMMARY", escapeText(e.Summary))
+	if e.AllDay {
+		l.add("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+		if !e.End.IsZero() {
+			l.add("DTEND;VALUE=DATE", e.End.Format("20060102"))
+		}
+	} else {
+		l.add("DTSTART", e.Start.UTC().Format("20060102T150405Z"))
+		if !e.End.IsZero() {
+			l.add("DTEND", e.End.UTC().Format("20060102T150405Z"))
+		}
+	}
+	l.add("LOCATION", escapeText(e.Location))
+	l.add("DESCRIPTION", escapeText(e.Description))
+	l.add("END", "VEVENT")
+
+	return l.String(), nil
+}
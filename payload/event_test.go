@@ -0,0 +1,67 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	start := time.Date(2025, time.June, 1, 10, 30, 0, 0, berlin)
+
+	tests := []struct {
+		name     string
+		event    *Event
+		expected []string
+		err      error
+	}{
+		{
+			"event",
+			&Event{Summary: "Team meeting; weekly", Location: "Room 1, floor 2", Description: "Agenda:\nreview", Start: start, End: start.Add(time.Hour)},
+			[]string{
+				"BEGIN:VEVENT",
+				`SUMMARY:Team meeting\; weekly`,
+				"DTSTART:20250601T093000Z",
+				"DTEND:20250601T103000Z",
+				`LOCATION:Room 1\, floor 2`,
+				`DESCRIPTION:Agenda:\nreview`,
+				"END:VEVENT",
+			},
+			nil,
+		},
+		{
+			"all day",
+			&Event{Summary: "Holiday", Start: start, End: start.AddDate(0, 0, 2), AllDay: true},
+			[]string{
+				"BEGIN:VEVENT",
+				"SUMMARY:Holiday",
+				"DTSTART;VALUE=DATE:20250601",
+				"DTEND;VALUE=DATE:20250603",
+				"END:VEVENT",
+			},
+			nil,
+		},
+		{"no end", &Event{Summary: "Call", Start: start}, []string{"BEGIN:VEVENT", "SUMMARY:Call", "DTSTART:20250601T093000Z", "END:VEVENT"}, nil},
+		{"empty summary", &Event{Start: start}, nil, ErrEmptySummary},
+		{"empty start", &Event{Summary: "Call"}, nil, ErrInvalidEventTime},
+		{"end before start", &Event{Summary: "Call", Start: start, End: start.Add(-time.Minute)}, nil, ErrInvalidEventTime},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.event.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			expected := ""
+			if test.expected != nil {
+				expected = strings.Join(test.expected, "\r\n") + "\r\n"
+			}
+			if content != expected {
+				t.Errorf("Expected %q, got %q", expected, content)
+			}
+		})
+	}
+}
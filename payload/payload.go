@@ -0,0 +1,76 @@
+// Package payload builds the content of the QR codes of the predefined types:
+// WiFi networks, contacts (vCard and MeCard), geographic locations, phone numbers,
+// SMS messages, e-mails and calendar events.
+//
+// Each type validates its fields and escapes the special characters, the result is passed to qrcode.Create:
+//
+//	content, err := (&payload.WiFi{SSID: "home", Password: "secret"}).Content()
+//	if err != nil {
+//		panic(err)
+//	}
+//	qr, err := qrcode.Create(content, nil)
+package payload
+
+import (
+	"errors"
+	"strings"
+)
+
+// Payload is the content of a QR code of a predefined type.
+type Payload interface {
+	// Content returns the content of the QR code.
+	Content() (string, error)
+}
+
+var (
+	ErrEmptySSID             = errors.New("empty SSID")
+	ErrInvalidAuthentication = errors.New("invalid WiFi authentication")
+	ErrEmptyPassword         = errors.New("empty password")
+	ErrEmptyName             = errors.New("empty name")
+	ErrInvalidVCardVersion   = errors.New("invalid vCard version")
+	ErrInvalidCoordinates    = errors.New("invalid coordinates")
+	ErrInvalidPhoneNumber    = errors.New("invalid phone number")
+	ErrInvalidEmail          = errors.New("invalid e-mail address")
+	ErrEmptySummary          = errors.New("empty event summary")
+	ErrInvalidEventTime      = errors.New("invalid event time")
+)
+
+// escape puts a backslash before each of the special characters in the value.
+func escape(value, special string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// escapeText escapes the value of the TEXT type of vCard and iCalendar (RFC 6350, RFC 5545):
+// backslashes, semicolons and commas are escaped, line breaks are written as \n.
+func escapeText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	value = escape(value, `\;,`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+// lines joins the content lines of vCard or iCalendar with CRLF.
+type lines struct {
+	sb strings.Builder
+}
+
+// add writes the property, if the value is not empty.
+func (l *lines) add(name, value string) {
+	if value == "" {
+		return
+	}
+	l.sb.WriteString(name)
+	l.sb.WriteByte(':')
+	l.sb.WriteString(value)
+	l.sb.WriteString("\r\n")
+}
+
+func (l *lines) String() string {
+	return l.sb.String()
+}
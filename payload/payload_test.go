@@ -0,0 +1,45 @@
+package payload
+
+import "testing"
+
+var _ = []Payload{&WiFi{}, &VCard{}, &MeCard{}, &Geo{}, &Phone{}, &SMS{}, &Email{}, &Event{}, &EPCTransfer{}, &SwissQRBill{}}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value    string
+		special  string
+		expected string
+	}{
+		{"plain", `\;,:`, "plain"},
+		{`a;b,c:d\e`, `\;,:`, `a\;b\,c\:d\\e`},
+		{`say "hi"`, `"`, `say \"hi\"`},
+		{"кафе;", `;`, `кафе\;`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if result := escape(test.value, test.special); result != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain: text", "plain: text"},
+		{`a;b,c\d`, `a\;b\,c\\d`},
+		{"line 1\r\nline 2\nline 3", `line 1\nline 2\nline 3`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if result := escapeText(test.value); result != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidIBAN      = errors.New("invalid IBAN")
+	ErrInvalidBIC       = errors.New("invalid BIC")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidReference = errors.New("invalid payment reference")
+	ErrInvalidPurpose   = errors.New("invalid purpose code")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrInvalidCountry   = errors.New("invalid country code")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrFieldTooLong     = errors.New("field is too long")
+	ErrEmptyField       = errors.New("empty required field")
+	ErrPayloadTooLong   = errors.New("payload is too long")
+)
+
+// MaxAmount is the largest amount of the EPC and Swiss QR-bill payments.
+const MaxAmount = 999999999.99
+
+var regexpIBAN = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+var regexpBIC = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+var regexpCreditorReference = regexp.MustCompile(`^RF[0-9]{2}[A-Z0-9]{1,21}$`)
+
+// normalizeCode removes the spaces from the IBAN, BIC or reference and converts it to the upper case.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(code, " ", ""))
+}
+
+// mod97 returns the remainder of the division by 97 of the number, where the letters are replaced
+// with two digits (A = 10, ..., Z = 35), as in the IBAN and ISO 11649 check digits.
+func mod97(code string) int {
+	remainder := 0
+	for _, r := range code {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder
+}
+
+// normalizeIBAN validates the format and the check digits of the IBAN and returns it without spaces.
+func normalizeIBAN(iban string) (string, error) {
+	normalized := normalizeCode(iban)
+	if !regexpIBAN.MatchString(normalized) || mod97(normalized[4:]+normalized[:4]) != 1 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidIBAN, iban)
+	}
+	return normalized, nil
+}
+
+// normalizeBIC validates the format of the BIC (8 or 11 characters) and returns it without spaces.
+func normalizeBIC(bic string) (string, error) {
+	normalized := normalizeCode(bic)
+	if !regexpBIC.MatchString(normalized) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBIC, bic)
+	}
+	return normalized, nil
+}
+
+// isCreditorReference returns true if the reference is the valid ISO 11649 creditor reference (RF...).
+func isCreditorReference(reference string) bool {
+	return regexpCreditorReference.MatchString(reference) && mod97(reference[4:]+reference[:4]) == 1
+}
+
+// formatAmount returns the amount with two decimals, the amount must be between 0.01 and MaxAmount
+// and must not have fractions of cents.
+func formatAmount(amount float64) (string, error) {
+	cents := math.Round(amount * 100)
+	if math.IsNaN(amount) || amount < 0.01 || amount > MaxAmount || math.Abs(amount*100-cents) > 1e-6 {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+	return strconv.FormatFloat(cents/100, 'f', 2, 64), nil
+}
+
+// checkField checks that the field of the payment is single line and not longer than maxLength characters.
+func checkField(name, value string, maxLength int) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%w: %s contains a line break", ErrInvalidCharacter, name)
+	}
+	if length := utf8.RuneCountInString(value); length > maxLength {
+		return fmt.Errorf("%w: %s has %d characters, max %d", ErrFieldTooLong, name, length, maxLength)
+	}
+	return nil
+}
+
+// checkRequiredField checks the field as checkField and that it's not empty.
+func checkRequiredField(name, value string, maxLength int) error {
+	if value == "" {
+		return fmt.Errorf("%w: %s", ErrEmptyField, name)
+	}
+	return checkField(name, value, maxLength)
+}
@@ -0,0 +1,109 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeIBAN(t *testing.T) {
+	tests := []struct {
+		iban     string
+		expected string
+		err      error
+	}{
+		{"DE89 3704 0044 0532 0130 00", "DE89370400440532013000", nil},
+		{"ch93 0076 2011 6238 5295 7", "CH9300762011623852957", nil},
+		{"LI21088100002324013AA", "LI21088100002324013AA", nil},
+		{"DE89370400440532013001", "", ErrInvalidIBAN},
+		{"DE89", "", ErrInvalidIBAN},
+		{"1289370400440532013000", "", ErrInvalidIBAN},
+		{"", "", ErrInvalidIBAN},
+	}
+
+	for _, test := range tests {
+		t.Run(test.iban, func(t *testing.T) {
+			iban, err := normalizeIBAN(test.iban)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if iban != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, iban)
+			}
+		})
+	}
+}
+
+func TestNormalizeBIC(t *testing.T) {
+	tests := []struct {
+		bic      string
+		expected string
+		err      error
+	}{
+		{"COBADEFFXXX", "COBADEFFXXX", nil},
+		{"cobadeff", "COBADEFF", nil},
+		{"COBADEFFXX", "", ErrInvalidBIC},
+		{"COBA1EFF", "", ErrInvalidBIC},
+	}
+
+	for _, test := range tests {
+		t.Run(test.bic, func(t *testing.T) {
+			bic, err := normalizeBIC(test.bic)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if bic != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, bic)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		expected string
+		err      error
+	}{
+		{0.01, "0.01", nil},
+		{12.3, "12.30", nil},
+		{1949.75, "1949.75", nil},
+		{MaxAmount, "999999999.99", nil},
+		{0.1 + 0.2, "0.30", nil},
+		{0.001, "", ErrInvalidAmount},
+		{1.005, "", ErrInvalidAmount},
+		{-1, "", ErrInvalidAmount},
+		{1e9, "", ErrInvalidAmount},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			amount, err := formatAmount(test.amount)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if amount != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, amount)
+			}
+		})
+	}
+}
+
+func TestIsCreditorReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  bool
+	}{
+		{"RF18539007547034", true},
+		{"RF18539007547035", false},
+		{"RF18", false},
+		{"RF18539007547034539007547034", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.reference, func(t *testing.T) {
+			if result := isCreditorReference(test.reference); result != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
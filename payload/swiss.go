@@ -0,0 +1,263 @@
+package payload
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SwissQRBillMaxLength is the maximum length of the Swiss QR-bill payload in characters.
+const SwissQRBillMaxLength = 997
+
+// DEFAULT_SWISS_CURRENCY is the default currency of the Swiss QR-bill.
+const DEFAULT_SWISS_CURRENCY = "CHF"
+
+// Reference types of the Swiss QR-bill.
+const (
+	SwissReferenceQRR  = "QRR"  // QR reference, 27 digits, only with QR-IBAN
+	SwissReferenceSCOR = "SCOR" // ISO 11649 creditor reference
+	SwissReferenceNON  = "NON"  // no reference
+)
+
+var regexpCountry = regexp.MustCompile(`^[A-Z]{2}$`)
+var regexpQRReference = regexp.MustCompile(`^[0-9]{27}$`)
+
+// qrReferenceTable is the table of the recursive modulo 10 check digit of the QR reference.
+var qrReferenceTable = [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+
+// isQRReference returns true if the reference has 27 digits with the valid check digit.
+func isQRReference(reference string) bool {
+	if !regexpQRReference.MatchString(reference) {
+		return false
+	}
+
+	carry := 0
+	for _, r := range reference[:26] {
+		carry = qrReferenceTable[(carry+int(r-'0'))%10]
+	}
+	return (10-carry)%10 == int(reference[26]-'0')
+}
+
+// isQRIBAN returns true if the IBAN is the QR-IBAN: the institution identification (the digits 5-9) is 30000-31999.
+func isQRIBAN(iban string) bool {
+	iid := iban[4:9]
+	return iid >= "30000" && iid <= "31999"
+}
+
+// checkSwissCharacters checks that the value has only the characters of the Latin character set of the QR-bill:
+// Basic Latin, Latin-1 Supplement, Latin Extended-A, Ș, ș, Ț, ț and €.
+func checkSwissCharacters(name, value string) error {
+	for _, r := range value {
+		switch {
+		case r >= 0x20 && r <= 0x7E, r >= 0xA0 && r <= 0x17F:
+		case r == 'Ș', r == 'ș', r == 'Ț', r == 'ț', r == '€':
+		default:
+			return fmt.Errorf("%w: %s contains %q", ErrInvalidCharacter, name, r)
+		}
+	}
+	return nil
+}
+
+// SwissAddress is the structured address (type S) of the Swiss QR-bill.
+type SwissAddress struct {
+	// Name is the name or the company, max 70 characters.
+	Name string
+
+	// Street and BuildingNumber are optional, max 70 and 16 characters.
+	Street         string
+	BuildingNumber string
+
+	// PostalCode and Town are required, max 16 and 35 characters.
+	PostalCode string
+	Town       string
+
+	// Country is the two letters ISO 3166-1 country code.
+	Country string
+}
+
+// lines returns the 7 lines of the address: the address type and the fields.
+func (a *SwissAddress) lines(name string) ([]string, error) {
+	if a == nil {
+		return make([]string, 7), nil
+	}
+
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+		required  bool
+	}{
+		{"name", a.Name, 70, true},
+		{"street", a.Street, 70, false},
+		{"building number", a.BuildingNumber, 16, false},
+		{"postal code", a.PostalCode, 16, true},
+		{"town", a.Town, 35, true},
+	}
+
+	lines := []string{"S"}
+	for _, field := range fields {
+		fieldName := name + " " + field.name
+		var err error
+		if field.required {
+			err = checkRequiredField(fieldName, field.value, field.maxLength)
+		} else {
+			err = checkField(fieldName, field.value, field.maxLength)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSwissCharacters(fieldName, field.value); err != nil {
+			return nil, err
+		}
+		lines = append(lines, field.value)
+	}
+
+	if !regexpCountry.MatchString(a.Country) {
+		return nil, fmt.Errorf("%w: %s country %q", ErrInvalidCountry, name, a.Country)
+	}
+
+	return append(lines, a.Country), nil
+}
+
+// SwissQRBill is the payment part of the Swiss QR-bill in the SPC format, version 2.0.
+// Use qrcode.CreateSwissQRBill to create the QR code with the Swiss cross at the center.
+type SwissQRBill struct {
+	// IBAN is the account of the creditor, only CH and LI accounts are allowed. Spaces are removed.
+	IBAN string
+
+	// Creditor is the address of the creditor.
+	Creditor SwissAddress
+
+	// Amount is the amount, 0.01 to 999999999.99.
+	// Default: 0, the amount is entered by the debtor.
+	Amount float64
+
+	// Currency is CHF or EUR.
+	// Default: DEFAULT_SWISS_CURRENCY.
+	Currency string
+
+	// Debtor is the address of the debtor.
+	// Default: nil, the debtor is entered by the debtor.
+	Debtor *SwissAddress
+
+	// Reference is the QR reference (27 digits) for the QR-IBAN or the ISO 11649 creditor reference (RF...)
+	// for the regular IBAN. Spaces are removed.
+	// Default: empty, no reference (only for the regular IBAN).
+	Reference string
+
+	// Message is the unstructured message, Message and BillInformation are max 140 characters together.
+	Message string
+
+	// BillInformation is the structured information of the biller (e.g. Swico S1).
+	BillInformation string
+
+	// AlternativeSchemes are the parameters of up to 2 alternative payment procedures, max 100 characters each.
+	AlternativeSchemes []string
+}
+
+// referenceType validates the reference and returns its type depending on the IBAN.
+func (s *SwissQRBill) referenceType(iban, reference string) (string, error) {
+	if isQRIBAN(iban) {
+		if !isQRReference(reference) {
+			return "", fmt.Errorf("%w: QR-IBAN requires the QR reference, got %q", ErrInvalidReference, s.Reference)
+		}
+		return SwissReferenceQRR, nil
+	}
+
+	if reference == "" {
+		return SwissReferenceNON, nil
+	}
+	if !isCreditorReference(reference) {
+		return "", fmt.Errorf("%w: IBAN requires the creditor reference, got %q", ErrInvalidReference, s.Reference)
+	}
+	return SwissReferenceSCOR, nil
+}
+
+// Content returns the Swiss QR-bill payload, the lines are separated by LF.
+func (s *SwissQRBill) Content() (string, error) {
+	iban, err := normalizeIBAN(s.IBAN)
+	if err != nil {
+		return "", err
+	}
+	if country := iban[:2]; country != "CH" && country != "LI" {
+		return "", fmt.Errorf("%w: only CH and LI accounts are allowed, got %q", ErrInvalidIBAN, s.IBAN)
+	}
+
+	creditor, err := s.Creditor.lines("creditor")
+	if err != nil {
+		return "", err
+	}
+	debtor, err := s.Debtor.lines("debtor")
+	if err != nil {
+		return "", err
+	}
+
+	amount := ""
+	if s.Amount != 0 {
+		if amount, err = formatAmount(s.Amount); err != nil {
+			return "", err
+		}
+	}
+
+	currency := s.Currency
+	if currency == "" {
+		currency = DEFAULT_SWISS_CURRENCY
+	}
+	if currency != "CHF" && currency != "EUR" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	reference := normalizeCode(s.Reference)
+	referenceType, err := s.referenceType(iban, reference)
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range []struct{ name, value string }{{"message", s.Message}, {"bill information", s.BillInformation}} {
+		if err := checkField(field.name, field.value, 140); err != nil {
+			return "", err
+		}
+		if err := checkSwissCharacters(field.name, field.value); err != nil {
+			return "", err
+		}
+	}
+	if length := utf8.RuneCountInString(s.Message + s.BillInformation); length > 140 {
+		return "", fmt.Errorf("%w: message and bill information have %d characters, max 140", ErrFieldTooLong, length)
+	}
+
+	if len(s.AlternativeSchemes) > 2 {
+		return "", fmt.Errorf("%w: %d alternative schemes, max 2", ErrFieldTooLong, len(s.AlternativeSchemes))
+	}
+	for _, scheme := range s.AlternativeSchemes {
+		if err := checkRequiredField("alternative scheme", scheme, 100); err != nil {
+			return "", err
+		}
+		if err := checkSwissCharacters("alternative scheme", scheme); err != nil {
+			return "", err
+		}
+	}
+
+	lines := []string{
+		"SPC",  // QR type
+		"0200", // version
+		"1",    // coding type: UTF-8 restricted to the Latin character set
+		iban,
+	}
+	lines = append(lines, creditor...)
+	lines = append(lines, make([]string, 7)...) // ultimate creditor, reserved for the future use
+	lines = append(lines, amount, currency)
+	lines = append(lines, debtor...)
+	lines = append(lines, referenceType, reference, s.Message, "EPD")
+	if s.BillInformation != "" || len(s.AlternativeSchemes) > 0 {
+		lines = append(lines, s.BillInformation)
+	}
+	lines = append(lines, s.AlternativeSchemes...)
+
+	content := strings.Join(lines, "\n")
+	if length := utf8.RuneCountInString(content); length > SwissQRBillMaxLength {
+		return "", fmt.Errorf("%w: %d characters, max %d", ErrPayloadTooLong, length, SwissQRBillMaxLength)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,138 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testCreditor = SwissAddress{
+	Name:           "Robert Schneider AG",
+	Street:         "Rue du Lac",
+	BuildingNumber: "1268",
+	PostalCode:     "2501",
+	Town:           "Biel",
+	Country:        "CH",
+}
+
+func TestIsQRReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  bool
+	}{
+		{"210000000003139471430009017", true},
+		{"210000000003139471430009018", false},
+		{"21000000000313947143000901", false},
+		{"21000000000313947143000901A", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.reference, func(t *testing.T) {
+			if result := isQRReference(test.reference); result != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestSwissQRBill(t *testing.T) {
+	empty := []string{"", "", "", "", "", "", ""}
+	creditor := []string{"S", "Robert Schneider AG", "Rue du Lac", "1268", "2501", "Biel", "CH"}
+
+	join := func(parts ...[]string) []string {
+		var lines []string
+		for _, part := range parts {
+			lines = append(lines, part...)
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name     string
+		bill     *SwissQRBill
+		expected []string
+		err      error
+	}{
+		{
+			"qr reference",
+			&SwissQRBill{
+				IBAN:            "CH44 3199 9123 0008 8901 2",
+				Creditor:        testCreditor,
+				Amount:          1949.75,
+				Debtor:          &SwissAddress{Name: "Pia-Maria Rutschmann-Schnyder", Street: "Grosse Marktgasse", BuildingNumber: "28", PostalCode: "9400", Town: "Rorschach", Country: "CH"},
+				Reference:       "21 00000 00003 13947 14300 09017",
+				Message:         "Order of 15 June 2020",
+				BillInformation: "//S1/10/10201409/11/200701/20/140.000-53/30/102673831/31/200615/32/7.7/33/7.7:139.40/40/0:30",
+			},
+			join(
+				[]string{"SPC", "0200", "1", "CH4431999123000889012"},
+				creditor,
+				empty,
+				[]string{"1949.75", "CHF"},
+				[]string{"S", "Pia-Maria Rutschmann-Schnyder", "Grosse Marktgasse", "28", "9400", "Rorschach", "CH"},
+				[]string{"QRR", "210000000003139471430009017", "Order of 15 June 2020", "EPD"},
+				[]string{"//S1/10/10201409/11/200701/20/140.000-53/30/102673831/31/200615/32/7.7/33/7.7:139.40/40/0:30"},
+			),
+			nil,
+		},
+		{
+			"creditor reference",
+			&SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Currency: "EUR", Reference: "RF18539007547034"},
+			join(
+				[]string{"SPC", "0200", "1", "CH5800791123000889012"},
+				creditor,
+				empty,
+				[]string{"", "EUR"},
+				empty,
+				[]string{"SCOR", "RF18539007547034", "", "EPD"},
+			),
+			nil,
+		},
+		{
+			"no reference",
+			&SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Amount: 10, AlternativeSchemes: []string{"eBill/B/41010560425610173"}},
+			join(
+				[]string{"SPC", "0200", "1", "CH5800791123000889012"},
+				creditor,
+				empty,
+				[]string{"10.00", "CHF"},
+				empty,
+				[]string{"NON", "", "", "EPD", "", "eBill/B/41010560425610173"},
+			),
+			nil,
+		},
+		{"foreign iban", &SwissQRBill{IBAN: "DE89370400440532013000", Creditor: testCreditor}, nil, ErrInvalidIBAN},
+		{"qr-iban without reference", &SwissQRBill{IBAN: "CH4431999123000889012", Creditor: testCreditor}, nil, ErrInvalidReference},
+		{"qr reference with iban", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Reference: "210000000003139471430009017"}, nil, ErrInvalidReference},
+		{"currency", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Currency: "USD"}, nil, ErrInvalidCurrency},
+		{"amount", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Amount: -5}, nil, ErrInvalidAmount},
+		{"empty town", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: SwissAddress{Name: "AG", PostalCode: "2501", Country: "CH"}}, nil, ErrEmptyField},
+		{"country", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: SwissAddress{Name: "AG", PostalCode: "2501", Town: "Biel", Country: "Schweiz"}}, nil, ErrInvalidCountry},
+		{"character", &SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Message: "Заказ"}, nil, ErrInvalidCharacter},
+		{
+			"message length",
+			&SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, Message: strings.Repeat("a", 100), BillInformation: strings.Repeat("b", 41)},
+			nil,
+			ErrFieldTooLong,
+		},
+		{
+			"alternative schemes",
+			&SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor, AlternativeSchemes: []string{"a", "b", "c"}},
+			nil,
+			ErrFieldTooLong,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.bill.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			expected := strings.Join(test.expected, "\n")
+			if content != expected {
+				t.Errorf("Expected %q, got %q", expected, content)
+			}
+		})
+	}
+}
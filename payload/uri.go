@@ -0,0 +1,158 @@
+package payload
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var regexpPhoneNumber = regexp.MustCompile(`^\+?[0-9]+$`)
+var regexpEmail = regexp.MustCompile(`^[^@\s?&#]+@[^@\s?&#]+$`)
+
+// normalizePhoneNumber removes the visual separators (spaces, dashes, dots and parentheses)
+// from the phone number and checks that only digits with an optional leading plus are left.
+func normalizePhoneNumber(number string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" -.()", r) {
+			return -1
+		}
+		return r
+	}, number)
+
+	if !regexpPhoneNumber.MatchString(normalized) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPhoneNumber, number)
+	}
+
+	return normalized, nil
+}
+
+// formatFloat writes the number without the exponent and the trailing zeros.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Geo is the geographic location in the format geo:latitude,longitude[,altitude] (RFC 5870).
+type Geo struct {
+	// Latitude is the latitude in degrees, -90 to 90.
+	Latitude float64
+
+	// Longitude is the longitude in degrees, -180 to 180.
+	Longitude float64
+
+	// Altitude is the altitude in meters.
+	// Default: 0, the altitude isn't written.
+	Altitude float64
+}
+
+// Content returns the geo URI.
+func (g *Geo) Content() (string, error) {
+	for _, value := range []float64{g.Latitude, g.Longitude, g.Altitude} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return "", fmt.Errorf("%w: %v", ErrInvalidCoordinates, value)
+		}
+	}
+	if g.Latitude < -90 || g.Latitude > 90 {
+		return "", fmt.Errorf("%w: latitude %v", ErrInvalidCoordinates, g.Latitude)
+	}
+	if g.Longitude < -180 || g.Longitude > 180 {
+		return "", fmt.Errorf("%w: longitude %v", ErrInvalidCoordinates, g.Longitude)
+	}
+
+	content := "geo:" + formatFloat(g.Latitude) + "," + formatFloat(g.Longitude)
+	if g.Altitude != 0 {
+		content += "," + formatFloat(g.Altitude)
+	}
+
+	return content, nil
+}
+
+// Phone is the phone number in the format tel:number (RFC 3966).
+type Phone struct {
+	// Number is the phone number, the international numbers start with a plus.
+	// Spaces, dashes, dots and parentheses are removed.
+	Number string
+}
+
+// Content returns the tel URI.
+func (p *Phone) Content() (string, error) {
+	number, err := normalizePhoneNumber(p.Number)
+	if err != nil {
+		return "", err
+	}
+
+	return "tel:" + number, nil
+}
+
+// SMS is the text message in the format SMSTO:number:message.
+type SMS struct {
+	// Number is the phone number of the recipient.
+	Number string
+
+	// Message is the text of the message, it may be empty.
+	Message string
+}
+
+// Content returns the SMS.
+func (s *SMS) Content() (string, error) {
+	number, err := normalizePhoneNumber(s.Number)
+	if err != nil {
+		return "", err
+	}
+
+	// the message is the rest of the content after the number, so it isn't escaped
+	return "SMSTO:" + number + ":" + s.Message, nil
+}
+
+// Email is the e-mail message in the format mailto:address?subject=...&body=... (RFC 6068).
+type Email struct {
+	// To is the address of the recipient.
+	To string
+
+	// Cc and Bcc are the addresses of the other recipients.
+	Cc  []string
+	Bcc []string
+
+	// Subject and Body of the message, they may be empty.
+	Subject string
+	Body    string
+}
+
+// mailtoEscape percent-encodes the value of the mailto field, spaces are encoded as %20.
+func mailtoEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// Content returns the mailto URI.
+func (e *Email) Content() (string, error) {
+	addresses := append([]string{e.To}, e.Cc...)
+	addresses = append(addresses, e.Bcc...)
+	for _, address := range addresses {
+		if !regexpEmail.MatchString(address) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidEmail, address)
+		}
+	}
+
+	var fields []string
+	if len(e.Cc) > 0 {
+		fields = append(fields, "cc="+strings.Join(e.Cc, ","))
+	}
+	if len(e.Bcc) > 0 {
+		fields = append(fields, "bcc="+strings.Join(e.Bcc, ","))
+	}
+	if e.Subject != "" {
+		fields = append(fields, "subject="+mailtoEscape(e.Subject))
+	}
+	if e.Body != "" {
+		fields = append(fields, "body="+mailtoEscape(e.Body))
+	}
+
+	content := "mailto:" + e.To
+	if len(fields) > 0 {
+		content += "?" + strings.Join(fields, "&")
+	}
+
+	return content, nil
+}
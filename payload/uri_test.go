@@ -0,0 +1,120 @@
+package payload
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGeo(t *testing.T) {
+	tests := []struct {
+		name     string
+		geo      *Geo
+		expected string
+		err      error
+	}{
+		{"location", &Geo{Latitude: 48.2010, Longitude: 16.3695}, "geo:48.201,16.3695", nil},
+		{"altitude", &Geo{Latitude: -33.8688, Longitude: 151.2093, Altitude: 58}, "geo:-33.8688,151.2093,58", nil},
+		{"zero", &Geo{}, "geo:0,0", nil},
+		{"latitude", &Geo{Latitude: 91}, "", ErrInvalidCoordinates},
+		{"longitude", &Geo{Longitude: -181}, "", ErrInvalidCoordinates},
+		{"nan", &Geo{Latitude: math.NaN()}, "", ErrInvalidCoordinates},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.geo.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, content)
+			}
+		})
+	}
+}
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected string
+		err      error
+	}{
+		{"+1 (555) 123-45.67", "tel:+15551234567", nil},
+		{"112", "tel:112", nil},
+		{"", "", ErrInvalidPhoneNumber},
+		{"+1 555 CALL", "", ErrInvalidPhoneNumber},
+		{"1+2", "", ErrInvalidPhoneNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			content, err := (&Phone{Number: test.number}).Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, content)
+			}
+		})
+	}
+}
+
+func TestSMS(t *testing.T) {
+	tests := []struct {
+		name     string
+		sms      *SMS
+		expected string
+		err      error
+	}{
+		{"message", &SMS{Number: "+49 170 1234567", Message: "Meet at 10:30; ok?"}, "SMSTO:+491701234567:Meet at 10:30; ok?", nil},
+		{"empty message", &SMS{Number: "12345"}, "SMSTO:12345:", nil},
+		{"invalid number", &SMS{Number: "abc", Message: "hi"}, "", ErrInvalidPhoneNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.sms.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, content)
+			}
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    *Email
+		expected string
+		err      error
+	}{
+		{"address", &Email{To: "info@example.com"}, "mailto:info@example.com", nil},
+		{
+			"fields",
+			&Email{To: "info@example.com", Cc: []string{"a@example.com", "b@example.com"}, Subject: "Hello & welcome", Body: "Line 1\nLine 2 = 100%"},
+			"mailto:info@example.com?cc=a@example.com,b@example.com&subject=Hello%20%26%20welcome&body=Line%201%0ALine%202%20%3D%20100%25",
+			nil,
+		},
+		{"plus", &Email{To: "a+b@example.com", Subject: "1+1"}, "mailto:a+b@example.com?subject=1%2B1", nil},
+		{"bcc", &Email{To: "a@example.com", Bcc: []string{"b@example.com"}}, "mailto:a@example.com?bcc=b@example.com", nil},
+		{"empty", &Email{}, "", ErrInvalidEmail},
+		{"invalid cc", &Email{To: "a@example.com", Cc: []string{"b example.com"}}, "", ErrInvalidEmail},
+		{"query in address", &Email{To: "a@example.com?subject=x"}, "", ErrInvalidEmail},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.email.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, content)
+			}
+		})
+	}
+}
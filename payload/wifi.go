@@ -0,0 +1,78 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WiFiAuthentication is the authentication type of the WiFi network.
+type WiFiAuthentication string
+
+const (
+	WiFiWPA        WiFiAuthentication = "WPA"
+	WiFiWEP        WiFiAuthentication = "WEP"
+	WiFiNoPassword WiFiAuthentication = "nopass"
+)
+
+// DEFAULT_WIFI_AUTHENTICATION is the default authentication type of the WiFi network.
+const DEFAULT_WIFI_AUTHENTICATION = WiFiWPA
+
+// wifiSpecial is the list of the characters escaped in the WiFi fields.
+const wifiSpecial = `\;,:"`
+
+// WiFi is the configuration of a WiFi network in the format WIFI:T:WPA;S:ssid;P:password;H:true;;
+type WiFi struct {
+	// SSID is the name of the network.
+	SSID string
+
+	// Password is the password of the network, it's required for WPA and WEP.
+	Password string
+
+	// Authentication is the authentication type: WiFiWPA (WPA/WPA2/WPA3), WiFiWEP or WiFiNoPassword.
+	// Default: DEFAULT_WIFI_AUTHENTICATION.
+	Authentication WiFiAuthentication
+
+	// Hidden is true if the network doesn't broadcast its SSID.
+	// Default: false.
+	Hidden bool
+}
+
+// Content returns the WiFi configuration.
+func (w *WiFi) Content() (string, error) {
+	if w.SSID == "" {
+		return "", ErrEmptySSID
+	}
+
+	authentication := w.Authentication
+	if authentication == "" {
+		authentication = DEFAULT_WIFI_AUTHENTICATION
+	}
+
+	switch authentication {
+	case WiFiWPA, WiFiWEP:
+		if w.Password == "" {
+			return "", fmt.Errorf("%w: %s network requires a password", ErrEmptyPassword, authentication)
+		}
+	case WiFiNoPassword:
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidAuthentication, authentication)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("WIFI:T:")
+	sb.WriteString(string(authentication))
+	sb.WriteString(";S:")
+	sb.WriteString(escape(w.SSID, wifiSpecial))
+	sb.WriteString(";")
+	if authentication != WiFiNoPassword {
+		sb.WriteString("P:")
+		sb.WriteString(escape(w.Password, wifiSpecial))
+		sb.WriteString(";")
+	}
+	if w.Hidden {
+		sb.WriteString("H:true;")
+	}
+	sb.WriteString(";")
+
+	return sb.String(), nil
+}
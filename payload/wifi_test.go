@@ -0,0 +1,35 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWiFi(t *testing.T) {
+	tests := []struct {
+		name     string
+		wifi     *WiFi
+		expected string
+		err      error
+	}{
+		{"wpa", &WiFi{SSID: "home", Password: "secret"}, "WIFI:T:WPA;S:home;P:secret;;", nil},
+		{"wep hidden", &WiFi{SSID: "home", Password: "secret", Authentication: WiFiWEP, Hidden: true}, "WIFI:T:WEP;S:home;P:secret;H:true;;", nil},
+		{"no password", &WiFi{SSID: "cafe", Authentication: WiFiNoPassword}, "WIFI:T:nopass;S:cafe;;", nil},
+		{"escaping", &WiFi{SSID: `"my;net"`, Password: `a\b,c:d`}, `WIFI:T:WPA;S:\"my\;net\";P:a\\b\,c\:d;;`, nil},
+		{"empty ssid", &WiFi{Password: "secret"}, "", ErrEmptySSID},
+		{"empty password", &WiFi{SSID: "home"}, "", ErrEmptyPassword},
+		{"invalid authentication", &WiFi{SSID: "home", Password: "secret", Authentication: "WPA4"}, "", ErrInvalidAuthentication},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.wifi.Content()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected %v, got %v", test.err, err)
+			}
+			if content != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"qrcode/encode"
+	"qrcode/payload"
+)
+
+const (
+	// epcMaxVersion is the largest version of the EPC QR code (EPC069-12).
+	epcMaxVersion = 13
+
+	// swissQRBillMaxVersion is the largest version of the Swiss QR code.
+	swissQRBillMaxVersion = 25
+
+	// swissCrossSize is the size of the Swiss cross (7 mm) relative to the size of the Swiss QR code (46 mm).
+	swissCrossSize = 7.0 / 46.0
+)
+
+// createPayment creates the QR code of the payment with the UTF-8 content (ECI 26) and the error correction level M.
+// The version is the smallest one up to maxVersion, the logo should fit it.
+// The ECI header is kept for all payloads, the content too long for maxVersion with it is reported
+// with ErrCapacityExceeded (ErrContentTooLong).
+func createPayment(content string, maxVersion int, logo *Logo) (*QRCode, error) {
+	blocks := []*encode.EncodeBlock{{
+		Mode:             encode.EncodingModeECI,
+		SubMode:          encode.EncodingModeByte,
+		AssignmentNumber: encode.UTF8,
+		Data:             content,
+	}}
+
+	versions := make([]int, maxVersion)
+	for i := range versions {
+		versions[i] = i + 1
+	}
+	version, err := minVersion(blocks, ErrorCorrectionLevelMedium, versions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate min version: %w", err)
+	}
+
+	return CreateMultiMode(blocks, &QRCodeOptionsMultiMode{
+		ErrorLevel: ErrorCorrectionLevelMedium,
+		Version:    version,
+		Logo:       logo,
+	})
+}
+
+// CreateEPC creates the QR code of the SEPA credit transfer (EPC069-12, GiroCode)
+// with the error correction level M and the version up to 13.
+func CreateEPC(transfer *payload.EPCTransfer) (*QRCode, error) {
+	content, err := transfer.Content()
+	if err != nil {
+		return nil, fmt.Errorf("invalid EPC transfer: %w", err)
+	}
+
+	return createPayment(content, epcMaxVersion, nil)
+}
+
+// CreateSwissQRBill creates the QR code of the Swiss QR-bill with the error correction level M
+// and the version up to 25. The Swiss cross is placed at the center of the QR code, when it's plotted
+// without another logo. It's drawn as an image in PNG, JPEG, GIF and SVG and as vector paths in PDF and EPS,
+// the text formats can't draw it and return ErrLogoFormat.
+// The QR code should be printed 46x46 mm (without the quiet zone), so the cross is 7x7 mm.
+func CreateSwissQRBill(bill *payload.SwissQRBill) (*QRCode, error) {
+	content, err := bill.Content()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Swiss QR-bill: %w", err)
+	}
+
+	// the cross is required by the standard, it's drawn over the alignment pattern at the center from version 7
+	return createPayment(content, swissQRBillMaxVersion, &Logo{
+		Image:  swissCross(70),
+		Size:   swissCrossSize,
+		vector: swissCrossVector,
+	})
+}
+
+// swissCrossGeometry returns the margin of the black square and the width and the length of the arms
+// of the cross in the square area of the size.
+// The proportions are 0.5 mm margin of the 7 mm square and the cross of the Swiss flag (arms 6/32 wide and 20/32 long).
+func swissCrossGeometry(size float64) (margin, armWidth, armLength float64) {
+	margin = size / 14
+	side := size - 2*margin
+	return margin, side * 6 / 32, side * 20 / 32
+}
+
+// swissCrossVector draws the Swiss cross in the square area for PDF and EPS.
+func swissCrossVector(w *vectorWriter, x, y, size float64) {
+	margin, armWidth, armLength := swissCrossGeometry(size)
+	center := size / 2
+
+	rectangles := []struct {
+		clr                 color.Color
+		x, y, width, height float64
+	}{
+		{color.White, 0, 0, size, size},
+		{color.Black, margin, margin, size - 2*margin, size - 2*margin},
+		{color.White, center - armWidth/2, center - armLength/2, armWidth, armLength},
+		{color.White, center - armLength/2, center - armWidth/2, armLength, armWidth},
+	}
+	for _, rect := range rectangles {
+		w.setColor(rect.clr)
+		w.rectangle(x+rect.x, y+rect.y, rect.width, rect.height)
+		if w.pdf {
+			w.fill(false)
+		}
+	}
+}
+
+// swissCross returns the image of the Swiss cross: the black square with the white cross and the white margin.
+func swissCross(size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	squareMargin, armWidth, armLength := swissCrossGeometry(float64(size))
+	margin := int(squareMargin)
+	square := image.Rect(margin, margin, size-margin, size-margin)
+	draw.Draw(img, square, image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	center := float64(size) / 2
+	rect := func(width, height float64) image.Rectangle {
+		return image.Rect(
+			int(center-width/2+0.5), int(center-height/2+0.5),
+			int(center+width/2+0.5), int(center+height/2+0.5),
+		)
+	}
+	draw.Draw(img, rect(armWidth, armLength), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, rect(armLength, armWidth), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	return img
+}
@@ -0,0 +1,318 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"qrcode/encode"
+	"qrcode/payload"
+)
+
+var testSwissQRBill = &payload.SwissQRBill{
+	IBAN: "CH4431999123000889012",
+	Creditor: payload.SwissAddress{
+		Name:           "Robert Schneider AG",
+		Street:         "Rue du Lac",
+		BuildingNumber: "1268",
+		PostalCode:     "2501",
+		Town:           "Biel",
+		Country:        "CH",
+	},
+	Amount:    1949.75,
+	Reference: "210000000003139471430009017",
+	Message:   "Auftrag vom 15.06.2020",
+}
+
+// checkPaymentQRCode checks the error correction level, the version limit and the UTF-8 ECI block of the payment QR code.
+func checkPaymentQRCode(t *testing.T, qr *QRCode, data [][]Cell, content string, maxVersion int) {
+	t.Helper()
+
+	result, err := Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != content {
+		t.Errorf("Expected %q, got %q", content, result.Content)
+	}
+	if result.ErrorLevel != ErrorCorrectionLevelMedium {
+		t.Errorf("Expected level %v, got %v", ErrorCorrectionLevelMedium, result.ErrorLevel)
+	}
+	if result.Version < 1 || result.Version > maxVersion {
+		t.Errorf("Expected version up to %v, got %v", maxVersion, result.Version)
+	}
+	if len(qr.blocks) != 1 {
+		t.Fatalf("Expected a single block, got %+v", qr.blocks)
+	}
+	if qr.blocks[0].Mode != encode.EncodingModeECI || qr.blocks[0].AssignmentNumber != encode.UTF8 {
+		t.Errorf("Expected UTF-8 ECI block, got %+v", qr.blocks[0])
+	}
+}
+
+func TestCreateEPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		transfer *payload.EPCTransfer
+	}{
+		{"minimal", &payload.EPCTransfer{Name: "Red Cross", IBAN: "DE89370400440532013000"}},
+		{"full", &payload.EPCTransfer{Name: "Rotes Kreuz", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX", Amount: 100, Purpose: "CHAR", Text: "Spende für Kinder"}},
+		{
+			// the longest payload (330 bytes) fits version 13 with the ECI header
+			"longest",
+			&payload.EPCTransfer{
+				Name:        strings.Repeat("N", 70),
+				IBAN:        "DE89370400440532013000",
+				BIC:         "COBADEFFXXX",
+				Amount:      payload.MaxAmount,
+				Purpose:     "CHAR",
+				Text:        strings.Repeat("T", 139),
+				Information: strings.Repeat("I", 48),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.transfer.Content()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.name == "longest" && len(content) != payload.EPCMaxLength {
+				t.Fatalf("Expected %v bytes, got %v", payload.EPCMaxLength, len(content))
+			}
+			qr, err := CreateEPC(test.transfer)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checkPaymentQRCode(t, qr, qr.Data, content, epcMaxVersion)
+		})
+	}
+
+	if _, err := CreateEPC(&payload.EPCTransfer{Name: "Red Cross", IBAN: "DE00"}); !errors.Is(err, payload.ErrInvalidIBAN) {
+		t.Errorf("Expected %v, got %v", payload.ErrInvalidIBAN, err)
+	}
+}
+
+func TestCreateSwissQRBill(t *testing.T) {
+	tests := []struct {
+		name string
+		bill *payload.SwissQRBill
+	}{
+		{"bill", testSwissQRBill},
+		{"bill information", &payload.SwissQRBill{
+			IBAN:            testSwissQRBill.IBAN,
+			Creditor:        testSwissQRBill.Creditor,
+			Debtor:          &testSwissQRBill.Creditor,
+			Reference:       testSwissQRBill.Reference,
+			Message:         strings.Repeat("M", 40),
+			BillInformation: strings.Repeat("B", 100),
+			AlternativeSchemes: []string{
+				strings.Repeat("A", 100),
+				strings.Repeat("Z", 100),
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := test.bill.Content()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			qr, err := CreateSwissQRBill(test.bill)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// the Swiss cross clears the modules at the center, the error correction recovers them
+			data, err := qr.logoField(qr.logo)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checkPaymentQRCode(t, qr, data, content, swissQRBillMaxVersion)
+		})
+	}
+
+	if _, err := CreateSwissQRBill(&payload.SwissQRBill{IBAN: "CH5800791123000889012"}); !errors.Is(err, payload.ErrEmptyField) {
+		t.Errorf("Expected %v, got %v", payload.ErrEmptyField, err)
+	}
+}
+
+func TestPlotSwissQRBill(t *testing.T) {
+	qr, err := CreateSwissQRBill(testSwissQRBill)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := qr.Plot(&buf, &PlotOptions{Scale: 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, _, err := image.Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the center of the cross is white, the black square is around it
+	size := img.Bounds().Dx()
+	center := size / 2
+	cross := int(float64(size) * swissCrossSize)
+	if r, _, _, _ := img.At(center, center).RGBA(); r != 0xffff {
+		t.Errorf("Expected white center of the cross, got %v", img.At(center, center))
+	}
+	corner := center - cross*3/8
+	if r, _, _, _ := img.At(corner, corner).RGBA(); r != 0 {
+		t.Errorf("Expected black square of the cross, got %v", img.At(corner, corner))
+	}
+
+	// another logo of the plot options replaces the cross
+	buf.Reset()
+	if err := qr.Plot(&buf, &PlotOptions{Scale: 10, Logo: &Logo{Image: testLogo(10, 10), Size: 0.1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, _, err = image.Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clr := color.NRGBAModel.Convert(img.At(center, center)); clr != (color.NRGBA{200, 0, 0, 255}) {
+		t.Errorf("Expected logo color, got %v", clr)
+	}
+}
+
+func TestPlotSwissQRBillVector(t *testing.T) {
+	content, err := testSwissQRBill.Content()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	qr, err := CreateSwissQRBill(testSwissQRBill)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	size := len(qr.Data)
+
+	t.Run("pdf", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{OutputFormat: PDF, Scale: 10, Border: 40, ModuleSize: 46 * Millimeter / Length(size)}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fills := parsePDFFills(t, buf.String())
+
+		start, side := logoArea(size, swissCrossSize)
+		pixel := float64(options.ModuleSize) / float64(options.Scale)
+		area := float64(side*options.Scale) * pixel
+		center := float64(start*options.Scale+options.Border)*pixel + area/2
+		margin, armWidth, armLength := swissCrossGeometry(area)
+		tests := []struct {
+			name   string
+			dx, dy float64
+			dark   bool
+		}{
+			{"center of the cross", 0, 0, false},
+			{"vertical arm", 0, armLength * 0.4, false},
+			{"horizontal arm", -armLength * 0.4, 0, false},
+			{"black square", armWidth, armWidth, true},
+			{"corner of the black square", area/2 - margin*1.5, -area/2 + margin*1.5, true},
+			{"margin", area/2 - margin/2, 0, false},
+		}
+		for _, test := range tests {
+			if dark := pdfDark(fills, center+test.dx, center+test.dy); dark != test.dark {
+				t.Errorf("Expected dark %v at the %v, got %v", test.dark, test.name, dark)
+			}
+		}
+
+		result, err := Decode(pdfModules(t, buf.String(), size, size, options))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Content != content {
+			t.Errorf("Expected %q, got %q", content, result.Content)
+		}
+	})
+
+	t.Run("eps", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: EPS, Scale: 1, ModuleSize: Point}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		start, side := logoArea(size, swissCrossSize)
+		cross := &vectorWriter{pixel: 1, height: float64(size)}
+		swissCrossVector(cross, float64(start), float64(start), float64(side))
+		if !strings.HasSuffix(buf.String(), cross.buf.String()+"showpage\n%%EOF\n") {
+			t.Errorf("Expected the cross at the end, got %v", buf.String())
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: ASCII}); !errors.Is(err, ErrLogoFormat) {
+			t.Errorf("Expected %v, got %v", ErrLogoFormat, err)
+		}
+	})
+}
+
+func TestSwissCross(t *testing.T) {
+	cross := swissCross(70)
+
+	tests := []struct {
+		x, y  int
+		white bool
+	}{
+		{0, 0, true},    // margin
+		{6, 6, false},   // black square
+		{35, 35, true},  // center of the cross
+		{35, 18, true},  // vertical arm
+		{18, 35, true},  // horizontal arm
+		{20, 20, false}, // between the arms
+	}
+
+	for _, test := range tests {
+		r, _, _, _ := cross.At(test.x, test.y).RGBA()
+		if white := r == 0xffff; white != test.white {
+			t.Errorf("Expected white %v at %v,%v, got %v", test.white, test.x, test.y, white)
+		}
+	}
+}
+
+func TestSwissCrossFits(t *testing.T) {
+	// the shortest Swiss QR-bill payload (74 bytes) needs version 5
+	for version := 5; version <= swissQRBillMaxVersion; version++ {
+		start, side := logoArea(getSize(version), swissCrossSize)
+		if err := checkLogo(version, ErrorCorrectionLevelMedium, start, side); err != nil {
+			t.Errorf("Expected the cross to fit version %v, got %v", version, err)
+		}
+	}
+}
+
+func TestCreateEPCTooLong(t *testing.T) {
+	// 331 bytes fit version 13 only without the ECI header, the payload rejects them
+	for _, text := range []string{strings.Repeat("T", 138) + "ü", strings.Repeat("T", 140)} {
+		transfer := &payload.EPCTransfer{
+			Name:        strings.Repeat("N", 70),
+			IBAN:        "DE89370400440532013000",
+			BIC:         "COBADEFFXXX",
+			Amount:      payload.MaxAmount,
+			Purpose:     "CHAR",
+			Text:        text,
+			Information: strings.Repeat("I", 48),
+		}
+
+		if _, err := CreateEPC(transfer); !errors.Is(err, payload.ErrPayloadTooLong) {
+			t.Errorf("Expected %v, got %v", payload.ErrPayloadTooLong, err)
+		}
+	}
+
+	// the content too long for the version with the ECI header is reported with the capacity error
+	_, err := createPayment(strings.Repeat("T", payload.EPCMaxLength+1), epcMaxVersion, nil)
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Errorf("Expected %v, got %v", ErrContentTooLong, err)
+	}
+	var capacityErr ErrCapacityExceeded
+	if !errors.As(err, &capacityErr) || capacityErr.Version != epcMaxVersion || capacityErr.ErrorLevel != ErrorCorrectionLevelMedium {
+		t.Errorf("Expected capacity error of version %v, got %v", epcMaxVersion, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
@@ -17,8 +18,25 @@ const (
 	PNG  = "png"
 	JPEG = "jpeg"
 	GIF  = "gif"
+	SVG  = "svg"
+	PDF  = "pdf"
+	EPS  = "eps"
+
+	// Text output formats, the border is the quiet zone in modules
+	HALF_BLOCKS          = "half-blocks"
+	HALF_BLOCKS_INVERTED = "half-blocks-inverted"
+	ASCII                = "ascii"
+	ASCII_INVERTED       = "ascii-inverted"
 )
 
+// matrixSize returns the width and the height of the matrix in modules.
+func matrixSize(data [][]Cell) (int, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	return len(data[0]), len(data)
+}
+
 // plotRectangle fills a rectangle in the image with the given color
 func plotRectangle(img *image.RGBA, x, y, size, shift int, clr color.Color) {
 	for idx := x * size; idx < (x+1)*size; idx++ {
@@ -30,46 +48,87 @@ func plotRectangle(img *image.RGBA, x, y, size, shift int, clr color.Color) {
 
 // plotBorder fills the border of the image with the given color
 func plotBorder(img *image.RGBA, border int, clr color.Color) {
-	size := img.Bounds().Size().X
-	for idx := 0; idx < size; idx++ {
-		for borderIdx := 0; borderIdx < border; borderIdx++ {
+	width, height := img.Bounds().Size().X, img.Bounds().Size().Y
+	for borderIdx := 0; borderIdx < border; borderIdx++ {
+		for idx := 0; idx < height; idx++ {
 			img.Set(borderIdx, idx, clr)
+			img.Set(width-1-borderIdx, idx, clr)
+		}
+		for idx := 0; idx < width; idx++ {
 			img.Set(idx, borderIdx, clr)
-			img.Set(size-1-borderIdx, idx, clr)
-			img.Set(idx, size-1-borderIdx, clr)
+			img.Set(idx, height-1-borderIdx, clr)
 		}
 	}
 }
 
-// plot creates a PNG image from the given data and writes it to the writer
-func plot(data [][]Cell, writer io.Writer, scale, border int, outputFormat OutputFormat) error {
-	imgSize := len(data)*scale + 2*border
+// plot creates an image from the given data and writes it to the writer
+func plot(data [][]Cell, writer io.Writer, options *PlotOptions) error {
+	scale, border, outputFormat := options.Scale, options.Border, options.OutputFormat
 
-	img := image.NewRGBA(image.Rect(0, 0, imgSize, imgSize))
+	// text formats are drawn with the ANSI colors (HALF_BLOCKS) or the colors of the terminal (the other ones)
+	switch outputFormat {
+	case HALF_BLOCKS, HALF_BLOCKS_INVERTED, ASCII, ASCII_INVERTED:
+		if options.Logo != nil && options.Logo.vector != nil {
+			return fmt.Errorf("%w: %s", ErrLogoFormat, outputFormat)
+		}
+		return plotText(data, writer, border, outputFormat)
+	}
+
+	style, err := newPlotStyle(options)
+	if err != nil {
+		return fmt.Errorf("invalid style: %w", err)
+	}
+	background := style.background
+
+	switch outputFormat {
+	case SVG:
+		return plotSVG(data, writer, scale, border, style, options.Logo)
+	case PDF, EPS:
+		return plotVector(data, writer, scale, border, options.ModuleSize, style, options.Logo, outputFormat)
+	case JPEG:
+		if isTransparent(background) {
+			return fmt.Errorf("%w: %s", ErrTransparentBackground, outputFormat)
+		}
+	}
+
+	width, height := matrixSize(data)
+	width, height = width*scale+2*border, height*scale+2*border
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
 	for idx, row := range data {
-		for jdx, cell := range row {
-			clr := image.White
-			if cell.Value {
-				clr = image.Black
-			}
-			plotRectangle(img, jdx, idx, scale, border, clr)
+		for jdx := range row {
+			plotRectangle(img, jdx, idx, scale, border, background)
 		}
 	}
+	drawStyled(img, data, scale, border, style)
+	if logo := options.Logo; logo != nil && logo.Image != nil {
+		start, side := logoArea(len(data), logo.Size)
+		area := image.Rect(start*scale+border, start*scale+border, (start+side)*scale+border, (start+side)*scale+border)
+		drawLogo(img, logo.Image, area)
+	}
 
 	// Draw border
 	if border > 0 {
-		plotBorder(img, border, image.White)
+		plotBorder(img, border, background)
 	}
 
-	var err error
-
 	switch outputFormat {
 	case PNG:
 		err = png.Encode(writer, img)
 	case JPEG:
 		err = jpeg.Encode(writer, img, nil)
 	case GIF:
-		err = gif.Encode(writer, img, nil)
+		// the palette of the style colors keeps the exact colors and the transparency of the background
+		palette := style.palette()
+		drawer := draw.Drawer(draw.Src)
+		if options.Logo != nil && options.Logo.Image != nil {
+			// the logo colors are approximated with the web-safe colors
+			palette = logoPalette(palette)
+			drawer = draw.FloydSteinberg
+		}
+		paletted := image.NewPaletted(img.Bounds(), palette)
+		drawer.Draw(paletted, paletted.Bounds(), img, image.Point{})
+		err = gif.Encode(writer, paletted, nil)
 	default:
 		err = fmt.Errorf("unsupported output format: %s", outputFormat)
 	}
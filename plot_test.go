@@ -18,6 +18,19 @@ var (
 	}
 )
 
+// imageModules samples the modules of the image plotted with the scale and the border at the centers of the modules.
+func imageModules(img image.Image, width, height, scale, border int) [][]Cell {
+	modules := make([][]Cell, height)
+	for y := range modules {
+		modules[y] = make([]Cell, width)
+		for x := range modules[y] {
+			r, g, b, _ := img.At(x*scale+border+scale/2, y*scale+border+scale/2).RGBA()
+			modules[y][x].Value = r+g+b < 3*0x8000
+		}
+	}
+	return modules
+}
+
 func TestPlotRectangle(t *testing.T) {
 	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
 	step := 2
@@ -109,7 +122,7 @@ func TestPlot(t *testing.T) {
 		for _, format := range formats {
 			t.Run(string(format), func(t *testing.T) {
 				var buf bytes.Buffer
-				err := plot(data, &buf, 1, 0, format)
+				err := plot(data, &buf, &PlotOptions{Scale: 1, OutputFormat: format})
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
@@ -146,7 +159,7 @@ func TestPlot(t *testing.T) {
 		var buf InvalidWriter
 
 		// Call the function and check for error
-		err := plot(data, &buf, 1, 0, PNG)
+		err := plot(data, &buf, &PlotOptions{Scale: 1, OutputFormat: PNG})
 		if err == nil {
 			t.Error("expected an error, but got nil")
 		}
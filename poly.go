@@ -1,5 +1,12 @@
 package qrcode
 
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTooManyErrors = errors.New("too many errors to correct")
+
 // gfMul multiplies two values in Galois Field
 func gfMul(a, b byte) byte {
 	if a == 0 || b == 0 {
@@ -103,6 +110,240 @@ func (p *polynomial) Modulo(other *polynomial) *polynomial {
 	return rest
 }
 
+// Evaluate returns the value of the polynomial in the given point (Horner's method)
+func (p *polynomial) Evaluate(x byte) byte {
+	var result byte
+	for _, c := range p.Coefficients {
+		result = gfMul(result, x) ^ c
+	}
+	return result
+}
+
+// gfAlphaPow raises alpha (generator of Galois Field) to the given power
+func gfAlphaPow(power int) byte {
+	power %= 255
+	if power < 0 {
+		power += 255
+	}
+	return exp[power]
+}
+
+// calculateSyndromes calculates the syndromes of the codeword: the values of the codeword polynomial in the roots
+// of the generator polynomial (alpha^0 ... alpha^(ecCodewords-1)).
+// All syndromes are zero if the codeword has no errors.
+func calculateSyndromes(codeword []byte, ecCodewords int) ([]byte, bool) {
+	codewordPoly := &polynomial{codeword}
+	syndromes := make([]byte, ecCodewords)
+	hasErrors := false
+	for i := range syndromes {
+		syndromes[i] = codewordPoly.Evaluate(gfAlphaPow(i))
+		if syndromes[i] != 0 {
+			hasErrors = true
+		}
+	}
+	return syndromes, hasErrors
+}
+
+// findErrorLocator finds the error locator polynomial with the Berlekamp-Massey algorithm.
+// The coefficients of the result are in ascending order of the degrees (the first one is always 1).
+func findErrorLocator(syndromes []byte) []byte {
+	locator := []byte{1}
+	prevLocator := []byte{1}
+	errorsCount := 0
+	shift := 1
+	var prevDiscrepancy byte = 1
+
+	for n := range syndromes {
+		discrepancy := syndromes[n]
+		for i := 1; i <= errorsCount && i < len(locator); i++ {
+			discrepancy ^= gfMul(locator[i], syndromes[n-i])
+		}
+
+		if discrepancy == 0 {
+			shift++
+			continue
+		}
+
+		factor := gfDiv(discrepancy, prevDiscrepancy)
+		newLocator := make([]byte, max(len(locator), len(prevLocator)+shift))
+		copy(newLocator, locator)
+		for i, c := range prevLocator {
+			newLocator[i+shift] ^= gfMul(factor, c)
+		}
+
+		if 2*errorsCount <= n {
+			prevLocator = locator
+			errorsCount = n + 1 - errorsCount
+			prevDiscrepancy = discrepancy
+			shift = 1
+		} else {
+			shift++
+		}
+		locator = newLocator
+	}
+
+	for len(locator) > 1 && locator[len(locator)-1] == 0 {
+		locator = locator[:len(locator)-1]
+	}
+
+	return locator
+}
+
+// evaluateAscending returns the value of the polynomial with coefficients in ascending order of the degrees
+func evaluateAscending(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = gfMul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// multiplyAscending multiplies two polynomials with coefficients in ascending order of the degrees
+func multiplyAscending(a, b []byte) []byte {
+	result := make([]byte, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			result[i+j] ^= gfMul(x, y)
+		}
+	}
+	return result
+}
+
+// calculateForneySyndromes removes the influence of the erasures from the syndromes.
+// For each erasure locator X the syndromes are transformed as S'(j) = X*S(j) + S(j+1),
+// so the result can be used for finding the locator of the rest (unknown) errors.
+func calculateForneySyndromes(syndromes []byte, erasureLocators []byte) []byte {
+	forneySyndromes := make([]byte, len(syndromes))
+	copy(forneySyndromes, syndromes)
+	for _, x := range erasureLocators {
+		for j := 0; j < len(forneySyndromes)-1; j++ {
+			forneySyndromes[j] = gfMul(forneySyndromes[j], x) ^ forneySyndromes[j+1]
+		}
+		forneySyndromes = forneySyndromes[:len(forneySyndromes)-1]
+	}
+	return forneySyndromes
+}
+
+// correctErrors corrects the errors and the erasures (errors with known positions) in the codeword
+// (data codewords followed by error correction codewords) using the Reed-Solomon decoding:
+// Berlekamp-Massey algorithm for the error locator, Chien search for the error positions
+// and Forney algorithm for the error values.
+// The protection codewords (misdecode protection, p) only detect errors, so the codeword can be corrected
+// if 2*errors + erasures <= ecCodewords - protection.
+// It returns the corrected codeword, the number of corrected errors and the number of corrected erasures.
+func correctErrors(codeword []byte, ecCodewords int, protection int, erasures []int) ([]byte, int, int, error) {
+	n := len(codeword)
+	budget := ecCodewords - protection
+	if len(erasures) > budget {
+		return nil, 0, 0, ErrTooManyErrors
+	}
+
+	syndromes, hasErrors := calculateSyndromes(codeword, ecCodewords)
+	if !hasErrors {
+		return codeword, 0, 0, nil
+	}
+
+	// The codeword index k corresponds to the power n-1-k, its locator is alpha^(n-1-k)
+	erasureLocators := make([]byte, 0, len(erasures))
+	erasureLocator := []byte{1}
+	seen := make(map[int]bool, len(erasures))
+	for _, k := range erasures {
+		if k < 0 || k >= n {
+			return nil, 0, 0, fmt.Errorf("erasure position %d is out of the codeword: %w", k, ErrTooManyErrors)
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		x := gfAlphaPow(n - 1 - k)
+		erasureLocators = append(erasureLocators, x)
+		erasureLocator = multiplyAscending(erasureLocator, []byte{1, x})
+	}
+
+	errorLocator := findErrorLocator(calculateForneySyndromes(syndromes, erasureLocators))
+	errorsCount := len(errorLocator) - 1
+	erasuresCount := len(erasureLocators)
+	if 2*errorsCount+erasuresCount > budget {
+		return nil, 0, 0, ErrTooManyErrors
+	}
+
+	// Errata locator has roots for both errors and erasures
+	locator := multiplyAscending(errorLocator, erasureLocator)
+
+	// Chien search: the codeword index k is an errata position if the locator has a root alpha^-(n-1-k)
+	var positions []int
+	for k := 0; k < n; k++ {
+		if evaluateAscending(locator, gfAlphaPow(-(n-1-k))) == 0 {
+			positions = append(positions, k)
+		}
+	}
+
+	if len(positions) != len(locator)-1 {
+		return nil, 0, 0, ErrTooManyErrors
+	}
+
+	// Errata evaluator: syndromes * locator mod x^ecCodewords
+	evaluator := make([]byte, ecCodewords)
+	for i, s := range syndromes {
+		for j, l := range locator {
+			if i+j < ecCodewords {
+				evaluator[i+j] ^= gfMul(s, l)
+			}
+		}
+	}
+
+	// Formal derivative of the locator: only odd degrees remain in Galois Field of characteristic 2
+	derivative := make([]byte, len(locator)-1)
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	corrected := make([]byte, n)
+	copy(corrected, codeword)
+	for _, k := range positions {
+		power := n - 1 - k
+		xInverse := gfAlphaPow(-power)
+		denominator := evaluateAscending(derivative, xInverse)
+		if denominator == 0 {
+			return nil, 0, 0, ErrTooManyErrors
+		}
+		value := gfMul(gfAlphaPow(power), gfDiv(evaluateAscending(evaluator, xInverse), denominator))
+		corrected[k] ^= value
+	}
+
+	if _, hasErrors := calculateSyndromes(corrected, ecCodewords); hasErrors {
+		return nil, 0, 0, ErrTooManyErrors
+	}
+
+	return corrected, errorsCount, erasuresCount, nil
+}
+
+// correctBlock corrects the received codewords of the error correction block with the given geometry.
+// The protection is the number of misdecode protection codewords (see misdecodeProtection),
+// the erasures are the indexes of the codewords in the block, which are known to be damaged.
+// It returns the corrected data codewords and the statistics of the correction.
+func correctBlock(received []byte, block ecBlock, protection int, erasures []int) ([]byte, BlockCorrection, error) {
+	correction := BlockCorrection{
+		ErrorCorrectionCodewords: block.TotalCodewords - block.DataCodewords,
+		MisdecodeProtection:      protection,
+	}
+
+	if len(received) != block.TotalCodewords {
+		return nil, correction, fmt.Errorf("block has %d codewords, expected %d", len(received), block.TotalCodewords)
+	}
+
+	corrected, errorsCount, erasuresCount, err := correctErrors(received, correction.ErrorCorrectionCodewords, protection, erasures)
+	if err != nil {
+		return nil, correction, err
+	}
+
+	correction.Errors = errorsCount
+	correction.Erasures = erasuresCount
+
+	return corrected[:block.DataCodewords], correction, nil
+}
+
 // log and exp tables for Galois Field
 var log [256]uint16 = [256]uint16{0, 0, 1, 25, 2, 50, 26, 198, 3, 223, 51, 238, 27, 104, 199, 75, 4, 100, 224, 14, 52, 141, 239, 129, 28, 193, 105, 248, 200, 8, 76, 113, 5, 138, 101, 47, 225, 36, 15, 33, 53, 147, 142, 218, 240, 18, 130, 69, 29, 181, 194, 125, 106, 39, 249, 185, 201, 154, 9, 120, 77, 228, 114, 166, 6, 191, 139, 98, 102, 221, 48, 253, 226, 152, 37, 179, 16, 145, 34, 136, 54, 208, 148, 206, 143, 150, 219, 189, 241, 210, 19, 92, 131, 56, 70, 64, 30, 66, 182, 163, 195, 72, 126, 110, 107, 58, 40, 84, 250, 133, 186, 61, 202, 94, 155, 159, 10, 21, 121, 43, 78, 212, 229, 172, 115, 243, 167, 87, 7, 112, 192, 247, 140, 128, 99, 13, 103, 74, 222, 237, 49, 197, 254, 24, 227, 165, 153, 119, 38, 184, 180, 124, 17, 68, 146, 217, 35, 32, 137, 46, 55, 63, 209, 91, 149, 188, 207, 205, 144, 135, 151, 178, 220, 252, 190, 97, 242, 86, 211, 171, 20, 42, 93, 158, 132, 60, 57, 83, 71, 109, 65, 162, 31, 45, 67, 216, 183, 123, 164, 118, 196, 23, 73, 236, 127, 12, 111, 246, 108, 161, 59, 82, 41, 157, 85, 170, 251, 96, 134, 177, 187, 204, 62, 90, 203, 89, 95, 176, 156, 169, 160, 81, 11, 245, 22, 235, 122, 117, 44, 215, 79, 174, 213, 233, 230, 231, 173, 232, 116, 214, 244, 234, 168, 80, 88, 175}
 var exp [256]byte = [256]byte{1, 2, 4, 8, 16, 32, 64, 128, 29, 58, 116, 232, 205, 135, 19, 38, 76, 152, 45, 90, 180, 117, 234, 201, 143, 3, 6, 12, 24, 48, 96, 192, 157, 39, 78, 156, 37, 74, 148, 53, 106, 212, 181, 119, 238, 193, 159, 35, 70, 140, 5, 10, 20, 40, 80, 160, 93, 186, 105, 210, 185, 111, 222, 161, 95, 190, 97, 194, 153, 47, 94, 188, 101, 202, 137, 15, 30, 60, 120, 240, 253, 231, 211, 187, 107, 214, 177, 127, 254, 225, 223, 163, 91, 182, 113, 226, 217, 175, 67, 134, 17, 34, 68, 136, 13, 26, 52, 104, 208, 189, 103, 206, 129, 31, 62, 124, 248, 237, 199, 147, 59, 118, 236, 197, 151, 51, 102, 204, 133, 23, 46, 92, 184, 109, 218, 169, 79, 158, 33, 66, 132, 21, 42, 84, 168, 77, 154, 41, 82, 164, 85, 170, 73, 146, 57, 114, 228, 213, 183, 115, 230, 209, 191, 99, 198, 145, 63, 126, 252, 229, 215, 179, 123, 246, 241, 255, 227, 219, 171, 75, 150, 49, 98, 196, 149, 55, 110, 220, 165, 87, 174, 65, 130, 25, 50, 100, 200, 141, 7, 14, 28, 56, 112, 224, 221, 167, 83, 166, 81, 162, 89, 178, 121, 242, 249, 239, 195, 155, 43, 86, 172, 69, 138, 9, 18, 36, 72, 144, 61, 122, 244, 245, 247, 243, 251, 235, 203, 139, 11, 22, 44, 88, 176, 125, 250, 233, 207, 131, 27, 54, 108, 216, 173, 71, 142, 0}
@@ -2,6 +2,7 @@ package qrcode
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -177,3 +178,182 @@ func TestConvertByteToPolynomial(t *testing.T) {
 		}
 	}
 }
+
+func TestPolynomialEvaluate(t *testing.T) {
+	tests := []struct {
+		p   *polynomial
+		x   byte
+		res byte
+	}{
+		{&polynomial{[]byte{1, 3, 2}}, 1, 0},
+		{&polynomial{[]byte{1, 3, 2}}, 2, 0},
+		{&polynomial{[]byte{1, 3, 2}}, 0, 2},
+		{&polynomial{[]byte{5}}, 100, 5},
+		{&polynomial{[]byte{1, 0}}, 100, 100},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("%v(%v)", test.p, test.x)
+		t.Run(name, func(t *testing.T) {
+			if res := test.p.Evaluate(test.x); res != test.res {
+				t.Errorf("Expected %v, got %v", test.res, res)
+			}
+		})
+	}
+}
+
+func TestCorrectErrors(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	codeword := append(append([]byte{}, data...), calculateEDCPoly(data, 26)...)
+
+	tests := []struct {
+		name      string
+		positions []int
+		erasures  []int
+		errors    int
+		err       error
+	}{
+		{"no errors", nil, nil, 0, nil},
+		{"one error in data", []int{3}, nil, 1, nil},
+		{"one error in ec", []int{20}, nil, 1, nil},
+		{"max errors", []int{0, 5, 10, 15, 25}, nil, 5, nil},
+		{"too many errors", []int{0, 3, 5, 10, 15, 20, 25}, nil, 0, ErrTooManyErrors},
+		{"max erasures", []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, 0, nil},
+		{"errors and erasures", []int{1, 2, 3, 4, 20, 21}, []int{1, 2, 3, 4}, 2, nil},
+		{"erasure without error", []int{7}, []int{7, 8}, 0, nil},
+		{"too many errors and erasures", []int{1, 2, 3, 4, 20, 21, 22, 23}, []int{1, 2, 3, 4}, 0, ErrTooManyErrors},
+		{"too many erasures", []int{0}, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0, ErrTooManyErrors},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received := make([]byte, len(codeword))
+			copy(received, codeword)
+			for _, pos := range test.positions {
+				received[pos] ^= 0x5A
+			}
+
+			res, errorsCount, erasuresCount, err := correctErrors(received, 10, 0, test.erasures)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("Expected error %v, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if errorsCount != test.errors {
+				t.Errorf("Expected %v corrected errors, got %v", test.errors, errorsCount)
+			}
+			if len(test.positions) > 0 && erasuresCount != len(test.erasures) {
+				t.Errorf("Expected %v corrected erasures, got %v", len(test.erasures), erasuresCount)
+			}
+			if !bytes.Equal(res, codeword) {
+				t.Errorf("Expected %v, got %v", codeword, res)
+			}
+		})
+	}
+}
+
+func TestCorrectBlock(t *testing.T) {
+	block := errorCorrectionBlocks[5][ErrorCorrectionLevelQuartile][1]
+	data := make([]byte, block.DataCodewords)
+	for idx := range data {
+		data[idx] = byte(idx * 7)
+	}
+	received := append(append([]byte{}, data...), calculateEDCPoly(data, block.TotalCodewords)...)
+	received[0] ^= 1
+	received[10] ^= 2
+	received[30] ^= 3
+
+	res, correction, err := correctBlock(received, block, 0, []int{30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(res, data) {
+		t.Errorf("Expected %v, got %v", data, res)
+	}
+
+	expected := BlockCorrection{Errors: 2, Erasures: 1, ErrorCorrectionCodewords: 18}
+	if correction != expected {
+		t.Errorf("Expected %v, got %v", expected, correction)
+	}
+
+	if margin := correction.Margin(); margin != 6 {
+		t.Errorf("Expected margin 6, got %v", margin)
+	}
+
+	if _, _, err := correctBlock(received[1:], block, 0, nil); err == nil {
+		t.Error("Expected error for invalid block size")
+	}
+}
+
+func TestCorrectBlockMisdecodeProtection(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		level     ErrorCorrectionLevel
+		positions []int
+		erasures  []int
+		err       error
+	}{
+		{"M1 detection only", M1, ErrorCorrectionLevelLow, []int{0}, nil, ErrTooManyErrors},
+		{"M1 no errors", M1, ErrorCorrectionLevelLow, nil, nil, nil},
+		{"M2-L max errors", M2, ErrorCorrectionLevelLow, []int{0}, nil, nil},
+		{"M2-L too many errors", M2, ErrorCorrectionLevelLow, []int{0, 1}, nil, ErrTooManyErrors},
+		{"M4-L max errors", M4, ErrorCorrectionLevelLow, []int{0, 5, 10}, nil, nil},
+		{"M4-L too many errors", M4, ErrorCorrectionLevelLow, []int{0, 5, 10, 15}, nil, ErrTooManyErrors},
+		{"1-L max errors", 1, ErrorCorrectionLevelLow, []int{0, 10}, nil, nil},
+		{"1-L too many errors", 1, ErrorCorrectionLevelLow, []int{0, 10, 20}, nil, ErrTooManyErrors},
+		{"1-L max erasures", 1, ErrorCorrectionLevelLow, []int{0, 1, 2, 3}, []int{0, 1, 2, 3}, nil},
+		{"1-L too many erasures", 1, ErrorCorrectionLevelLow, []int{0, 1, 2, 3, 4}, []int{0, 1, 2, 3, 4}, ErrTooManyErrors},
+		{"1-M max errors", 1, ErrorCorrectionLevelMedium, []int{0, 5, 10, 15}, nil, nil},
+		{"1-M too many errors", 1, ErrorCorrectionLevelMedium, []int{0, 5, 10, 15, 20}, nil, ErrTooManyErrors},
+		{"1-H max errors", 1, ErrorCorrectionLevelHigh, []int{0, 3, 6, 9, 12, 15, 18, 21}, nil, nil},
+		{"1-H too many errors", 1, ErrorCorrectionLevelHigh, []int{0, 3, 6, 9, 12, 15, 18, 21, 24}, nil, ErrTooManyErrors},
+		{"2-L max errors", 2, ErrorCorrectionLevelLow, []int{0, 10, 20, 30}, nil, nil},
+		{"2-L too many errors", 2, ErrorCorrectionLevelLow, []int{0, 10, 20, 30, 40}, nil, ErrTooManyErrors},
+		{"4-L no protection", 4, ErrorCorrectionLevelLow, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var block ecBlock
+			if test.version < 0 {
+				block = microErrorCorrectionBlocks[-test.version][test.level][0]
+			} else {
+				block = errorCorrectionBlocks[test.version][test.level][0]
+			}
+			data := make([]byte, block.DataCodewords)
+			for idx := range data {
+				data[idx] = byte(idx*11 + 1)
+			}
+			received := append(append([]byte{}, data...), calculateEDCPoly(data, block.TotalCodewords)...)
+			for _, pos := range test.positions {
+				received[pos] ^= 0xA5
+			}
+
+			protection := misdecodeProtection(test.version, test.level)
+			res, correction, err := correctBlock(received, block, protection, test.erasures)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("Expected error %v, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(res, data) {
+				t.Errorf("Expected %v, got %v", data, res)
+			}
+			if margin := correction.Margin(); margin != 0 {
+				t.Errorf("Expected margin 0, got %v", margin)
+			}
+		})
+	}
+}
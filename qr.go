@@ -1,7 +1,9 @@
 package qrcode
 
 import (
+	"errors"
 	"fmt"
+	"image/color"
 	"io"
 
 	"qrcode/encode"
@@ -16,6 +18,12 @@ const (
 
 	// DEFAULT_OUTPUT_FORMAT is the default output format for the QR Code image.
 	DEFAULT_OUTPUT_FORMAT = PNG
+
+	// DEFAULT_MODULE_SIZE is the default physical size of one module for the PDF and EPS formats.
+	DEFAULT_MODULE_SIZE = Millimeter / 2
+
+	// DEFAULT_LOGO_SIZE is the default size of the logo area relative to the size of the QR Code.
+	DEFAULT_LOGO_SIZE = 0.2
 )
 
 const (
@@ -26,6 +34,42 @@ const (
 	M4 = -4
 )
 
+const (
+	// rMQR versions (R<height>x<width>)
+	R7x43 = encode.RMQRVersionMin + iota
+	R7x59
+	R7x77
+	R7x99
+	R7x139
+	R9x43
+	R9x59
+	R9x77
+	R9x99
+	R9x139
+	R11x27
+	R11x43
+	R11x59
+	R11x77
+	R11x99
+	R11x139
+	R13x27
+	R13x43
+	R13x59
+	R13x77
+	R13x99
+	R13x139
+	R15x43
+	R15x59
+	R15x77
+	R15x99
+	R15x139
+	R17x43
+	R17x59
+	R17x77
+	R17x99
+	R17x139
+)
+
 // QRCode is a struct that represents a QR Code.
 type QRCode struct {
 	// Content
@@ -35,12 +79,24 @@ type QRCode struct {
 
 	// Data
 	Data [][]Cell
+
+	// ErrorLevel is the error correction level of the QR Code,
+	// it's higher than the level of the options, if the level is boosted.
+	ErrorLevel ErrorCorrectionLevel
+
+	// blocks are the encoded blocks, which are used to rebuild the QR Code (e.g. for the penalty report)
+	blocks []*encode.EncodeBlock
+
+	// logo is the logo of the options or the mandatory one (e.g. the Swiss cross),
+	// which is plotted if PlotOptions.Logo isn't set
+	logo *Logo
 }
 
 // QRCodeOptions is a struct that represents the options for the QR Code.
 type QRCodeOptions struct {
 	// Encoding is the encoding mode.
-	// Default: calculated based on the content (can undestand only numeric, alphanumeric, byte, kanji or utf-8 with ECI)
+	// Default: the content is split into blocks with numeric, alphanumeric, byte, kanji, hanzi or utf-8 with ECI modes,
+	// which give the smallest QR Code.
 	Mode encode.EncodingMode
 
 	// Level is the error correction level.
@@ -54,6 +110,29 @@ type QRCodeOptions struct {
 	// Enable micro QR code
 	// Default: false
 	MicroQR bool
+
+	// Enable rectangular micro QR code (rMQR), it can't be combined with MicroQR.
+	// rMQR codes support only ErrorCorrectionLevelMedium and ErrorCorrectionLevelHigh.
+	// The version with the smallest area is chosen, if the version isn't set.
+	// Default: false
+	RMQR bool
+
+	// Mask is the mask pattern: 0-7 for QR Codes, 0-3 for Micro QR Codes and 0 for rMQR codes with the only mask.
+	// A fixed mask gives the same output across the library versions, see QRCode.PenaltyReport for the penalties.
+	// Default: nil, the mask with the lowest penalty.
+	Mask *int
+
+	// BoostErrorLevel raises the error correction level to the highest one, which still fits the data
+	// into the version (the fixed one or the smallest one for the error correction level).
+	// The final level is QRCode.ErrorLevel.
+	// Default: false
+	BoostErrorLevel bool
+
+	// Logo is the image at the center of the QR Code, it's plotted if PlotOptions.Logo isn't set.
+	// The logo is checked at the creation, with Logo.AutoAdjust the error correction level and the version
+	// are increased until it fits (see QRCode.ErrorLevel for the final level).
+	// Default: nil, no logo.
+	Logo *Logo
 }
 
 // QRCodeOptionsMultiMode is a struct that represents the options for building multi-mode QR Codes.
@@ -69,11 +148,34 @@ type QRCodeOptionsMultiMode struct {
 	// Enable micro QR code
 	// Default: false
 	MicroQR bool
+
+	// Enable rectangular micro QR code (rMQR), it can't be combined with MicroQR.
+	// rMQR codes support only ErrorCorrectionLevelMedium and ErrorCorrectionLevelHigh.
+	// The version with the smallest area is chosen, if the version isn't set.
+	// Default: false
+	RMQR bool
+
+	// Mask is the mask pattern: 0-7 for QR Codes, 0-3 for Micro QR Codes and 0 for rMQR codes with the only mask.
+	// A fixed mask gives the same output across the library versions, see QRCode.PenaltyReport for the penalties.
+	// Default: nil, the mask with the lowest penalty.
+	Mask *int
+
+	// BoostErrorLevel raises the error correction level to the highest one, which still fits the data
+	// into the version (the fixed one or the smallest one for the error correction level).
+	// The final level is QRCode.ErrorLevel.
+	// Default: false
+	BoostErrorLevel bool
+
+	// Logo is the image at the center of the QR Code, it's plotted if PlotOptions.Logo isn't set.
+	// The logo is checked at the creation, with Logo.AutoAdjust the error correction level and the version
+	// are increased until it fits (see QRCode.ErrorLevel for the final level).
+	// Default: nil, no logo.
+	Logo *Logo
 }
 
 type PlotOptions struct {
 	// Scale is the scale for the QR Code image (in pixels).
-	// The image will be len(data) * Scale x len(data) * Scale pixels.
+	// The image will be len(data[0]) * Scale x len(data) * Scale pixels.
 	Scale int
 
 	// Border is the border for the QR Code image (in pixels).
@@ -81,67 +183,213 @@ type PlotOptions struct {
 
 	// OutputFormat is the format of the output image.
 	OutputFormat OutputFormat
+
+	// ModuleSize is the physical size of one module for the PDF and EPS formats,
+	// for example 0.5 * Millimeter. The Scale and the Border keep their meaning:
+	// one pixel is ModuleSize / Scale.
+	// Default: DEFAULT_MODULE_SIZE.
+	ModuleSize Length
+
+	// Foreground is the color of the dark modules.
+	// Default: black.
+	Foreground color.Color
+
+	// Background is the color of the light modules and the border. It can be fully transparent
+	// for PNG, GIF, SVG, PDF and EPS (the background is not drawn), but not for JPEG.
+	// The colors are checked to be readable: the foreground should be darker than the background
+	// with the contrast ratio at least 3:1 (the transparent background is checked as the white one).
+	// Text formats ignore the colors.
+	// Default: white.
+	Background color.Color
+
+	// CellStyles are the colors and the shapes of the dark modules by the cell type,
+	// for example CellTypeAlignmentPattern can be drawn with circles of another color.
+	// Text formats ignore the styles.
+	// Default: square modules of the Foreground color.
+	CellStyles map[CellType]CellStyle
+
+	// Eye is the style of the finder patterns, which are drawn as a unit (the ring and the center)
+	// instead of separate modules.
+	// Default: nil, the finder patterns are drawn with the CellTypeSearchPattern style.
+	Eye *EyeStyle
+
+	// Logo is the image at the center of the QR Code. The covered modules are cleared, so the logo
+	// should be small enough to be recovered by the error correction (ErrLogoTooLarge is returned otherwise,
+	// the QR Code isn't rebuilt, see QRCodeOptions.Logo to adjust it at the creation).
+	// Default: nil, the logo of the creation options (the Swiss cross for the QR codes created by CreateSwissQRBill).
+	Logo *Logo
 }
 
 // CreateMultiMode creates a QR Code with multiple modes.
 func CreateMultiMode(blocks []*encode.EncodeBlock, options *QRCodeOptionsMultiMode) (*QRCode, error) {
-	var err error
 	if options == nil {
 		options = &QRCodeOptionsMultiMode{}
 	}
+
+	return createMultiMode(blocks, options, options.Version != 0)
+}
+
+// createMultiMode creates a QR Code with multiple modes, the version of the options is the smallest one
+// for the blocks, if it isn't fixed (the logo can increase it).
+func createMultiMode(blocks []*encode.EncodeBlock, options *QRCodeOptionsMultiMode, fixedVersion bool) (*QRCode, error) {
+	var err error
 	qrCodeOptions := &QRCodeOptions{
 		ErrorLevel: options.ErrorLevel,
+		Mask:       options.Mask,
+
+		BoostErrorLevel: options.BoostErrorLevel,
 	}
 
 	version := options.Version
 
 	if version == 0 {
-		version, err = calculateMinVersion(blocks, qrCodeOptions.ErrorLevel, options.MicroQR)
+		versions, err := candidateVersions(options.MicroQR, options.RMQR, options.ErrorLevel)
+		if err != nil {
+			return nil, err
+		}
+		version, err = minVersion(blocks, qrCodeOptions.ErrorLevel, versions)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate min version: %w", err)
 		}
 	}
 	qrCodeOptions.Version = version
 
-	buf, err := getBytesData(blocks, options.ErrorLevel, version)
+	if encode.IsRMQRVersion(version) {
+		if _, dataCodewords := rmqrCodewordsCount(version, options.ErrorLevel); dataCodewords == 0 {
+			return nil, ErrRMQRErrorLevel
+		}
+	}
+
+	if options.Version != 0 {
+		ok, err := blocksFit(blocks, version, options.ErrorLevel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check version: %w", err)
+		}
+		if !ok {
+			return nil, newCapacityError(blocks, version, options.ErrorLevel)
+		}
+	}
+
+	errorLevel := options.ErrorLevel
+	if options.BoostErrorLevel {
+		errorLevel, err = boostErrorLevel(blocks, version, errorLevel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to boost error level: %w", err)
+		}
+		qrCodeOptions.ErrorLevel = errorLevel
+	}
+
+	var logo *Logo
+	if options.Logo != nil {
+		logo, err = normalizeLogo(options.Logo)
+		if err != nil {
+			return nil, err
+		}
+		version, errorLevel, err = adjustLogo(blocks, version, errorLevel, logo, fixedVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to place logo: %w", err)
+		}
+		qrCodeOptions.Version, qrCodeOptions.ErrorLevel = version, errorLevel
+		qrCodeOptions.Logo = logo
+	}
+
+	mask := autoMask
+	if options.Mask != nil {
+		mask = *options.Mask
+		if err := validateMask(mask, version); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := getBytesData(blocks, errorLevel, version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bytes data: %w", err)
 	}
 
-	data := generateField(buf, version, options.ErrorLevel)
+	data := generateField(buf, version, errorLevel, mask)
 
 	return &QRCode{
-		options: qrCodeOptions,
-		Data:    data,
+		options:    qrCodeOptions,
+		Data:       data,
+		ErrorLevel: errorLevel,
+		blocks:     blocks,
+		logo:       logo,
 	}, nil
 }
 
 // Create creates a QR Code with the given content and options.
+// If the mode is not specified, the content is split into blocks with different modes
+// to get the smallest QR Code (see encode.Segment).
 func Create(content string, options *QRCodeOptions) (*QRCode, error) {
 	if options == nil {
 		options = &QRCodeOptions{}
 	}
 
-	encodeBlock := &encode.EncodeBlock{
-		Mode: options.Mode,
-		Data: content,
+	multiModeOptions := &QRCodeOptionsMultiMode{
+		ErrorLevel: options.ErrorLevel,
+		Version:    options.Version,
+		MicroQR:    options.MicroQR,
+		RMQR:       options.RMQR,
+		Mask:       options.Mask,
+		Logo:       options.Logo,
+
+		BoostErrorLevel: options.BoostErrorLevel,
+	}
+
+	if options.Mode != 0 {
+		encodeBlock := &encode.EncodeBlock{
+			Mode: options.Mode,
+			Data: content,
+		}
+
+		return createMultiMode([]*encode.EncodeBlock{encodeBlock}, multiModeOptions, options.Version != 0)
 	}
-	if encodeBlock.Mode == 0 {
-		var err error
-		encodeBlock.Mode, err = encode.GetEncodingMode(content)
 
-		// If the content is not valid for any mode, use UTF-8 with ECI
+	var blocks []*encode.EncodeBlock
+	var err error
+	if options.Version == 0 {
+		versions, err := candidateVersions(options.MicroQR, options.RMQR, options.ErrorLevel)
+		if err != nil {
+			return nil, err
+		}
+		multiModeOptions.Version, blocks, err = calculateMinVersionSegmented(content, options.ErrorLevel, versions)
+		if errors.Is(err, ErrContentTooLong) {
+			// the details are calculated for the segmentation of the largest version
+			largest := versions[len(versions)-1]
+			if blocks, segmentErr := encode.Segment(content, largest); segmentErr == nil {
+				err = newCapacityError(blocks, largest, options.ErrorLevel)
+			}
+		}
 		if err != nil {
-			encodeBlock.Mode = encode.EncodingModeECI
-			encodeBlock.SubMode = encode.EncodingModeByte
-			encodeBlock.AssignmentNumber = 26
+			return nil, fmt.Errorf("failed to calculate min version: %w", err)
+		}
+	} else {
+		blocks, err = encode.Segment(content, options.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to segment content: %w", err)
 		}
 	}
 
-	return CreateMultiMode([]*encode.EncodeBlock{encodeBlock}, &QRCodeOptionsMultiMode{
+	return createMultiMode(blocks, multiModeOptions, options.Version != 0)
+}
+
+// CreateBytes creates a QR Code with the binary data in one byte mode block.
+// The bytes are written as is, without the charset conversion (see encode.NewBytesBlock),
+// the Mode option is ignored.
+func CreateBytes(data []byte, options *QRCodeOptions) (*QRCode, error) {
+	if options == nil {
+		options = &QRCodeOptions{}
+	}
+
+	return CreateMultiMode([]*encode.EncodeBlock{encode.NewBytesBlock(data)}, &QRCodeOptionsMultiMode{
 		ErrorLevel: options.ErrorLevel,
 		Version:    options.Version,
 		MicroQR:    options.MicroQR,
+		RMQR:       options.RMQR,
+		Mask:       options.Mask,
+		Logo:       options.Logo,
+
+		BoostErrorLevel: options.BoostErrorLevel,
 	})
 }
 
@@ -159,5 +407,31 @@ func (qr *QRCode) Plot(writer io.Writer, options *PlotOptions) error {
 		options.OutputFormat = DEFAULT_OUTPUT_FORMAT
 	}
 
-	return plot(qr.Data, writer, options.Scale, options.Border, options.OutputFormat)
+	if options.ModuleSize == 0 {
+		options.ModuleSize = DEFAULT_MODULE_SIZE
+	}
+
+	if options.Logo == nil && qr.logo != nil {
+		withLogo := *options
+		withLogo.Logo = qr.logo
+		options = &withLogo
+	}
+
+	data := qr.Data
+	if options.Logo != nil {
+		logo, err := normalizeLogo(options.Logo)
+		if err != nil {
+			return err
+		}
+		withLogo := *options
+		withLogo.Logo = logo
+		options = &withLogo
+
+		data, err = qr.logoField(logo)
+		if err != nil {
+			return fmt.Errorf("failed to place logo: %w", err)
+		}
+	}
+
+	return plot(data, writer, options)
 }
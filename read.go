@@ -0,0 +1,605 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+var (
+	ErrFinderPatternsNotFound = errors.New("cannot find finder patterns")
+	ErrAlignmentNotFound      = errors.New("cannot find alignment pattern")
+)
+
+// minFinderConfirmations is the number of rows, where the finder pattern candidate should be found
+// to be considered as a finder pattern.
+const minFinderConfirmations = 2
+
+// minAlignmentScore is the minimal number of the matched cells of the 5x5 alignment pattern.
+const minAlignmentScore = 22
+
+// point is a point in the image (in pixels) or in the QR code matrix (in modules).
+type point struct {
+	X, Y float64
+}
+
+func (p point) distance(other point) float64 {
+	return math.Hypot(p.X-other.X, p.Y-other.Y)
+}
+
+// bitMatrix is a binarized image: true values are dark pixels.
+type bitMatrix struct {
+	width, height int
+	bits          []bool
+}
+
+// Get returns true if the pixel is dark. The pixels outside the image are light.
+func (m *bitMatrix) Get(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return false
+	}
+	return m.bits[y*m.width+x]
+}
+
+// otsuThreshold calculates the threshold, which separates the histogram into two classes
+// with the maximal between-class variance.
+func otsuThreshold(histogram [256]int, total int) uint8 {
+	sum := 0
+	for value, count := range histogram {
+		sum += value * count
+	}
+
+	sumBackground, weightBackground := 0, 0
+	maxVariance := -1.0
+	var threshold uint8
+	for value, count := range histogram {
+		weightBackground += count
+		if weightBackground == 0 {
+			continue
+		}
+		weightForeground := total - weightBackground
+		if weightForeground == 0 {
+			break
+		}
+
+		sumBackground += value * count
+		meanBackground := float64(sumBackground) / float64(weightBackground)
+		meanForeground := float64(sum-sumBackground) / float64(weightForeground)
+		variance := float64(weightBackground) * float64(weightForeground) * (meanBackground - meanForeground) * (meanBackground - meanForeground)
+		if variance > maxVariance {
+			maxVariance = variance
+			threshold = uint8(value)
+		}
+	}
+
+	return threshold
+}
+
+// binarize converts the image to the bit matrix using the global Otsu threshold.
+// The global threshold works well for scans and evenly lit photos.
+func binarize(img image.Image) *bitMatrix {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	gray := make([]uint8, width*height)
+	var histogram [256]int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y
+			gray[y*width+x] = value
+			histogram[value]++
+		}
+	}
+
+	threshold := otsuThreshold(histogram, width*height)
+
+	matrix := &bitMatrix{width: width, height: height, bits: make([]bool, width*height)}
+	for idx, value := range gray {
+		matrix.bits[idx] = value <= threshold
+	}
+
+	return matrix
+}
+
+// isFinderRatio checks if the run lengths of dark-light-dark-light-dark have the finder pattern ratio 1:1:3:1:1.
+func isFinderRatio(counts [5]int) bool {
+	total := 0
+	for _, count := range counts {
+		if count == 0 {
+			return false
+		}
+		total += count
+	}
+	if total < 7 {
+		return false
+	}
+
+	moduleSize := float64(total) / 7
+	maxVariance := moduleSize / 2
+	return math.Abs(moduleSize-float64(counts[0])) < maxVariance &&
+		math.Abs(moduleSize-float64(counts[1])) < maxVariance &&
+		math.Abs(3*moduleSize-float64(counts[2])) < 3*maxVariance &&
+		math.Abs(moduleSize-float64(counts[3])) < maxVariance &&
+		math.Abs(moduleSize-float64(counts[4])) < maxVariance
+}
+
+// crossCheck counts the finder pattern runs from the center in the both directions along the line (dx, dy)
+// and returns the refined center coordinate along this line.
+func crossCheck(matrix *bitMatrix, centerX, centerY, dx, dy int, maxCount, originalTotal int) (float64, bool) {
+	var counts [5]int
+	get := func(i int) bool {
+		return matrix.Get(centerX+i*dx, centerY+i*dy)
+	}
+	inside := func(i int) bool {
+		x, y := centerX+i*dx, centerY+i*dy
+		return x >= 0 && y >= 0 && x < matrix.width && y < matrix.height
+	}
+
+	// backward direction: center, light, dark
+	i := 0
+	for inside(i) && get(i) {
+		counts[2]++
+		i--
+	}
+	if !inside(i) {
+		return 0, false
+	}
+	for inside(i) && !get(i) && counts[1] <= maxCount {
+		counts[1]++
+		i--
+	}
+	if !inside(i) || counts[1] > maxCount {
+		return 0, false
+	}
+	for inside(i) && get(i) && counts[0] <= maxCount {
+		counts[0]++
+		i--
+	}
+	if counts[0] > maxCount {
+		return 0, false
+	}
+
+	// forward direction: center, light, dark
+	i = 1
+	for inside(i) && get(i) {
+		counts[2]++
+		i++
+	}
+	if !inside(i) {
+		return 0, false
+	}
+	for inside(i) && !get(i) && counts[3] < maxCount {
+		counts[3]++
+		i++
+	}
+	if !inside(i) || counts[3] >= maxCount {
+		return 0, false
+	}
+	for inside(i) && get(i) && counts[4] < maxCount {
+		counts[4]++
+		i++
+	}
+	if counts[4] >= maxCount {
+		return 0, false
+	}
+
+	total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+	if 5*abs(total-originalTotal) >= 2*originalTotal || !isFinderRatio(counts) {
+		return 0, false
+	}
+
+	// i points to the first pixel after the pattern
+	return float64(i-counts[4]-counts[3]) - float64(counts[2])/2, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// finderCandidate is a possible finder pattern center.
+type finderCandidate struct {
+	center        point
+	moduleSize    float64
+	confirmations int
+}
+
+// addFinderCandidate adds the found center to the list of the candidates
+// or merges it with the close candidate of the similar size.
+func addFinderCandidate(candidates []finderCandidate, center point, moduleSize float64) []finderCandidate {
+	for idx, candidate := range candidates {
+		if candidate.center.distance(center) <= candidate.moduleSize*2 &&
+			math.Abs(candidate.moduleSize-moduleSize) <= math.Max(1, candidate.moduleSize/2) {
+			n := float64(candidate.confirmations)
+			candidates[idx] = finderCandidate{
+				center: point{
+					X: (candidate.center.X*n + center.X) / (n + 1),
+					Y: (candidate.center.Y*n + center.Y) / (n + 1),
+				},
+				moduleSize:    (candidate.moduleSize*n + moduleSize) / (n + 1),
+				confirmations: candidate.confirmations + 1,
+			}
+			return candidates
+		}
+	}
+
+	return append(candidates, finderCandidate{center: center, moduleSize: moduleSize, confirmations: 1})
+}
+
+// handleFinderRun checks the found 1:1:3:1:1 run in the row with the vertical and horizontal cross checks.
+func handleFinderRun(matrix *bitMatrix, candidates []finderCandidate, counts [5]int, endX, y int) []finderCandidate {
+	total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+	centerX := int(float64(endX-counts[4]-counts[3]) - float64(counts[2])/2)
+
+	offsetY, ok := crossCheck(matrix, centerX, y, 0, 1, counts[2], total)
+	if !ok {
+		return candidates
+	}
+	centerY := y + int(math.Floor(offsetY))
+
+	offsetX, ok := crossCheck(matrix, centerX, centerY, 1, 0, counts[2], total)
+	if !ok {
+		return candidates
+	}
+
+	center := point{X: float64(centerX) + offsetX, Y: float64(y) + offsetY}
+	return addFinderCandidate(candidates, center, float64(total)/7)
+}
+
+// findFinderCandidates scans the rows of the image for the 1:1:3:1:1 runs of the finder patterns.
+func findFinderCandidates(matrix *bitMatrix) []finderCandidate {
+	var candidates []finderCandidate
+
+	for y := 0; y < matrix.height; y++ {
+		var counts [5]int
+		state := 0
+		for x := 0; x < matrix.width; x++ {
+			if matrix.Get(x, y) {
+				// odd states count light pixels
+				if state%2 == 1 {
+					state++
+				}
+				counts[state]++
+				continue
+			}
+
+			if state%2 == 1 {
+				counts[state]++
+				continue
+			}
+
+			if state < 4 {
+				state++
+				counts[state]++
+				continue
+			}
+
+			if isFinderRatio(counts) {
+				candidates = handleFinderRun(matrix, candidates, counts, x, y)
+			}
+
+			// keep the last dark-light pair as the beginning of the next pattern
+			counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+			state = 3
+		}
+
+		if state == 4 && isFinderRatio(counts) {
+			candidates = handleFinderRun(matrix, candidates, counts, matrix.width, y)
+		}
+	}
+
+	return candidates
+}
+
+// selectFinderPatterns selects three candidates, which form the best right isosceles triangle,
+// and returns them in the order: top-left, top-right, bottom-left.
+func selectFinderPatterns(candidates []finderCandidate) ([3]finderCandidate, error) {
+	var result [3]finderCandidate
+
+	confirmed := make([]finderCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.confirmations >= minFinderConfirmations {
+			confirmed = append(confirmed, candidate)
+		}
+	}
+	if len(confirmed) < 3 {
+		return result, ErrFinderPatternsNotFound
+	}
+
+	sort.Slice(confirmed, func(i, j int) bool {
+		return confirmed[i].confirmations > confirmed[j].confirmations
+	})
+	if len(confirmed) > 10 {
+		confirmed = confirmed[:10]
+	}
+
+	bestScore := math.Inf(1)
+	for i := 0; i < len(confirmed); i++ {
+		for j := i + 1; j < len(confirmed); j++ {
+			for k := j + 1; k < len(confirmed); k++ {
+				triple := [3]finderCandidate{confirmed[i], confirmed[j], confirmed[k]}
+
+				minSize := math.Min(triple[0].moduleSize, math.Min(triple[1].moduleSize, triple[2].moduleSize))
+				maxSize := math.Max(triple[0].moduleSize, math.Max(triple[1].moduleSize, triple[2].moduleSize))
+				if maxSize > minSize*1.5 {
+					continue
+				}
+
+				ordered, score := orderFinderPatterns(triple)
+				if score < bestScore {
+					bestScore = score
+					result = ordered
+				}
+			}
+		}
+	}
+
+	if math.IsInf(bestScore, 1) || bestScore > 0.5 {
+		return result, ErrFinderPatternsNotFound
+	}
+
+	return result, nil
+}
+
+// orderFinderPatterns orders the finder patterns as top-left, top-right, bottom-left
+// and returns how far the triangle is from the right isosceles one (0 is the perfect triangle).
+func orderFinderPatterns(patterns [3]finderCandidate) ([3]finderCandidate, float64) {
+	// top-left is the vertex opposite to the longest side
+	d01 := patterns[0].center.distance(patterns[1].center)
+	d12 := patterns[1].center.distance(patterns[2].center)
+	d02 := patterns[0].center.distance(patterns[2].center)
+
+	var topLeft, a, b finderCandidate
+	var hypotenuse, side1, side2 float64
+	switch {
+	case d12 >= d01 && d12 >= d02:
+		topLeft, a, b = patterns[0], patterns[1], patterns[2]
+		hypotenuse, side1, side2 = d12, d01, d02
+	case d02 >= d01 && d02 >= d12:
+		topLeft, a, b = patterns[1], patterns[0], patterns[2]
+		hypotenuse, side1, side2 = d02, d01, d12
+	default:
+		topLeft, a, b = patterns[2], patterns[0], patterns[1]
+		hypotenuse, side1, side2 = d01, d02, d12
+	}
+
+	if hypotenuse == 0 {
+		return patterns, math.Inf(1)
+	}
+
+	// in the image coordinates (y down) the top-right pattern is clockwise from the bottom-left one
+	cross := (a.center.X-topLeft.center.X)*(b.center.Y-topLeft.center.Y) - (a.center.Y-topLeft.center.Y)*(b.center.X-topLeft.center.X)
+	topRight, bottomLeft := a, b
+	if cross < 0 {
+		topRight, bottomLeft = b, a
+	}
+
+	score := math.Abs(side1-side2)/hypotenuse + math.Abs(hypotenuse*hypotenuse-side1*side1-side2*side2)/(hypotenuse*hypotenuse)
+	return [3]finderCandidate{topLeft, topRight, bottomLeft}, score
+}
+
+// perspectiveTransform maps the points of the QR code matrix (in modules) to the image (in pixels).
+type perspectiveTransform struct {
+	a11, a12, a13, a21, a22, a23, a31, a32 float64
+}
+
+// newPerspectiveTransform calculates the transform, which maps four source points to four destination points.
+func newPerspectiveTransform(src, dst [4]point) (*perspectiveTransform, error) {
+	// x' = (a11*x + a12*y + a13) / (a31*x + a32*y + 1)
+	// y' = (a21*x + a22*y + a23) / (a31*x + a32*y + 1)
+	var system [8][9]float64
+	for i := 0; i < 4; i++ {
+		x, y := src[i].X, src[i].Y
+		u, v := dst[i].X, dst[i].Y
+		system[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		system[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(system[row][col]) > math.Abs(system[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(system[pivot][col]) < 1e-12 {
+			return nil, errors.New("degenerate perspective transform")
+		}
+		system[col], system[pivot] = system[pivot], system[col]
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			factor := system[row][col] / system[col][col]
+			for k := col; k < 9; k++ {
+				system[row][k] -= factor * system[col][k]
+			}
+		}
+	}
+
+	var solution [8]float64
+	for i := range solution {
+		solution[i] = system[i][8] / system[i][i]
+	}
+
+	return &perspectiveTransform{
+		a11: solution[0], a12: solution[1], a13: solution[2],
+		a21: solution[3], a22: solution[4], a23: solution[5],
+		a31: solution[6], a32: solution[7],
+	}, nil
+}
+
+// Apply maps the point of the QR code matrix to the image.
+func (t *perspectiveTransform) Apply(p point) point {
+	denominator := t.a31*p.X + t.a32*p.Y + 1
+	return point{
+		X: (t.a11*p.X + t.a12*p.Y + t.a13) / denominator,
+		Y: (t.a21*p.X + t.a22*p.Y + t.a23) / denominator,
+	}
+}
+
+// estimateDimension estimates the size of the QR code matrix (in modules) by the finder patterns.
+func estimateDimension(patterns [3]finderCandidate) int {
+	moduleSize := (patterns[0].moduleSize + patterns[1].moduleSize + patterns[2].moduleSize) / 3
+	width := patterns[0].center.distance(patterns[1].center) / moduleSize
+	height := patterns[0].center.distance(patterns[2].center) / moduleSize
+	dimension := int(math.Round((width+height)/2)) + 7
+
+	// the size of QR code is 17 + 4*version, round to the closest one
+	version := int(math.Round(float64(dimension-17) / 4))
+	version = max(1, min(40, version))
+
+	return getSize(version)
+}
+
+// findAlignmentPattern searches the bottom-right alignment pattern around the position predicted by the finder patterns.
+// The 5x5 alignment pattern is matched with the cells sampled by the affine transform of the finder patterns.
+func findAlignmentPattern(matrix *bitMatrix, patterns [3]finderCandidate, dimension int) (point, error) {
+	topLeft, topRight, bottomLeft := patterns[0].center, patterns[1].center, patterns[2].center
+
+	// vectors of one module along the rows and the columns
+	modules := float64(dimension - 7)
+	right := point{X: (topRight.X - topLeft.X) / modules, Y: (topRight.Y - topLeft.Y) / modules}
+	down := point{X: (bottomLeft.X - topLeft.X) / modules, Y: (bottomLeft.Y - topLeft.Y) / modules}
+
+	// alignment pattern center is 3 modules closer to the top-left than the bottom-right finder pattern center would be
+	shift := float64(dimension) - 10
+	predicted := point{
+		X: topLeft.X + (right.X+down.X)*shift,
+		Y: topLeft.Y + (right.Y+down.Y)*shift,
+	}
+
+	moduleSize := (patterns[0].moduleSize + patterns[1].moduleSize + patterns[2].moduleSize) / 3
+	step := math.Max(1, moduleSize/3)
+
+	bestScore, bestDistance := 0, math.Inf(1)
+	var best point
+	for _, allowance := range []float64{4, 8, 16} {
+		radius := allowance * moduleSize
+
+		for dy := -radius; dy <= radius; dy += step {
+			for dx := -radius; dx <= radius; dx += step {
+				candidate := point{X: predicted.X + dx, Y: predicted.Y + dy}
+				score := 0
+				for i := 0; i < 5; i++ {
+					for j := 0; j < 5; j++ {
+						x := candidate.X + right.X*float64(j-2) + down.X*float64(i-2)
+						y := candidate.Y + right.Y*float64(j-2) + down.Y*float64(i-2)
+						if matrix.Get(int(x), int(y)) == alignmentPattern[i][j] {
+							score++
+						}
+					}
+				}
+
+				distance := math.Hypot(dx, dy)
+				if score > bestScore || (score == bestScore && distance < bestDistance) {
+					bestScore, best, bestDistance = score, candidate, distance
+				}
+			}
+		}
+
+		if bestScore == 25 {
+			return best, nil
+		}
+	}
+
+	// the affine estimation of the module vectors is not exact for the strong perspective distortion
+	if bestScore >= minAlignmentScore {
+		return best, nil
+	}
+
+	return point{}, ErrAlignmentNotFound
+}
+
+// sampleGrid reads the module values of the QR code matrix from the image with the given transform.
+func sampleGrid(matrix *bitMatrix, transform *perspectiveTransform, dimension int) [][]Cell {
+	data := make([][]Cell, dimension)
+	for row := range data {
+		data[row] = make([]Cell, dimension)
+		for col := range data[row] {
+			p := transform.Apply(point{X: float64(col) + 0.5, Y: float64(row) + 0.5})
+			data[row][col].Value = matrix.Get(int(math.Floor(p.X)), int(math.Floor(p.Y)))
+		}
+	}
+	return data
+}
+
+// detectGrid builds the transform from the QR code matrix with the given dimension to the image.
+// The finder patterns define three corners, the fourth one is the bottom-right alignment pattern (for versions 2-40)
+// or the completion of the parallelogram.
+func detectGrid(matrix *bitMatrix, patterns [3]finderCandidate, dimension int) (*perspectiveTransform, error) {
+	topLeft, topRight, bottomLeft := patterns[0].center, patterns[1].center, patterns[2].center
+	size := float64(dimension)
+
+	src := [4]point{{3.5, 3.5}, {size - 3.5, 3.5}, {3.5, size - 3.5}, {size - 3.5, size - 3.5}}
+	dst := [4]point{topLeft, topRight, bottomLeft, {
+		X: topRight.X + bottomLeft.X - topLeft.X,
+		Y: topRight.Y + bottomLeft.Y - topLeft.Y,
+	}}
+
+	if dimension > getSize(1) {
+		if alignment, err := findAlignmentPattern(matrix, patterns, dimension); err == nil {
+			src[3] = point{size - 6.5, size - 6.5}
+			dst[3] = alignment
+		}
+	}
+
+	return newPerspectiveTransform(src, dst)
+}
+
+// ReadImage finds the QR Code in the image and decodes it.
+// The image is binarized with a global threshold, three finder patterns define the position and the orientation
+// of the code, the bottom-right alignment pattern corrects the perspective distortion.
+// Micro QR codes (with a single finder pattern) are not supported.
+func ReadImage(img image.Image) (*DecodeResult, error) {
+	matrix := binarize(img)
+
+	patterns, err := selectFinderPatterns(findFinderCandidates(matrix))
+	if err != nil {
+		return nil, err
+	}
+
+	estimated := estimateDimension(patterns)
+	dimensions := []int{estimated, estimated + 4, estimated - 4}
+
+	var lastErr error
+	for _, dimension := range dimensions {
+		version, err := getVersionBySize(dimension)
+		if err != nil || version < 0 {
+			continue
+		}
+
+		transform, err := detectGrid(matrix, patterns, dimension)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		data := sampleGrid(matrix, transform, dimension)
+
+		// the version information is more reliable than the estimation by the finder patterns
+		if decoded := decodeVersion(data, version); decoded != version {
+			dimension = getSize(decoded)
+			if transform, err = detectGrid(matrix, patterns, dimension); err != nil {
+				lastErr = err
+				continue
+			}
+			data = sampleGrid(matrix, transform, dimension)
+		}
+
+		result, err := Decode(data)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("failed to decode QR code: %w", lastErr)
+}
@@ -0,0 +1,210 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// plotImage plots the QR code to the image with the given format and decodes it back.
+func plotImage(t *testing.T, qr *QRCode, scale, border int, format OutputFormat) image.Image {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := qr.Plot(&buf, &PlotOptions{Scale: scale, Border: border, OutputFormat: format}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, _, err := image.Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return img
+}
+
+// transformImage renders the source image with the inverse transform of the destination pixels to the source ones.
+func transformImage(src image.Image, width, height int, inverse func(x, y float64) (float64, float64)) image.Image {
+	dst := image.NewGray(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := inverse(float64(x)+0.5, float64(y)+0.5)
+			p := image.Pt(int(math.Floor(sx)), int(math.Floor(sy)))
+			if !p.In(bounds) {
+				dst.SetGray(x, y, color.Gray{Y: 255})
+				continue
+			}
+			dst.Set(x, y, src.At(p.X, p.Y))
+		}
+	}
+	return dst
+}
+
+func TestOtsuThreshold(t *testing.T) {
+	var histogram [256]int
+	histogram[20] = 100
+	histogram[30] = 50
+	histogram[200] = 80
+	histogram[230] = 120
+
+	threshold := otsuThreshold(histogram, 350)
+	if threshold < 30 || threshold >= 200 {
+		t.Errorf("Expected threshold between 30 and 200, got %v", threshold)
+	}
+}
+
+func TestIsFinderRatio(t *testing.T) {
+	tests := []struct {
+		counts [5]int
+		result bool
+	}{
+		{[5]int{1, 1, 3, 1, 1}, true},
+		{[5]int{4, 4, 12, 4, 4}, true},
+		{[5]int{4, 5, 11, 3, 4}, true},
+		{[5]int{4, 4, 4, 4, 4}, false},
+		{[5]int{4, 0, 12, 4, 4}, false},
+		{[5]int{1, 1, 1, 1, 1}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.counts), func(t *testing.T) {
+			if result := isFinderRatio(test.counts); result != test.result {
+				t.Errorf("Expected %v, got %v", test.result, result)
+			}
+		})
+	}
+}
+
+func TestPerspectiveTransform(t *testing.T) {
+	src := [4]point{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+	dst := [4]point{{5, 5}, {30, 8}, {7, 40}, {35, 45}}
+
+	transform, err := newPerspectiveTransform(src, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for idx := range src {
+		p := transform.Apply(src[idx])
+		if p.distance(dst[idx]) > 1e-9 {
+			t.Errorf("Expected %v, got %v", dst[idx], p)
+		}
+	}
+
+	if _, err := newPerspectiveTransform(src, [4]point{}); err == nil {
+		t.Error("Expected error for degenerate transform")
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	tests := []struct {
+		content string
+		version int
+		level   ErrorCorrectionLevel
+		scale   int
+		border  int
+		format  OutputFormat
+	}{
+		{"HELLO WORLD", 1, ErrorCorrectionLevelMedium, 4, 16, PNG},
+		{"HELLO WORLD", 1, ErrorCorrectionLevelMedium, 1, 4, PNG},
+		{"HELLO WORLD 1234567890", 3, ErrorCorrectionLevelHigh, 5, 0, PNG},
+		{"ORDER 12345678901234 café", 7, ErrorCorrectionLevelQuartile, 3, 12, GIF},
+		{GenerateByteContent(500), 20, ErrorCorrectionLevelLow, 3, 12, JPEG},
+		{GenerateNumericContent(5000), 40, ErrorCorrectionLevelLow, 2, 8, PNG},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("version %v, scale %v, border %v, format %v", test.version, test.scale, test.border, test.format)
+		t.Run(name, func(t *testing.T) {
+			qr, err := Create(test.content, &QRCodeOptions{ErrorLevel: test.level, Version: test.version})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := ReadImage(plotImage(t, qr, test.scale, test.border, test.format))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Content != test.content {
+				t.Errorf("Expected %v, got %v", test.content, result.Content)
+			}
+			if result.Version != test.version {
+				t.Errorf("Expected version %v, got %v", test.version, result.Version)
+			}
+		})
+	}
+}
+
+func TestReadImageTransformed(t *testing.T) {
+	content := "https://example.com/transformed"
+	qr, err := Create(content, &QRCodeOptions{ErrorLevel: ErrorCorrectionLevelMedium, Version: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img := plotImage(t, qr, 6, 36, PNG)
+	size := float64(img.Bounds().Dx())
+	center := size / 2
+
+	rotate := func(angle float64) func(x, y float64) (float64, float64) {
+		sin, cos := math.Sincos(angle)
+		return func(x, y float64) (float64, float64) {
+			x, y = x-center, y-center
+			return x*cos - y*sin + center, x*sin + y*cos + center
+		}
+	}
+
+	perspective, err := newPerspectiveTransform(
+		[4]point{{0, 0}, {size, 0}, {0, size}, {size, size}},
+		[4]point{{0, 0}, {size, 30}, {20, size}, {size - 40, size - 10}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		inverse func(x, y float64) (float64, float64)
+	}{
+		{"rotated 90", rotate(math.Pi / 2)},
+		{"rotated 180", rotate(math.Pi)},
+		{"rotated 25", rotate(25 * math.Pi / 180)},
+		{"rotated -70", rotate(-70 * math.Pi / 180)},
+		{"perspective", func(x, y float64) (float64, float64) {
+			p := perspective.Apply(point{X: x, Y: y})
+			return p.X, p.Y
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transformed := transformImage(img, int(size), int(size), test.inverse)
+
+			result, err := ReadImage(transformed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Content != content {
+				t.Errorf("Expected %v, got %v", content, result.Content)
+			}
+		})
+	}
+}
+
+func TestReadImageErrors(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for idx := range img.Pix {
+		img.Pix[idx] = 255
+	}
+
+	if _, err := ReadImage(img); !errors.Is(err, ErrFinderPatternsNotFound) {
+		t.Errorf("Expected %v, got %v", ErrFinderPatternsNotFound, err)
+	}
+}
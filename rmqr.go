@@ -0,0 +1,316 @@
+package qrcode
+
+import (
+	"errors"
+)
+
+var (
+	ErrMicroQRAndRMQR = errors.New("micro QR and rMQR options are mutually exclusive")
+	ErrRMQRErrorLevel = errors.New("rMQR codes support only M and H error correction levels")
+)
+
+// rmqrMask is the only mask pattern of rMQR codes, it's the same as the mask pattern 4 of QR Codes.
+const rmqrMask = 4
+
+const (
+	// rmqrFormatGenerator is the generator polynomial of the BCH (18, 6) code of the format information.
+	rmqrFormatGenerator = 0b1111100100101
+
+	// Masks of the format information next to the finder pattern and the finder sub-pattern.
+	rmqrFinderFormatMask    = 0b011111101010110010
+	rmqrSubFinderFormatMask = 0b100000101001111011
+)
+
+// rmqrSizes are the heights and the widths of the rMQR versions.
+// Structure: [version - R7x43][height, width]
+var rmqrSizes = [32][2]int{
+	{7, 43}, {7, 59}, {7, 77}, {7, 99}, {7, 139},
+	{9, 43}, {9, 59}, {9, 77}, {9, 99}, {9, 139},
+	{11, 27}, {11, 43}, {11, 59}, {11, 77}, {11, 99}, {11, 139},
+	{13, 27}, {13, 43}, {13, 59}, {13, 77}, {13, 99}, {13, 139},
+	{15, 43}, {15, 59}, {15, 77}, {15, 99}, {15, 139},
+	{17, 43}, {17, 59}, {17, 77}, {17, 99}, {17, 139},
+}
+
+// rmqrAlignmentColumns are the columns of the centers of the alignment patterns by the width of the rMQR code.
+var rmqrAlignmentColumns = map[int][]int{
+	27:  {},
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+// Error correction blocks for each rMQR version and error correction level (only M and H are supported)
+// Structure: [version - R7x43][error correction level][block]
+var rmqrErrorCorrectionBlocks = [32][4][]ecBlock{
+	{{}, {{1, 13, 6, 0}}, {}, {{1, 13, 3, 0}}},                                   // R7x43
+	{{}, {{1, 21, 12, 0}}, {}, {{1, 21, 7, 0}}},                                  // R7x59
+	{{}, {{1, 32, 20, 0}}, {}, {{1, 32, 10, 0}}},                                 // R7x77
+	{{}, {{1, 44, 28, 0}}, {}, {{1, 44, 14, 0}}},                                 // R7x99
+	{{}, {{1, 68, 44, 0}}, {}, {{2, 34, 12, 0}}},                                 // R7x139
+	{{}, {{1, 21, 12, 0}}, {}, {{1, 21, 7, 0}}},                                  // R9x43
+	{{}, {{1, 33, 21, 0}}, {}, {{1, 33, 11, 0}}},                                 // R9x59
+	{{}, {{1, 49, 31, 0}}, {}, {{1, 24, 8, 0}, {1, 25, 9, 0}}},                   // R9x77
+	{{}, {{1, 66, 42, 0}}, {}, {{2, 33, 11, 0}}},                                 // R9x99
+	{{}, {{1, 49, 31, 0}, {1, 50, 32, 0}}, {}, {{3, 33, 11, 0}}},                 // R9x139
+	{{}, {{1, 15, 7, 0}}, {}, {{1, 15, 5, 0}}},                                   // R11x27
+	{{}, {{1, 31, 19, 0}}, {}, {{1, 31, 11, 0}}},                                 // R11x43
+	{{}, {{1, 47, 31, 0}}, {}, {{1, 23, 7, 0}, {1, 24, 8, 0}}},                   // R11x59
+	{{}, {{1, 67, 43, 0}}, {}, {{1, 33, 11, 0}, {1, 34, 12, 0}}},                 // R11x77
+	{{}, {{1, 44, 28, 0}, {1, 45, 29, 0}}, {}, {{1, 44, 14, 0}, {1, 45, 15, 0}}}, // R11x99
+	{{}, {{2, 66, 42, 0}}, {}, {{3, 44, 14, 0}}},                                 // R11x139
+	{{}, {{1, 21, 12, 0}}, {}, {{1, 21, 7, 0}}},                                  // R13x27
+	{{}, {{1, 41, 27, 0}}, {}, {{1, 41, 13, 0}}},                                 // R13x43
+	{{}, {{1, 60, 38, 0}}, {}, {{2, 30, 10, 0}}},                                 // R13x59
+	{{}, {{1, 42, 26, 0}, {1, 43, 27, 0}}, {}, {{1, 42, 14, 0}, {1, 43, 15, 0}}}, // R13x77
+	{{}, {{1, 56, 36, 0}, {1, 57, 37, 0}}, {}, {{1, 37, 11, 0}, {2, 38, 12, 0}}}, // R13x99
+	{{}, {{2, 55, 35, 0}, {1, 56, 36, 0}}, {}, {{2, 41, 13, 0}, {2, 42, 14, 0}}}, // R13x139
+	{{}, {{1, 51, 33, 0}}, {}, {{1, 25, 7, 0}, {1, 26, 8, 0}}},                   // R15x43
+	{{}, {{1, 74, 48, 0}}, {}, {{2, 37, 13, 0}}},                                 // R15x59
+	{{}, {{1, 51, 33, 0}, {1, 52, 34, 0}}, {}, {{2, 34, 10, 0}, {1, 35, 11, 0}}}, // R15x77
+	{{}, {{2, 68, 44, 0}}, {}, {{4, 34, 12, 0}}},                                 // R15x99
+	{{}, {{2, 66, 42, 0}, {1, 67, 43, 0}}, {}, {{1, 39, 13, 0}, {4, 40, 14, 0}}}, // R15x139
+	{{}, {{1, 61, 39, 0}}, {}, {{1, 30, 10, 0}, {1, 31, 11, 0}}},                 // R17x43
+	{{}, {{2, 44, 28, 0}}, {}, {{2, 44, 14, 0}}},                                 // R17x59
+	{{}, {{2, 61, 39, 0}}, {}, {{1, 40, 12, 0}, {2, 41, 13, 0}}},                 // R17x77
+	{{}, {{2, 53, 33, 0}, {1, 54, 34, 0}}, {}, {{4, 40, 14, 0}}},                 // R17x99
+	{{}, {{4, 58, 38, 0}}, {}, {{2, 38, 12, 0}, {4, 39, 13, 0}}},                 // R17x139
+}
+
+// getRMQRSize returns the width and the height of the rMQR code matrix for the given version.
+func getRMQRSize(version int) (int, int) {
+	size := rmqrSizes[version-R7x43]
+	return size[1], size[0]
+}
+
+// getRMQRVersionBySize returns the rMQR version of the matrix with the given width and height.
+func getRMQRVersionBySize(width, height int) (int, error) {
+	for idx, size := range rmqrSizes {
+		if size[0] == height && size[1] == width {
+			return R7x43 + idx, nil
+		}
+	}
+	return 0, ErrInvalidSize
+}
+
+// rmqrCodewordsCount returns the total number of codewords and the number of data codewords
+// of the rMQR version and error correction level. Both are zero for the unsupported levels.
+func rmqrCodewordsCount(version int, errorLevel ErrorCorrectionLevel) (int, int) {
+	total, data := 0, 0
+	for _, block := range rmqrErrorCorrectionBlocks[version-R7x43][errorLevel] {
+		total += block.Blocks * block.TotalCodewords
+		data += block.Blocks * block.DataCodewords
+	}
+	return total, data
+}
+
+// candidateVersions returns the versions of the QR Code family in the order of increasing size:
+// 1 to 40, M1 to M4 for Micro QR Codes or rMQR versions by the area (the smaller height goes first).
+func candidateVersions(microQR, rmqr bool, ecl ErrorCorrectionLevel) ([]int, error) {
+	var versions []int
+	switch {
+	case microQR && rmqr:
+		return nil, ErrMicroQRAndRMQR
+	case rmqr && len(rmqrErrorCorrectionBlocks[0][ecl]) == 0:
+		return nil, ErrRMQRErrorLevel
+	case microQR:
+		for version := M1; version >= M4; version-- {
+			versions = append(versions, version)
+		}
+	case rmqr:
+		for version := R7x43; version <= R17x139; version++ {
+			versions = append(versions, version)
+		}
+		area := func(version int) int {
+			width, height := getRMQRSize(version)
+			return width * height
+		}
+		// insertion sort keeps the order of the versions with the same area
+		for i := 1; i < len(versions); i++ {
+			for j := i; j > 0 && area(versions[j]) < area(versions[j-1]); j-- {
+				versions[j], versions[j-1] = versions[j-1], versions[j]
+			}
+		}
+	default:
+		for version := 1; version <= 40; version++ {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// rmqrFormatInfo returns the format information next to the finder pattern and the finder sub-pattern:
+// the error correction level bit (0 for M, 1 for H) and the 5-bit version indicator with 12 BCH bits.
+func rmqrFormatInfo(version int, errorLevel ErrorCorrectionLevel) (int, int) {
+	data := version - R7x43
+	if errorLevel == ErrorCorrectionLevelHigh {
+		data |= 1 << 5
+	}
+
+	remainder := data << 12
+	for bit := 17; bit >= 12; bit-- {
+		if remainder&(1<<bit) != 0 {
+			remainder ^= rmqrFormatGenerator << (bit - 12)
+		}
+	}
+
+	value := data<<12 | remainder
+	return value ^ rmqrFinderFormatMask, value ^ rmqrSubFinderFormatMask
+}
+
+// rmqrFormatPositions returns the positions (x, y) of the bits of the format information
+// next to the finder pattern and the finder sub-pattern.
+func rmqrFormatPositions(width, height int) ([18][2]int, [18][2]int) {
+	var finder, subFinder [18][2]int
+	for n := 0; n < 18; n++ {
+		finder[n] = [2]int{8 + n/5, 1 + n%5}
+		if n < 15 {
+			subFinder[n] = [2]int{width - 8 + n/5, height - 6 + n%5}
+		} else {
+			subFinder[n] = [2]int{width - 5 + n - 15, height - 6}
+		}
+	}
+	return finder, subFinder
+}
+
+// fillFormatBlockRMQR fills both copies of the format information in the rMQR code matrix.
+func fillFormatBlockRMQR(field [][]Cell, version int, errorLevel ErrorCorrectionLevel) {
+	finderInfo, subFinderInfo := rmqrFormatInfo(version, errorLevel)
+	finder, subFinder := rmqrFormatPositions(len(field[0]), len(field))
+	for n := 0; n < 18; n++ {
+		field[finder[n][1]][finder[n][0]] = Cell{Value: finderInfo>>n&1 == 1, Type: CellTypeFormat}
+		field[subFinder[n][1]][subFinder[n][0]] = Cell{Value: subFinderInfo>>n&1 == 1, Type: CellTypeFormat}
+	}
+}
+
+// createRMQRFunctionField creates an rMQR code matrix with the function patterns: the finder pattern (top-left),
+// the finder sub-pattern (bottom-right), the corner finder patterns, the timing and alignment patterns
+// and empty format blocks. The rest of the cells have the CellTypeData type.
+func createRMQRFunctionField(version int) [][]Cell {
+	width, height := getRMQRSize(version)
+	field := make([][]Cell, height)
+	for i := range field {
+		field[i] = make([]Cell, width)
+	}
+
+	// timing patterns at the edges and the columns of the alignment patterns
+	for x := 0; x < width; x++ {
+		field[0][x] = Cell{Value: x%2 == 0, Type: CellTypeSyncPattern}
+		field[height-1][x] = Cell{Value: x%2 == 0, Type: CellTypeSyncPattern}
+	}
+	for _, x := range append([]int{0, width - 1}, rmqrAlignmentColumns[width]...) {
+		for y := 0; y < height; y++ {
+			field[y][x] = Cell{Value: y%2 == 0, Type: CellTypeSyncPattern}
+		}
+	}
+
+	// corner finder patterns, the bottom-left one is covered by the finder pattern and its separator in R7 and R9
+	field[0][width-2] = Cell{Value: true, Type: CellTypeSearchPattern}
+	field[0][width-1] = Cell{Value: true, Type: CellTypeSearchPattern}
+	field[1][width-2] = Cell{Value: false, Type: CellTypeSearchPattern}
+	field[1][width-1] = Cell{Value: true, Type: CellTypeSearchPattern}
+	for x := 0; x < 3; x++ {
+		field[height-1][x] = Cell{Value: true, Type: CellTypeSearchPattern}
+	}
+	field[height-2][0] = Cell{Value: true, Type: CellTypeSearchPattern}
+	field[height-2][1] = Cell{Value: false, Type: CellTypeSearchPattern}
+
+	// finder pattern with the separator
+	for i := 0; i < 8 && i < height; i++ {
+		for j := 0; j < 8; j++ {
+			value := i < 7 && j < 7 && searchPattern[i][j]
+			field[i][j] = Cell{Value: value, Type: CellTypeSearchPattern}
+		}
+	}
+
+	// finder sub-pattern
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			field[height-5+i][width-5+j] = Cell{Value: alignmentPattern[i][j], Type: CellTypeSearchPattern}
+		}
+	}
+
+	// alignment patterns at the top and the bottom edges
+	for _, x := range rmqrAlignmentColumns[width] {
+		for _, top := range []int{0, height - 3} {
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					field[top+i][x-1+j] = Cell{Value: i != 1 || j != 1, Type: CellTypeAlignmentPattern}
+				}
+			}
+		}
+	}
+
+	finder, subFinder := rmqrFormatPositions(width, height)
+	for n := 0; n < 18; n++ {
+		field[finder[n][1]][finder[n][0]] = Cell{Value: false, Type: CellTypeFormat}
+		field[subFinder[n][1]][subFinder[n][0]] = Cell{Value: false, Type: CellTypeFormat}
+	}
+
+	return field
+}
+
+// rmqrPosition returns the start position of the data placement in the rMQR code matrix:
+// the pairs of columns start from the second column from the right.
+func rmqrPosition(field [][]Cell) position {
+	width, height := len(field[0]), len(field)
+	return position{X: width - 2, Y: height - 1, Size: height, Direction: -1, Micro: true, evenReverse: true}
+}
+
+// generateRMQRField creates an rMQR code matrix based on the given data, version and error correction level.
+// rMQR codes have the only mask pattern, so the penalty isn't evaluated.
+func generateRMQRField(data []byte, version int, errorLevel ErrorCorrectionLevel) [][]Cell {
+	field := createRMQRFunctionField(version)
+	walkDataModules(field, len(data), version, errorLevel, func(x, y, byteIdx, bitIdx int) {
+		field[y][x].Value = data[byteIdx]&(1<<uint(7-bitIdx)) != 0
+	})
+
+	fillFormatBlockRMQR(field, version, errorLevel)
+	applyMask(field, rmqrMask)
+
+	return field
+}
+
+// decodeFormatRMQR finds the version and the error correction level with the closest format information
+// of the rMQR code. It's the reverse operation for fillFormatBlockRMQR.
+func decodeFormatRMQR(field [][]Cell) (int, ErrorCorrectionLevel, error) {
+	finder, subFinder := rmqrFormatPositions(len(field[0]), len(field))
+	var first, second [18]byte
+	for n := 0; n < 18; n++ {
+		if field[finder[n][1]][finder[n][0]].Value {
+			first[n] = 1
+		}
+		if field[subFinder[n][1]][subFinder[n][0]].Value {
+			second[n] = 1
+		}
+	}
+
+	bestDistance := maxInfoDistance + 1
+	version := 0
+	var level ErrorCorrectionLevel
+	for v := R7x43; v <= R17x139; v++ {
+		for _, l := range []ErrorCorrectionLevel{ErrorCorrectionLevelMedium, ErrorCorrectionLevelHigh} {
+			finderInfo, subFinderInfo := rmqrFormatInfo(v, l)
+			var expectedFirst, expectedSecond [18]byte
+			for n := 0; n < 18; n++ {
+				expectedFirst[n] = byte(finderInfo >> n & 1)
+				expectedSecond[n] = byte(subFinderInfo >> n & 1)
+			}
+
+			distance := min(infoDistance(first[:], expectedFirst[:]), infoDistance(second[:], expectedSecond[:]))
+			if distance < bestDistance {
+				bestDistance = distance
+				version = v
+				level = l
+			}
+		}
+	}
+
+	if bestDistance > maxInfoDistance {
+		return 0, 0, ErrInvalidFormatInfo
+	}
+
+	return version, level, nil
+}
@@ -0,0 +1,314 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"qrcode/encode"
+)
+
+func TestRMQRFunctionField(t *testing.T) {
+	// remainder bits of the data area for each version
+	remainders := [32]int{
+		0, 3, 5, 6, 1,
+		2, 3, 1, 4, 5,
+		2, 1, 0, 2, 7, 6,
+		4, 1, 6, 4, 3, 0,
+		1, 4, 6, 7, 2,
+		1, 2, 0, 3, 4,
+	}
+
+	for version := R7x43; version <= R17x139; version++ {
+		width, height := getRMQRSize(version)
+		t.Run(fmt.Sprintf("R%vx%v", height, width), func(t *testing.T) {
+			field := createFunctionField(version)
+			if len(field) != height || len(field[0]) != width {
+				t.Fatalf("Expected %vx%v, got %vx%v", height, width, len(field), len(field[0]))
+			}
+
+			dataModules := 0
+			for _, row := range field {
+				for _, cell := range row {
+					if cell.Type == CellTypeData {
+						dataModules++
+					}
+				}
+			}
+
+			for _, level := range []ErrorCorrectionLevel{ErrorCorrectionLevelMedium, ErrorCorrectionLevelHigh} {
+				total, _ := rmqrCodewordsCount(version, level)
+				if expected := total*8 + remainders[version-R7x43]; dataModules != expected {
+					t.Errorf("Expected %v data modules for level %v, got %v", expected, level, dataModules)
+				}
+			}
+		})
+	}
+}
+
+func TestRMQRFormatInfo(t *testing.T) {
+	finder, subFinder := rmqrFormatInfo(R7x43, ErrorCorrectionLevelMedium)
+	if finder != rmqrFinderFormatMask || subFinder != rmqrSubFinderFormatMask {
+		t.Errorf("Expected %b %b, got %b %b", rmqrFinderFormatMask, rmqrSubFinderFormatMask, finder, subFinder)
+	}
+
+	for version := R7x43; version <= R17x139; version++ {
+		for _, level := range []ErrorCorrectionLevel{ErrorCorrectionLevelMedium, ErrorCorrectionLevelHigh} {
+			field := createFunctionField(version)
+			fillFormatBlockRMQR(field, version, level)
+
+			// damage the copy next to the finder pattern
+			for x := 8; x < 12; x++ {
+				field[1][x].Value = !field[1][x].Value
+			}
+
+			decodedVersion, decodedLevel, err := decodeFormatRMQR(field)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decodedVersion != version || decodedLevel != level {
+				t.Errorf("Expected %v %v, got %v %v", version, level, decodedVersion, decodedLevel)
+			}
+		}
+	}
+}
+
+func TestCandidateVersions(t *testing.T) {
+	versions, err := candidateVersions(false, true, ErrorCorrectionLevelMedium)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 32 {
+		t.Fatalf("Expected 32 versions, got %v", len(versions))
+	}
+
+	// R11x27 (297 modules) is the smallest one, R7x43 (301 modules) is the next one
+	if versions[0] != R11x27 || versions[1] != R7x43 || versions[31] != R17x139 {
+		t.Errorf("Unexpected order: %v", versions)
+	}
+}
+
+func TestCreateRMQR(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options *QRCodeOptions
+		version int
+	}{
+		{"numeric", "0123456789", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, R11x27},
+		{"alphanumeric", "HELLO WORLD", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, R13x27},
+		{"high", "HELLO WORLD", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelHigh}, R11x43},
+		{"byte", "https://example.com/rmqr", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, R13x43},
+		{"utf-8", "привет мир", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, R13x43},
+		{"kanji", "茗荷", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelHigh}, R11x27},
+		{"fixed version", "12345", &QRCodeOptions{Version: R17x139, ErrorLevel: ErrorCorrectionLevelHigh}, R17x139},
+		{"mode", "12345", &QRCodeOptions{Version: R13x99, Mode: encode.EncodingModeByte, ErrorLevel: ErrorCorrectionLevelMedium}, R13x99},
+		{"longest", strings.Repeat("1", 361), &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, R17x139},
+		{"width 59", "https://example.com/rmqr", &QRCodeOptions{Version: R13x59, ErrorLevel: ErrorCorrectionLevelMedium}, R13x59},
+		{"width 77", "https://example.com/rmqr", &QRCodeOptions{Version: R15x77, ErrorLevel: ErrorCorrectionLevelHigh}, R15x77},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr, err := Create(test.content, test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			width, height := getRMQRSize(test.version)
+			if len(qr.Data) != height || len(qr.Data[0]) != width {
+				t.Fatalf("Expected %vx%v, got %vx%v", height, width, len(qr.Data), len(qr.Data[0]))
+			}
+
+			result, err := Decode(qr.Data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Content != test.content {
+				t.Errorf("Expected %v, got %v", test.content, result.Content)
+			}
+			if result.Version != test.version {
+				t.Errorf("Expected version %v, got %v", test.version, result.Version)
+			}
+			if result.ErrorLevel != test.options.ErrorLevel {
+				t.Errorf("Expected level %v, got %v", test.options.ErrorLevel, result.ErrorLevel)
+			}
+		})
+	}
+}
+
+func TestRMQRCapacity(t *testing.T) {
+	// the character capacities of ISO/IEC 23941: numeric, alphanumeric, byte and kanji
+	tests := []struct {
+		version    int
+		level      ErrorCorrectionLevel
+		capacities [4]int
+	}{
+		{R7x43, ErrorCorrectionLevelMedium, [4]int{12, 7, 5, 3}},
+		{R7x43, ErrorCorrectionLevelHigh, [4]int{5, 3, 2, 1}},
+		{R11x27, ErrorCorrectionLevelMedium, [4]int{14, 8, 6, 3}},
+		{R11x27, ErrorCorrectionLevelHigh, [4]int{9, 6, 4, 2}},
+		{R17x139, ErrorCorrectionLevelMedium, [4]int{361, 219, 150, 92}},
+		{R17x139, ErrorCorrectionLevelHigh, [4]int{178, 108, 74, 46}},
+	}
+
+	modes := []encode.EncodingMode{encode.EncodingModeNumeric, encode.EncodingModeAlphaNumeric, encode.EncodingModeByte, encode.EncodingModeKanji}
+	characters := []string{"1", "A", "a", "茗"}
+
+	for _, test := range tests {
+		for idx, mode := range modes {
+			t.Run(fmt.Sprintf("version %v, level %v, mode %v", test.version, test.level, mode), func(t *testing.T) {
+				options := &QRCodeOptions{Version: test.version, ErrorLevel: test.level, Mode: mode}
+				content := strings.Repeat(characters[idx], test.capacities[idx])
+
+				qr, err := Create(content, options)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				result, err := Decode(qr.Data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Content != content {
+					t.Errorf("Expected %v, got %v", content, result.Content)
+				}
+
+				if _, err := Create(content+characters[idx], options); !errors.Is(err, ErrContentTooLong) {
+					t.Errorf("Expected %v, got %v", ErrContentTooLong, err)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateRMQRErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options *QRCodeOptions
+		err     error
+	}{
+		{"low level", "123", &QRCodeOptions{RMQR: true}, ErrRMQRErrorLevel},
+		{"quartile level", "123", &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelQuartile}, ErrRMQRErrorLevel},
+		{"low level with version", "123", &QRCodeOptions{Version: R7x43}, ErrRMQRErrorLevel},
+		{"micro QR", "123", &QRCodeOptions{RMQR: true, MicroQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, ErrMicroQRAndRMQR},
+		{"too long", strings.Repeat("1", 362), &QRCodeOptions{RMQR: true, ErrorLevel: ErrorCorrectionLevelMedium}, ErrContentTooLong},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Create(test.content, test.options); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	if _, err := CreateStructuredAppend("123", &QRCodeOptions{RMQR: true}); !errors.Is(err, ErrStructuredAppendMicroQR) {
+		t.Errorf("Expected %v, got %v", ErrStructuredAppendMicroQR, err)
+	}
+}
+
+// checkRMQRModules decodes the modules of the plotted rMQR code.
+func checkRMQRModules(t *testing.T, modules [][]Cell, content string, version int) {
+	t.Helper()
+
+	result, err := Decode(modules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != content || result.Version != version {
+		t.Errorf("Expected %v of version %v, got %v of version %v", content, version, result.Content, result.Version)
+	}
+}
+
+func TestPlotRMQR(t *testing.T) {
+	content := "HELLO"
+	qr, err := Create(content, &QRCodeOptions{Version: R7x43, ErrorLevel: ErrorCorrectionLevelMedium})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkRMQRModules(t, qr.Data, content, R7x43)
+
+	t.Run("png", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{Scale: 2, Border: 4, Eye: &EyeStyle{RingShape: ModuleShapeRounded}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if size := img.Bounds().Size(); size.X != 43*2+8 || size.Y != 7*2+8 {
+			t.Errorf("Expected %vx%v, got %vx%v", 43*2+8, 7*2+8, size.X, size.Y)
+		}
+		checkRMQRModules(t, imageModules(img, 43, 7, 2, 4), content, R7x43)
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: SVG}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), `width="172" height="28" viewBox="0 0 172 28"`) {
+			t.Errorf("Expected rectangular svg, got %v", buf.String())
+		}
+
+		var img svgImage
+		if err := xml.Unmarshal(buf.Bytes(), &img); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		polygons := parseSVGPath(t, img.Path.D)
+		modules := make([][]Cell, 7)
+		for y := range modules {
+			modules[y] = make([]Cell, 43)
+			for x := range modules[y] {
+				modules[y][x].Value = windingNumber(polygons, float64(x*4+2), float64(y*4+2)) != 0
+			}
+		}
+		checkRMQRModules(t, modules, content, R7x43)
+	})
+
+	t.Run("pdf", func(t *testing.T) {
+		var buf bytes.Buffer
+		options := &PlotOptions{OutputFormat: PDF, ModuleSize: Point}
+		if err := qr.Plot(&buf, options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "/MediaBox [0 0 43 7]") {
+			t.Errorf("Expected rectangular page, got %v", buf.String())
+		}
+		checkRMQRModules(t, pdfModules(t, buf.String(), 43, 7, options), content, R7x43)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: ASCII, Border: 1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 9 || len(lines[0]) != 45*2 {
+			t.Errorf("Expected 9 lines of %v characters, got %v lines of %v", 45*2, len(lines), len(lines[0]))
+		}
+
+		text := parseText(t, buf.String(), ASCII)
+		modules := make([][]Cell, 7)
+		for y := range modules {
+			modules[y] = make([]Cell, 43)
+			for x := range modules[y] {
+				modules[y][x].Value = text[y+1][x+1]
+			}
+		}
+		checkRMQRModules(t, modules, content, R7x43)
+	})
+
+	t.Run("logo", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{Logo: &Logo{Image: testLogo(10, 10)}}); !errors.Is(err, ErrLogoRMQR) {
+			t.Errorf("Expected %v, got %v", ErrLogoRMQR, err)
+		}
+	})
+}
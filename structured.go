@@ -0,0 +1,173 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"qrcode/encode"
+)
+
+var (
+	ErrStructuredAppendMicroQR = errors.New("micro QR and rMQR codes don't support structured append")
+	ErrTooManySymbols          = fmt.Errorf("content doesn't fit into %d symbols", encode.MaxStructuredAppendSymbols)
+)
+
+// structuredAppendSymbol returns the blocks of the symbol content for the version.
+func structuredAppendSymbol(content string, mode encode.EncodingMode, version int) ([]*encode.EncodeBlock, error) {
+	if mode != 0 {
+		return []*encode.EncodeBlock{{Mode: mode, Data: content}}, nil
+	}
+
+	return encode.Segment(content, version)
+}
+
+// symbolFits checks that the content with the Structured Append header fits into the symbol.
+func symbolFits(content string, mode encode.EncodingMode, version int, ecl ErrorCorrectionLevel) (bool, error) {
+	header, err := encode.NewStructuredAppendBlock(0, 1, 0)
+	if err != nil {
+		return false, err
+	}
+
+	blocks, err := structuredAppendSymbol(content, mode, version)
+	if err != nil {
+		return false, fmt.Errorf("failed to segment content: %w", err)
+	}
+	blocks = append([]*encode.EncodeBlock{header}, blocks...)
+
+	dataSize := 0
+	for _, block := range blocks {
+		blockSize, err := block.CalculateDataBitsCount()
+		if err != nil {
+			return false, fmt.Errorf("failed to calculate data bits count: %w", err)
+		}
+		dataSize += blockSize
+	}
+
+	return isVersionEnough(blocks, version, dataSize, ecl)
+}
+
+// maxSymbolRunes returns the upper bound of the number of runes in the symbol: all runes are digits,
+// which take 10/3 bits in the numeric mode.
+func maxSymbolRunes(version int, ecl ErrorCorrectionLevel) int {
+	dataCodewords := codewordsCount[version] - errorCorrectionCodeWords[version][ecl]
+	return dataCodewords*8*3/10 + 1
+}
+
+// splitStructuredAppend splits the content into the parts, which fit into the symbols of the version.
+// Each symbol gets the longest prefix of the rest of the content (by runes).
+// ErrTooManySymbols is returned if the content doesn't fit into the maximum number of symbols.
+func splitStructuredAppend(content string, mode encode.EncodingMode, version int, ecl ErrorCorrectionLevel) ([]string, error) {
+	var parts []string
+	runes := []rune(content)
+
+	maxRunes := maxSymbolRunes(version, ecl)
+
+	for len(runes) > 0 {
+		if len(parts) == encode.MaxStructuredAppendSymbols {
+			return nil, ErrTooManySymbols
+		}
+
+		var fitErr error
+		// the first count of runes, which doesn't fit
+		count := sort.Search(min(len(runes), maxRunes), func(idx int) bool {
+			ok, err := symbolFits(string(runes[:idx+1]), mode, version, ecl)
+			if err != nil {
+				fitErr = err
+			}
+			return !ok
+		})
+		if fitErr != nil {
+			return nil, fitErr
+		}
+		if count == 0 {
+			return nil, ErrContentTooLong
+		}
+
+		parts = append(parts, string(runes[:count]))
+		runes = runes[count:]
+	}
+
+	return parts, nil
+}
+
+// CreateStructuredAppend creates a sequence of up to 16 QR Codes with the content split between them.
+// Each symbol starts with the Structured Append header: the index of the symbol, the number of symbols
+// and the parity of the whole content (XOR of the bytes, which are written in the symbols: ISO 8859-1 bytes
+// of the byte mode, Shift JIS bytes of the kanji mode, see encode.EncodeBlock.ParityBytes),
+// so the reader can restore the content.
+// All symbols have the same version: the given one or the smallest version, which fits the content.
+// Micro QR and rMQR codes are not supported.
+func CreateStructuredAppend(content string, options *QRCodeOptions) ([]*QRCode, error) {
+	if options == nil {
+		options = &QRCodeOptions{}
+	}
+	if options.MicroQR || options.RMQR || options.Version < 0 || encode.IsRMQRVersion(options.Version) {
+		return nil, ErrStructuredAppendMicroQR
+	}
+	if content == "" {
+		return nil, fmt.Errorf("failed to split content: %w", encode.ErrCannotDeterminEncodingMode)
+	}
+
+	start, end := 1, 40
+	if options.Version != 0 {
+		start, end = options.Version, options.Version
+	}
+
+	var parts []string
+	version := start
+	runesCount := len([]rune(content))
+	for ; version <= end; version++ {
+		if runesCount > maxSymbolRunes(version, options.ErrorLevel)*encode.MaxStructuredAppendSymbols {
+			continue
+		}
+
+		var err error
+		parts, err = splitStructuredAppend(content, options.Mode, version, options.ErrorLevel)
+		if err != nil && !errors.Is(err, ErrContentTooLong) && !errors.Is(err, ErrTooManySymbols) {
+			return nil, fmt.Errorf("failed to split content: %w", err)
+		}
+		if err == nil {
+			break
+		}
+	}
+	if version > end {
+		return nil, ErrTooManySymbols
+	}
+
+	partsBlocks := make([][]*encode.EncodeBlock, len(parts))
+	var parity byte
+	for idx, part := range parts {
+		blocks, err := structuredAppendSymbol(part, options.Mode, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to segment content: %w", err)
+		}
+
+		partParity, err := encode.BlocksParity(blocks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate parity: %w", err)
+		}
+		partsBlocks[idx] = blocks
+		parity ^= partParity
+	}
+
+	symbols := make([]*QRCode, 0, len(parts))
+	for idx, blocks := range partsBlocks {
+		header, err := encode.NewStructuredAppendBlock(idx, len(parts), parity)
+		if err != nil {
+			return nil, err
+		}
+
+		qr, err := CreateMultiMode(append([]*encode.EncodeBlock{header}, blocks...), &QRCodeOptionsMultiMode{
+			ErrorLevel: options.ErrorLevel,
+			Version:    version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create symbol %d: %w", idx, err)
+		}
+
+		symbols = append(symbols, qr)
+	}
+
+	return symbols, nil
+}
@@ -0,0 +1,113 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"qrcode/encode"
+)
+
+func TestCreateStructuredAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options *QRCodeOptions
+		version int
+		symbols int
+		// written are the bytes of the content in the symbols, the parity is calculated over them
+		written []byte
+	}{
+		{"short content", "HELLO WORLD", nil, 1, 1, nil},
+		{"fixed version", strings.Repeat("0123456789", 20), &QRCodeOptions{Version: 2}, 2, 3, nil},
+		{"utf-8", strings.Repeat("привет мир ", 10), &QRCodeOptions{Version: 5, ErrorLevel: ErrorCorrectionLevelMedium}, 5, 3, nil},
+		{"longer than version 40", strings.Repeat("config=value;", 400), nil, 12, 15, nil},
+		{"mode", strings.Repeat("ABC", 30), &QRCodeOptions{Mode: encode.EncodingModeByte, Version: 1}, 1, 6, nil},
+		{"latin-1", strings.Repeat("café ", 21), &QRCodeOptions{Version: 3}, 3, 3,
+			bytes.Repeat([]byte("caf\xe9 "), 21)},
+		{"kanji", strings.Repeat("漢字", 21), &QRCodeOptions{Version: 1}, 1, 5,
+			bytes.Repeat([]byte{0x8a, 0xbf, 0x8e, 0x9a}, 21)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			symbols, err := CreateStructuredAppend(test.content, test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(symbols) != test.symbols {
+				t.Fatalf("Expected %v symbols, got %v", test.symbols, len(symbols))
+			}
+
+			written := test.written
+			if written == nil {
+				written = []byte(test.content)
+			}
+			parity := encode.StructuredAppendParity(written)
+			var content strings.Builder
+			for idx, symbol := range symbols {
+				result, err := Decode(symbol.Data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Version != test.version {
+					t.Errorf("Expected version %v, got %v", test.version, result.Version)
+				}
+
+				header := result.Blocks[0]
+				if header.Mode != encode.EncodingModeStructuredAppend {
+					t.Fatalf("Expected structured append header, got %v", header.Mode)
+				}
+				if header.SequenceIndex != idx || header.SequenceTotal != len(symbols) || header.Parity != parity {
+					t.Errorf("Expected symbol %v of %v with parity %v, got %v of %v with parity %v",
+						idx, len(symbols), parity, header.SequenceIndex, header.SequenceTotal, header.Parity)
+				}
+
+				content.WriteString(result.Content)
+			}
+
+			if content.String() != test.content {
+				t.Errorf("Expected %v, got %v", test.content, content.String())
+			}
+		})
+	}
+}
+
+func TestCreateStructuredAppendErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options *QRCodeOptions
+		err     error
+	}{
+		{"micro QR", "123", &QRCodeOptions{MicroQR: true}, ErrStructuredAppendMicroQR},
+		{"micro version", "123", &QRCodeOptions{Version: M2}, ErrStructuredAppendMicroQR},
+		{"too many symbols", strings.Repeat("0123456789", 100), &QRCodeOptions{Version: 1}, ErrTooManySymbols},
+		{"too long", strings.Repeat("a", 16*2953+1), nil, ErrTooManySymbols},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.name), func(t *testing.T) {
+			if _, err := CreateStructuredAppend(test.content, test.options); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestSplitStructuredAppend(t *testing.T) {
+	// 16 symbols of version 1-H hold 16*11 digits
+	parts, err := splitStructuredAppend(strings.Repeat("1", 16*11), 0, 1, ErrorCorrectionLevelHigh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != encode.MaxStructuredAppendSymbols {
+		t.Errorf("Expected %v parts, got %v", encode.MaxStructuredAppendSymbols, len(parts))
+	}
+
+	if _, err := splitStructuredAppend(strings.Repeat("1", 16*11+1), 0, 1, ErrorCorrectionLevelHigh); !errors.Is(err, ErrTooManySymbols) {
+		t.Errorf("Expected %v, got %v", ErrTooManySymbols, err)
+	}
+}
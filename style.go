@@ -0,0 +1,354 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrUnsupportedShape = errors.New("unsupported shape")
+
+// ModuleShape is a shape of the dark modules.
+type ModuleShape int
+
+const (
+	// ModuleShapeSquare fills the whole module.
+	ModuleShapeSquare ModuleShape = iota
+	// ModuleShapeCircle is a circle inscribed in the module.
+	ModuleShapeCircle
+	// ModuleShapeRounded rounds the corners of the module, which don't touch other dark modules.
+	// For the finder patterns it's a square with rounded corners.
+	ModuleShapeRounded
+	// ModuleShapeDiamond is a square rotated by 45 degrees inscribed in the module.
+	ModuleShapeDiamond
+)
+
+// eyeCornerRadius is the radius of the rounded corners of the finder pattern parts relative to their width.
+const eyeCornerRadius = 2.0 / 7
+
+// CellStyle is a style of the dark modules of one cell type.
+type CellStyle struct {
+	// Color is the color of the modules.
+	// Default: PlotOptions.Foreground.
+	Color color.Color
+
+	// Shape is the shape of the modules.
+	// Default: ModuleShapeSquare.
+	Shape ModuleShape
+}
+
+// EyeStyle is a style of the finder patterns ("eyes"). Each finder pattern is drawn as a unit:
+// the outer ring (7x7 modules) and the center (3x3 modules).
+type EyeStyle struct {
+	// RingShape is the shape of the outer ring: ModuleShapeSquare, ModuleShapeRounded or ModuleShapeCircle.
+	RingShape ModuleShape
+
+	// RingColor is the color of the outer ring.
+	// Default: the color of the CellTypeSearchPattern style or PlotOptions.Foreground.
+	RingColor color.Color
+
+	// CenterShape is the shape of the center, ModuleShapeCircle draws a dot.
+	CenterShape ModuleShape
+
+	// CenterColor is the color of the center.
+	// Default: the color of the CellTypeSearchPattern style or PlotOptions.Foreground.
+	CenterColor color.Color
+}
+
+// plotStyle is a resolved style of the plot: all colors are set and checked.
+type plotStyle struct {
+	foreground, background color.Color
+	cells                  map[CellType]CellStyle
+	eye                    *EyeStyle
+}
+
+// newPlotStyle resolves the colors and the shapes of the plot options and checks them.
+func newPlotStyle(options *PlotOptions) (*plotStyle, error) {
+	foreground, background := options.colors()
+	style := &plotStyle{
+		foreground: foreground,
+		background: background,
+		cells:      make(map[CellType]CellStyle, len(options.CellStyles)),
+	}
+
+	if err := checkContrast(foreground, background); err != nil {
+		return nil, err
+	}
+
+	for cellType, cellStyle := range options.CellStyles {
+		if cellStyle.Shape < ModuleShapeSquare || cellStyle.Shape > ModuleShapeDiamond {
+			return nil, fmt.Errorf("%w: %d for cell type %d", ErrUnsupportedShape, cellStyle.Shape, cellType)
+		}
+		if cellStyle.Color == nil {
+			cellStyle.Color = foreground
+		} else if err := checkContrast(cellStyle.Color, background); err != nil {
+			return nil, fmt.Errorf("cell type %d: %w", cellType, err)
+		}
+		style.cells[cellType] = cellStyle
+	}
+
+	if options.Eye != nil {
+		eye := *options.Eye
+		if eye.RingShape != ModuleShapeSquare && eye.RingShape != ModuleShapeRounded && eye.RingShape != ModuleShapeCircle {
+			return nil, fmt.Errorf("%w: %d for the finder pattern ring", ErrUnsupportedShape, eye.RingShape)
+		}
+		if eye.CenterShape < ModuleShapeSquare || eye.CenterShape > ModuleShapeDiamond {
+			return nil, fmt.Errorf("%w: %d for the finder pattern center", ErrUnsupportedShape, eye.CenterShape)
+		}
+
+		searchColor := style.cellStyle(CellTypeSearchPattern).Color
+		for _, clr := range []*color.Color{&eye.RingColor, &eye.CenterColor} {
+			if *clr == nil {
+				*clr = searchColor
+			} else if err := checkContrast(*clr, background); err != nil {
+				return nil, fmt.Errorf("finder pattern: %w", err)
+			}
+		}
+		style.eye = &eye
+	}
+
+	return style, nil
+}
+
+// cellStyle returns the style of the cell type.
+func (s *plotStyle) cellStyle(cellType CellType) CellStyle {
+	if cellStyle, ok := s.cells[cellType]; ok {
+		return cellStyle
+	}
+	return CellStyle{Color: s.foreground, Shape: ModuleShapeSquare}
+}
+
+// isEye returns true if the cell is drawn as a part of the finder pattern unit.
+// The finder sub-pattern and the corner finder patterns of rMQR codes are drawn as separate modules.
+func (s *plotStyle) isEye(data [][]Cell, x, y int) bool {
+	if s.eye == nil || data[y][x].Type != CellTypeSearchPattern {
+		return false
+	}
+	for _, origin := range finderOrigins(matrixSize(data)) {
+		if x >= origin[0] && x < origin[0]+7 && y >= origin[1] && y < origin[1]+7 {
+			return true
+		}
+	}
+	return false
+}
+
+// palette returns all colors of the style, the background is the first one.
+func (s *plotStyle) palette() color.Palette {
+	palette := color.Palette{s.background, s.foreground}
+	add := func(clr color.Color) {
+		for _, existing := range palette {
+			if color.NRGBAModel.Convert(existing) == color.NRGBAModel.Convert(clr) {
+				return
+			}
+		}
+		palette = append(palette, clr)
+	}
+	// the cell types are sorted, so the palette (and the GIF output) is the same for each run
+	cellTypes := make([]CellType, 0, len(s.cells))
+	for cellType := range s.cells {
+		cellTypes = append(cellTypes, cellType)
+	}
+	sort.Slice(cellTypes, func(i, j int) bool { return cellTypes[i] < cellTypes[j] })
+	for _, cellType := range cellTypes {
+		add(s.cells[cellType].Color)
+	}
+	if s.eye != nil {
+		add(s.eye.RingColor)
+		add(s.eye.CenterColor)
+	}
+	return palette
+}
+
+// finderOrigins returns the top-left corners of the finder patterns of the matrix with the given size.
+// Micro QR and rMQR codes have only one finder pattern.
+func finderOrigins(width, height int) [][2]int {
+	size := height
+	if width != height || size < getSize(1) {
+		return [][2]int{{0, 0}}
+	}
+	return [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}}
+}
+
+// roundedCorners returns which corners (top-left, top-right, bottom-right, bottom-left) of the rounded module
+// are rounded: the corner is rounded if both neighbours at this corner are light.
+func roundedCorners(data [][]Cell, x, y int) [4]bool {
+	top, right := isDark(data, x, y-1), isDark(data, x+1, y)
+	bottom, left := isDark(data, x, y+1), isDark(data, x-1, y)
+	return [4]bool{!top && !left, !top && !right, !bottom && !right, !bottom && !left}
+}
+
+// moduleContains checks if the point (u, v) of the module (from 0 to 1) is inside the module shape.
+func moduleContains(shape ModuleShape, corners [4]bool, u, v float64) bool {
+	switch shape {
+	case ModuleShapeCircle:
+		return (u-0.5)*(u-0.5)+(v-0.5)*(v-0.5) <= 0.25
+	case ModuleShapeDiamond:
+		return math.Abs(u-0.5)+math.Abs(v-0.5) <= 0.5
+	case ModuleShapeRounded:
+		var corner int
+		switch {
+		case u < 0.5 && v < 0.5:
+			corner = 0
+		case u >= 0.5 && v < 0.5:
+			corner = 1
+		case u >= 0.5 && v >= 0.5:
+			corner = 2
+		default:
+			corner = 3
+		}
+		if corners[corner] {
+			return (u-0.5)*(u-0.5)+(v-0.5)*(v-0.5) <= 0.25
+		}
+	}
+	return true
+}
+
+// shapeContains checks if the point (u, v) of the rectangle with the given width and height
+// (in the same units, the origin is the top-left corner) is inside the shape.
+func shapeContains(shape ModuleShape, width, height, u, v float64) bool {
+	if u < 0 || v < 0 || u > width || v > height {
+		return false
+	}
+
+	dx, dy := math.Abs(u-width/2), math.Abs(v-height/2)
+	switch shape {
+	case ModuleShapeCircle:
+		return (dx*dx)/(width*width/4)+(dy*dy)/(height*height/4) <= 1
+	case ModuleShapeDiamond:
+		return dx/(width/2)+dy/(height/2) <= 1
+	case ModuleShapeRounded:
+		radius := math.Min(width, height) * eyeCornerRadius
+		cx, cy := dx-(width/2-radius), dy-(height/2-radius)
+		if cx > 0 && cy > 0 {
+			return cx*cx+cy*cy <= radius*radius
+		}
+	}
+	return true
+}
+
+// eyePart is a part of the finder pattern: the rectangle (in modules) filled with the shape,
+// the ring has the hole (inset by one module) with the same shape.
+type eyePart struct {
+	x, y, size int
+	shape      ModuleShape
+	clr        color.Color
+	ring       bool
+}
+
+// contains checks if the point (in modules) is inside the part.
+func (p eyePart) contains(x, y float64) bool {
+	size := float64(p.size)
+	u, v := x-float64(p.x), y-float64(p.y)
+	if !shapeContains(p.shape, size, size, u, v) {
+		return false
+	}
+	return !p.ring || !shapeContains(p.shape, size-2, size-2, u-1, v-1)
+}
+
+// eyeParts returns the parts of all finder patterns of the matrix with the given size.
+func (s *plotStyle) eyeParts(width, height int) []eyePart {
+	var parts []eyePart
+	for _, origin := range finderOrigins(width, height) {
+		parts = append(parts,
+			eyePart{x: origin[0], y: origin[1], size: 7, shape: s.eye.RingShape, clr: s.eye.RingColor, ring: true},
+			eyePart{x: origin[0] + 2, y: origin[1] + 2, size: 3, shape: s.eye.CenterShape, clr: s.eye.CenterColor},
+		)
+	}
+	return parts
+}
+
+// drawStyled draws the dark modules with the shapes and the finder pattern units to the image,
+// which is already filled with the background. The pixels are tested by their centers.
+func drawStyled(img *image.RGBA, data [][]Cell, scale, border int, style *plotStyle) {
+	for y, row := range data {
+		for x, cell := range row {
+			if !cell.Value || style.isEye(data, x, y) {
+				continue
+			}
+
+			cellStyle := style.cellStyle(cell.Type)
+			if cellStyle.Shape == ModuleShapeSquare {
+				plotRectangle(img, x, y, scale, border, cellStyle.Color)
+				continue
+			}
+
+			corners := roundedCorners(data, x, y)
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					u, v := (float64(px)+0.5)/float64(scale), (float64(py)+0.5)/float64(scale)
+					if moduleContains(cellStyle.Shape, corners, u, v) {
+						img.Set(x*scale+px+border, y*scale+py+border, cellStyle.Color)
+					}
+				}
+			}
+		}
+	}
+
+	if style.eye == nil {
+		return
+	}
+
+	for _, part := range style.eyeParts(matrixSize(data)) {
+		for py := part.y * scale; py < (part.y+part.size)*scale; py++ {
+			for px := part.x * scale; px < (part.x+part.size)*scale; px++ {
+				if part.contains((float64(px)+0.5)/float64(scale), (float64(py)+0.5)/float64(scale)) {
+					img.Set(px+border, py+border, part.clr)
+				}
+			}
+		}
+	}
+}
+
+// svgShapePath returns the SVG path of the shape in the rectangle.
+func svgShapePath(shape ModuleShape, x, y, width, height float64) string {
+	n := formatNumber
+	switch shape {
+	case ModuleShapeCircle:
+		rx, ry := width/2, height/2
+		return fmt.Sprintf("M%s %sa%s %s 0 1 0 %s 0a%s %s 0 1 0 %s 0Z",
+			n(x), n(y+ry), n(rx), n(ry), n(width), n(rx), n(ry), n(-width))
+	case ModuleShapeDiamond:
+		return fmt.Sprintf("M%s %sL%s %sL%s %sL%s %sZ",
+			n(x+width/2), n(y), n(x+width), n(y+height/2), n(x+width/2), n(y+height), n(x), n(y+height/2))
+	case ModuleShapeRounded:
+		r := math.Min(width, height) * eyeCornerRadius
+		return fmt.Sprintf("M%s %sH%sA%s %s 0 0 1 %s %sV%sA%s %s 0 0 1 %s %sH%sA%s %s 0 0 1 %s %sV%sA%s %s 0 0 1 %s %sZ",
+			n(x+r), n(y), n(x+width-r), n(r), n(r), n(x+width), n(y+r),
+			n(y+height-r), n(r), n(r), n(x+width-r), n(y+height),
+			n(x+r), n(r), n(r), n(x), n(y+height-r),
+			n(y+r), n(r), n(r), n(x+r), n(y))
+	}
+	return fmt.Sprintf("M%s %sH%sV%sH%sZ", n(x), n(y), n(x+width), n(y+height), n(x))
+}
+
+// svgModulePath returns the SVG path of the module with the shape (scale is the size of the module).
+func svgModulePath(shape ModuleShape, corners [4]bool, x, y, scale float64) string {
+	if shape != ModuleShapeRounded {
+		return svgShapePath(shape, x, y, scale, scale)
+	}
+
+	// the rounded module goes clockwise through the middles of the sides,
+	// the corner is either an arc with the radius of the half module or two lines through the corner
+	n := formatNumber
+	r := scale / 2
+	middles := [4][2]float64{{x + r, y}, {x + scale, y + r}, {x + r, y + scale}, {x, y + r}}
+	cornerPoints := [4][2]float64{{x, y}, {x + scale, y}, {x + scale, y + scale}, {x, y + scale}}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "M%s %s", n(middles[0][0]), n(middles[0][1]))
+	for idx := 1; idx <= 4; idx++ {
+		corner := idx % 4
+		next := middles[idx%4]
+		if corners[corner] {
+			fmt.Fprintf(&sb, "A%s %s 0 0 1 %s %s", n(r), n(r), n(next[0]), n(next[1]))
+		} else {
+			fmt.Fprintf(&sb, "L%s %sL%s %s", n(cornerPoints[corner][0]), n(cornerPoints[corner][1]), n(next[0]), n(next[1]))
+		}
+	}
+	sb.WriteString("Z")
+	return sb.String()
+}
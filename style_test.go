@@ -0,0 +1,287 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestModuleContains(t *testing.T) {
+	allRounded := [4]bool{true, true, true, true}
+	tests := []struct {
+		shape   ModuleShape
+		corners [4]bool
+		u, v    float64
+		result  bool
+	}{
+		{ModuleShapeSquare, allRounded, 0.05, 0.05, true},
+		{ModuleShapeCircle, [4]bool{}, 0.05, 0.05, false},
+		{ModuleShapeCircle, [4]bool{}, 0.5, 0.05, true},
+		{ModuleShapeDiamond, [4]bool{}, 0.2, 0.2, false},
+		{ModuleShapeDiamond, [4]bool{}, 0.5, 0.1, true},
+		{ModuleShapeRounded, allRounded, 0.05, 0.05, false},
+		{ModuleShapeRounded, [4]bool{false, true, true, true}, 0.05, 0.05, true},
+		{ModuleShapeRounded, [4]bool{true, false, true, true}, 0.95, 0.05, true},
+		{ModuleShapeRounded, [4]bool{true, true, false, true}, 0.95, 0.95, true},
+		{ModuleShapeRounded, [4]bool{true, true, true, false}, 0.05, 0.95, true},
+		{ModuleShapeRounded, [4]bool{true, true, true, false}, 0.95, 0.95, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("shape %v, corners %v, point %v %v", test.shape, test.corners, test.u, test.v), func(t *testing.T) {
+			if result := moduleContains(test.shape, test.corners, test.u, test.v); result != test.result {
+				t.Errorf("Expected %v, got %v", test.result, result)
+			}
+		})
+	}
+}
+
+func TestShapeContains(t *testing.T) {
+	tests := []struct {
+		shape  ModuleShape
+		u, v   float64
+		result bool
+	}{
+		{ModuleShapeSquare, 0.1, 0.1, true},
+		{ModuleShapeSquare, 7.1, 0.1, false},
+		{ModuleShapeRounded, 0.1, 0.1, false},
+		{ModuleShapeRounded, 1, 1, true},
+		{ModuleShapeRounded, 3.5, 0.1, true},
+		{ModuleShapeCircle, 1, 1, false},
+		{ModuleShapeCircle, 3.5, 0.1, true},
+		{ModuleShapeDiamond, 1.5, 1.5, false},
+		{ModuleShapeDiamond, 3.5, 0.5, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("shape %v, point %v %v", test.shape, test.u, test.v), func(t *testing.T) {
+			if result := shapeContains(test.shape, 7, 7, test.u, test.v); result != test.result {
+				t.Errorf("Expected %v, got %v", test.result, result)
+			}
+		})
+	}
+}
+
+func TestRoundedCorners(t *testing.T) {
+	data := [][]Cell{
+		{{Value: false}, {Value: true}, {Value: false}},
+		{{Value: false}, {Value: true}, {Value: true}},
+		{{Value: false}, {Value: false}, {Value: false}},
+	}
+
+	expected := [4]bool{false, false, false, true}
+	if corners := roundedCorners(data, 1, 1); corners != expected {
+		t.Errorf("Expected %v, got %v", expected, corners)
+	}
+}
+
+func TestPlotStyled(t *testing.T) {
+	ringColor := color.RGBA{0, 0, 128, 255}
+	centerColor := color.RGBA{139, 0, 0, 255}
+	alignmentColor := color.RGBA{0, 100, 0, 255}
+
+	options := func(format OutputFormat) *PlotOptions {
+		return &PlotOptions{
+			Scale:        10,
+			Border:       40,
+			OutputFormat: format,
+			CellStyles: map[CellType]CellStyle{
+				CellTypeData:             {Shape: ModuleShapeCircle},
+				CellTypeAlignmentPattern: {Color: alignmentColor, Shape: ModuleShapeRounded},
+			},
+			Eye: &EyeStyle{
+				RingShape:   ModuleShapeRounded,
+				RingColor:   ringColor,
+				CenterShape: ModuleShapeCircle,
+				CenterColor: centerColor,
+			},
+		}
+	}
+
+	qr, err := Create("https://example.com/styled", &QRCodeOptions{Version: 3, ErrorLevel: ErrorCorrectionLevelQuartile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, format := range []OutputFormat{PNG, GIF} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := qr.Plot(&buf, options(format)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			img, _, err := image.Decode(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pixels := []struct {
+				name  string
+				x, y  int
+				color color.Color
+			}{
+				{"rounded ring corner", 41, 41, color.White},
+				{"ring side", 75, 45, ringColor},
+				{"dot center", 75, 75, centerColor},
+				{"dot corner", 61, 61, color.White},
+				// alignment pattern center module (22, 22) of version 3
+				{"alignment center", 40 + 22*10 + 5, 40 + 22*10 + 5, alignmentColor},
+			}
+
+			for _, pixel := range pixels {
+				expected := color.NRGBAModel.Convert(pixel.color)
+				if clr := color.NRGBAModel.Convert(img.At(pixel.x, pixel.y)); clr != expected {
+					t.Errorf("%v: expected %v, got %v", pixel.name, expected, clr)
+				}
+			}
+
+			result, err := ReadImage(img)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Content != "https://example.com/styled" {
+				t.Errorf("Expected https://example.com/styled, got %v", result.Content)
+			}
+		})
+	}
+
+	t.Run("svg", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, options(SVG)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		svg := buf.String()
+
+		if count := strings.Count(svg, `fill-rule="evenodd"`); count != 6 {
+			t.Errorf("Expected 6 parts of finder patterns, got %v", count)
+		}
+		for _, fill := range []string{`fill="#000080"`, `fill="#8b0000"`, `fill="#006400"`, `fill="#000000"`} {
+			if !strings.Contains(svg, fill) {
+				t.Errorf("Expected %v in %v", fill, svg)
+			}
+		}
+		// circles of the data modules
+		if !strings.Contains(svg, " 0 1 0 ") {
+			t.Errorf("Expected arcs of circles, got %v", svg)
+		}
+	})
+
+	for _, format := range []OutputFormat{PDF, EPS} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := qr.Plot(&buf, options(format)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content := buf.String()
+
+			moveTo, curveTo, evenOddFill := " m\n", " c\n", "f*\n"
+			if format == EPS {
+				moveTo, curveTo, evenOddFill = " moveto\n", " curveto\n", "eofill\n"
+			}
+
+			// the data and the alignment modules are separate subpaths, each finder pattern has two subpaths
+			// of the ring and one of the center
+			shapes := 3 * 3
+			for _, row := range qr.Data {
+				for _, cell := range row {
+					if cell.Value && (cell.Type == CellTypeData || cell.Type == CellTypeAlignmentPattern) {
+						shapes++
+					}
+				}
+			}
+			if count := strings.Count(content, moveTo); count != shapes {
+				t.Errorf("Expected %v subpaths, got %v", shapes, count)
+			}
+			if count := strings.Count(content, evenOddFill); count != 6 {
+				t.Errorf("Expected 6 parts of finder patterns, got %v", count)
+			}
+			if !strings.Contains(content, curveTo) {
+				t.Errorf("Expected curves, got %v", content)
+			}
+			for _, clr := range []color.Color{ringColor, centerColor, alignmentColor, color.Black} {
+				if !strings.Contains(content, "\n"+rgbComponents(clr)+" ") {
+					t.Errorf("Expected color %v in %v", rgbComponents(clr), content)
+				}
+			}
+		})
+	}
+
+	t.Run("micro svg", func(t *testing.T) {
+		micro, err := Create("1234", &QRCodeOptions{Version: M2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := micro.Plot(&buf, options(SVG)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count := strings.Count(buf.String(), `fill-rule="evenodd"`); count != 2 {
+			t.Errorf("Expected 2 parts of finder pattern, got %v", count)
+		}
+	})
+}
+
+func TestPlotStylePalette(t *testing.T) {
+	options := &PlotOptions{
+		CellStyles: map[CellType]CellStyle{
+			CellTypeDelimiter:        {Color: color.RGBA{0, 0, 128, 255}},
+			CellTypeData:             {Color: color.RGBA{139, 0, 0, 255}},
+			CellTypeSyncPattern:      {Color: color.RGBA{0, 100, 0, 255}},
+			CellTypeAlignmentPattern: {Color: color.RGBA{64, 0, 64, 255}},
+			CellTypeFormat:           {Color: color.RGBA{0, 64, 64, 255}},
+		},
+	}
+
+	// the background, the foreground and the colors in the order of the cell types
+	expected := color.Palette{color.White, color.Black, color.RGBA{139, 0, 0, 255}, color.RGBA{0, 64, 64, 255},
+		color.RGBA{64, 0, 64, 255}, color.RGBA{0, 100, 0, 255}, color.RGBA{0, 0, 128, 255}}
+
+	for range 20 {
+		style, err := newPlotStyle(options)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		palette := style.palette()
+		if len(palette) != len(expected) {
+			t.Fatalf("Expected %v colors, got %v", len(expected), len(palette))
+		}
+		for idx, clr := range palette {
+			if color.NRGBAModel.Convert(clr) != color.NRGBAModel.Convert(expected[idx]) {
+				t.Fatalf("Expected %v at %v, got %v", expected[idx], idx, clr)
+			}
+		}
+	}
+}
+
+func TestPlotStyledErrors(t *testing.T) {
+	data := [][]Cell{{{Value: true}}}
+
+	tests := []struct {
+		name    string
+		options *PlotOptions
+		err     error
+	}{
+		{"unknown shape", &PlotOptions{CellStyles: map[CellType]CellStyle{CellTypeData: {Shape: 10}}}, ErrUnsupportedShape},
+		{"diamond ring", &PlotOptions{Eye: &EyeStyle{RingShape: ModuleShapeDiamond}}, ErrUnsupportedShape},
+		{"unknown center", &PlotOptions{Eye: &EyeStyle{CenterShape: -1}}, ErrUnsupportedShape},
+		{"low contrast cell", &PlotOptions{CellStyles: map[CellType]CellStyle{CellTypeData: {Color: color.RGBA{220, 220, 220, 255}}}}, ErrLowContrast},
+		{"inverted eye", &PlotOptions{Eye: &EyeStyle{CenterColor: color.White}}, ErrInvertedColors},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Scale = 1
+			test.options.OutputFormat = PNG
+			var buf bytes.Buffer
+			if err := plot(data, &buf, test.options); !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
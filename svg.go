@@ -0,0 +1,214 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// outlineEdge is a directed unit edge of the dark modules outline (in modules).
+// Edges go clockwise around dark areas, so the dark module is always on the right side of the edge.
+type outlineEdge struct {
+	x, y   int
+	dx, dy int
+}
+
+// isDark returns true if the cell is dark, the cells outside the matrix are light.
+func isDark(data [][]Cell, x, y int) bool {
+	if y < 0 || y >= len(data) || x < 0 || x >= len(data[y]) {
+		return false
+	}
+	return data[y][x].Value
+}
+
+// traceOutlines finds the outlines of the dark areas of the matrix.
+// Each outline is a closed polygon: a list of the corner points (in modules), where the direction changes.
+// Outer outlines go clockwise, the outlines of holes go counterclockwise, so the nonzero fill rule fills only dark areas.
+func traceOutlines(data [][]Cell) [][][2]int {
+	var edges []outlineEdge
+	starts := make(map[[2]int][]int)
+	addEdge := func(x, y, dx, dy int) {
+		starts[[2]int{x, y}] = append(starts[[2]int{x, y}], len(edges))
+		edges = append(edges, outlineEdge{x, y, dx, dy})
+	}
+
+	for y, row := range data {
+		for x, cell := range row {
+			if !cell.Value {
+				continue
+			}
+			if !isDark(data, x, y-1) {
+				addEdge(x, y, 1, 0)
+			}
+			if !isDark(data, x+1, y) {
+				addEdge(x+1, y, 0, 1)
+			}
+			if !isDark(data, x, y+1) {
+				addEdge(x+1, y+1, -1, 0)
+			}
+			if !isDark(data, x-1, y) {
+				addEdge(x, y+1, 0, -1)
+			}
+		}
+	}
+
+	used := make([]bool, len(edges))
+	var outlines [][][2]int
+	for idx := range edges {
+		if used[idx] {
+			continue
+		}
+
+		var outline [][2]int
+		current := idx
+		for !used[current] {
+			used[current] = true
+			edge := edges[current]
+			end := [2]int{edge.x + edge.dx, edge.y + edge.dy}
+
+			next := -1
+			for _, candidate := range starts[end] {
+				if used[candidate] && candidate != idx {
+					continue
+				}
+				next = candidate
+				// where two dark modules touch by the corner, turn right to keep the outlines separate
+				if edges[candidate].dx == -edge.dy && edges[candidate].dy == edge.dx {
+					break
+				}
+			}
+
+			if next == -1 || edges[next].dx != edge.dx || edges[next].dy != edge.dy {
+				outline = append(outline, end)
+			}
+			if next == -1 {
+				break
+			}
+			current = next
+		}
+
+		outlines = append(outlines, outline)
+	}
+
+	return outlines
+}
+
+// svgPath builds the SVG path data of the outlines with the given scale and border.
+func svgPath(outlines [][][2]int, scale, border int) string {
+	var sb strings.Builder
+	for _, outline := range outlines {
+		for idx, corner := range outline {
+			x, y := corner[0]*scale+border, corner[1]*scale+border
+			switch {
+			case idx == 0:
+				fmt.Fprintf(&sb, "M%d %d", x, y)
+			case outline[idx-1][1] == corner[1]:
+				fmt.Fprintf(&sb, "H%d", x)
+			default:
+				fmt.Fprintf(&sb, "V%d", y)
+			}
+		}
+		sb.WriteString("Z")
+	}
+	return sb.String()
+}
+
+// svgFill returns the fill attributes for the color.
+func svgFill(clr color.Color) string {
+	hex, opacity := hexColor(clr)
+	if opacity < 1 {
+		return fmt.Sprintf(` fill="%s" fill-opacity="%s"`, hex, formatNumber(opacity))
+	}
+	return fmt.Sprintf(` fill="%s"`, hex)
+}
+
+// plotSVG writes the QR code as an SVG image. Square dark modules of the same color are merged into outlines
+// of a single path, other shapes and the finder pattern units are added as separate subpaths.
+// The viewBox has the same size as the image, so the image can be scaled without losses.
+// The logo is embedded as a PNG data URI.
+func plotSVG(data [][]Cell, writer io.Writer, scale, border int, style *plotStyle, logo *Logo) error {
+	width, height := matrixSize(data)
+	width, height = width*scale+2*border, height*scale+2*border
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		width, height, width, height)
+	if !isTransparent(style.background) {
+		fmt.Fprintf(&sb, `<rect width="%d" height="%d"%s/>`+"\n", width, height, svgFill(style.background))
+	}
+
+	// paths by the fill attributes in the order of appearance
+	var fills []string
+	paths := make(map[string]*strings.Builder)
+	squares := make(map[string][][]Cell)
+	addFill := func(fill string) {
+		if _, ok := paths[fill]; !ok {
+			fills = append(fills, fill)
+			paths[fill] = &strings.Builder{}
+		}
+	}
+
+	for y, row := range data {
+		for x, cell := range row {
+			if !cell.Value || style.isEye(data, x, y) {
+				continue
+			}
+
+			cellStyle := style.cellStyle(cell.Type)
+			fill := svgFill(cellStyle.Color)
+			addFill(fill)
+
+			if cellStyle.Shape == ModuleShapeSquare {
+				if squares[fill] == nil {
+					squares[fill] = make([][]Cell, len(data))
+					for idx := range squares[fill] {
+						squares[fill][idx] = make([]Cell, len(data[idx]))
+					}
+				}
+				squares[fill][y][x].Value = true
+				continue
+			}
+
+			paths[fill].WriteString(svgModulePath(cellStyle.Shape, roundedCorners(data, x, y),
+				float64(x*scale+border), float64(y*scale+border), float64(scale)))
+		}
+	}
+
+	for _, fill := range fills {
+		if matrix, ok := squares[fill]; ok {
+			paths[fill].WriteString(svgPath(traceOutlines(matrix), scale, border))
+		}
+		fmt.Fprintf(&sb, `<path%s d="%s"/>`+"\n", fill, paths[fill].String())
+	}
+
+	if style.eye != nil {
+		for _, part := range style.eyeParts(matrixSize(data)) {
+			x, y := float64(part.x*scale+border), float64(part.y*scale+border)
+			size := float64(part.size * scale)
+			d := svgShapePath(part.shape, x, y, size, size)
+			if part.ring {
+				d += svgShapePath(part.shape, x+float64(scale), y+float64(scale), size-float64(2*scale), size-float64(2*scale))
+			}
+			fmt.Fprintf(&sb, `<path fill-rule="evenodd"%s d="%s"/>`+"\n", svgFill(part.clr), d)
+		}
+	}
+
+	if logo != nil && logo.Image != nil {
+		start, side := logoArea(len(data), logo.Size)
+		image, err := svgLogo(logo.Image, start*scale+border, start*scale+border, side*scale)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(image + "\n")
+	}
+
+	sb.WriteString("</svg>\n")
+
+	if _, err := io.WriteString(writer, sb.String()); err != nil {
+		return fmt.Errorf("failed to write svg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"testing"
+	"unicode"
+)
+
+type svgImage struct {
+	Width   int    `xml:"width,attr"`
+	Height  int    `xml:"height,attr"`
+	ViewBox string `xml:"viewBox,attr"`
+	Rect    struct {
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+		Fill   string `xml:"fill,attr"`
+	} `xml:"rect"`
+	Path struct {
+		Fill string `xml:"fill,attr"`
+		D    string `xml:"d,attr"`
+	} `xml:"path"`
+}
+
+// parseSVGPath parses the path data with M, H, V and Z commands to the list of polygons.
+func parseSVGPath(t *testing.T, d string) [][][2]float64 {
+	t.Helper()
+
+	var polygons [][][2]float64
+	var current [2]float64
+	readNumber := func(idx int) (float64, int) {
+		end := idx
+		for end < len(d) && (unicode.IsDigit(rune(d[end])) || d[end] == '-' || d[end] == '.') {
+			end++
+		}
+		value, err := strconv.ParseFloat(d[idx:end], 64)
+		if err != nil {
+			t.Fatalf("invalid number in path at %v: %v", idx, err)
+		}
+		return value, end
+	}
+
+	for idx := 0; idx < len(d); {
+		command := d[idx]
+		idx++
+		switch command {
+		case 'M':
+			current[0], idx = readNumber(idx)
+			idx++ // space
+			current[1], idx = readNumber(idx)
+			polygons = append(polygons, [][2]float64{current})
+		case 'H':
+			current[0], idx = readNumber(idx)
+			polygons[len(polygons)-1] = append(polygons[len(polygons)-1], current)
+		case 'V':
+			current[1], idx = readNumber(idx)
+			polygons[len(polygons)-1] = append(polygons[len(polygons)-1], current)
+		case 'Z':
+		default:
+			t.Fatalf("unexpected command %q", command)
+		}
+	}
+
+	return polygons
+}
+
+// windingNumber returns the winding number of the polygons around the point.
+func windingNumber(polygons [][][2]float64, x, y float64) int {
+	winding := 0
+	for _, polygon := range polygons {
+		for idx := range polygon {
+			a, b := polygon[idx], polygon[(idx+1)%len(polygon)]
+			if a[0] != b[0] {
+				continue
+			}
+			// vertical edges crossing the horizontal ray to the right of the point
+			if a[0] > x && (a[1] <= y) != (b[1] <= y) {
+				if b[1] > a[1] {
+					winding++
+				} else {
+					winding--
+				}
+			}
+		}
+	}
+	return winding
+}
+
+func TestTraceOutlines(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     [][]bool
+		outlines int
+		corners  int
+	}{
+		{"single", [][]bool{{true}}, 1, 4},
+		{"line", [][]bool{{true, true, true}}, 1, 4},
+		{"diagonal", [][]bool{{true, false}, {false, true}}, 2, 8},
+		{"ring", [][]bool{{true, true, true}, {true, false, true}, {true, true, true}}, 2, 8},
+		{"L", [][]bool{{true, false}, {true, true}}, 1, 6},
+		{"empty", [][]bool{{false, false}, {false, false}}, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := make([][]Cell, len(test.data))
+			for idx, row := range test.data {
+				data[idx] = make([]Cell, len(row))
+				for jdx, value := range row {
+					data[idx][jdx].Value = value
+				}
+			}
+
+			outlines := traceOutlines(data)
+			if len(outlines) != test.outlines {
+				t.Errorf("Expected %v outlines, got %v", test.outlines, len(outlines))
+			}
+
+			corners := 0
+			for _, outline := range outlines {
+				corners += len(outline)
+			}
+			if corners != test.corners {
+				t.Errorf("Expected %v corners, got %v", test.corners, corners)
+			}
+		})
+	}
+}
+
+func TestPlotSVG(t *testing.T) {
+	tests := []struct {
+		version int
+		scale   int
+		border  int
+	}{
+		{1, 1, 0},
+		{5, 4, 16},
+		{10, 2, 8},
+		{M1, 3, 6},
+		{M4, 10, 20},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("version %v, scale %v, border %v", test.version, test.scale, test.border), func(t *testing.T) {
+			qr, err := Create("1234", &QRCodeOptions{Version: test.version})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := qr.Plot(&buf, &PlotOptions{Scale: test.scale, Border: test.border, OutputFormat: SVG}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var img svgImage
+			if err := xml.Unmarshal(buf.Bytes(), &img); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			size := getSize(test.version)
+			imgSize := size*test.scale + 2*test.border
+			if img.Width != imgSize || img.Height != imgSize || img.Rect.Width != imgSize || img.Rect.Height != imgSize {
+				t.Errorf("Expected size %v, got %vx%v, background %vx%v", imgSize, img.Width, img.Height, img.Rect.Width, img.Rect.Height)
+			}
+			if viewBox := fmt.Sprintf("0 0 %d %d", imgSize, imgSize); img.ViewBox != viewBox {
+				t.Errorf("Expected viewBox %v, got %v", viewBox, img.ViewBox)
+			}
+
+			polygons := parseSVGPath(t, img.Path.D)
+			if len(polygons) >= size*size/2 {
+				t.Errorf("Expected merged outlines, got %v polygons", len(polygons))
+			}
+
+			for idx, row := range qr.Data {
+				for jdx, cell := range row {
+					x := float64(jdx*test.scale+test.border) + float64(test.scale)/2
+					y := float64(idx*test.scale+test.border) + float64(test.scale)/2
+					if dark := windingNumber(polygons, x, y) != 0; dark != cell.Value {
+						t.Errorf("Expected %v at (%v, %v), got %v", cell.Value, jdx, idx, dark)
+					}
+				}
+			}
+
+			// the quiet zone is not covered by the path
+			if test.border > 0 && windingNumber(polygons, float64(test.border)/2, float64(test.border)/2) != 0 {
+				t.Error("Expected light quiet zone")
+			}
+		})
+	}
+
+	t.Run("invalid writer", func(t *testing.T) {
+		if err := plot([][]Cell{{{Value: true}}}, &InvalidWriter{}, &PlotOptions{Scale: 1, OutputFormat: SVG}); err == nil {
+			t.Error("expected an error, but got nil")
+		}
+	})
+}
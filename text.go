@@ -0,0 +1,80 @@
+package qrcode
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// halfBlocks are the characters for the pairs of modules (top, bottom) in one text line.
+var halfBlocks = map[[2]bool]string{
+	{false, false}: " ",
+	{true, false}:  "▀",
+	{false, true}:  "▄",
+	{true, true}:   "█",
+}
+
+// ANSI SGR sequences of the half blocks format. The foreground and the background colors are set explicitly,
+// so the code doesn't depend on the colors of the terminal theme.
+const (
+	// ansiDarkOnLight draws the half blocks black on bright white
+	ansiDarkOnLight = "\x1b[30;107m"
+	ansiReset       = "\x1b[0m"
+)
+
+// textModule returns the value of the module with the quiet zone of border modules around the matrix.
+// If inverted, light modules are drawn, so the code is readable on dark terminals.
+func textModule(data [][]Cell, x, y, border int, inverted bool) bool {
+	return isDark(data, x-border, y-border) != inverted
+}
+
+// plotText writes the QR code as text. Border is the width of the quiet zone in modules.
+// Half blocks formats draw two rows of modules in one line, HALF_BLOCKS with the ANSI colors (see ansiDarkOnLight)
+// and HALF_BLOCKS_INVERTED with the colors of the terminal theme (the light modules in the foreground color
+// of the dark terminal). ASCII formats draw each module as two characters without escape sequences.
+func plotText(data [][]Cell, writer io.Writer, border int, outputFormat OutputFormat) error {
+	width, height := matrixSize(data)
+	width, height = width+2*border, height+2*border
+
+	var sb strings.Builder
+	switch outputFormat {
+	case HALF_BLOCKS, HALF_BLOCKS_INVERTED:
+		inverted := outputFormat == HALF_BLOCKS_INVERTED
+		for y := 0; y < height; y += 2 {
+			if !inverted {
+				sb.WriteString(ansiDarkOnLight)
+			}
+			for x := 0; x < width; x++ {
+				top := textModule(data, x, y, border, inverted)
+				// the last line of the odd height has only the top half
+				bottom := y+1 < height && textModule(data, x, y+1, border, inverted)
+				sb.WriteString(halfBlocks[[2]bool{top, bottom}])
+			}
+			// reset the colors before the line break, so the background doesn't fill the rest of the line
+			if !inverted {
+				sb.WriteString(ansiReset)
+			}
+			sb.WriteString("\n")
+		}
+	case ASCII, ASCII_INVERTED:
+		inverted := outputFormat == ASCII_INVERTED
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if textModule(data, x, y, border, inverted) {
+					sb.WriteString("##")
+				} else {
+					sb.WriteString("  ")
+				}
+			}
+			sb.WriteString("\n")
+		}
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+
+	if _, err := io.WriteString(writer, sb.String()); err != nil {
+		return fmt.Errorf("failed to write text: %w", err)
+	}
+
+	return nil
+}
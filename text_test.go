@@ -0,0 +1,128 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// parseText parses the text output back to the matrix of modules (with the quiet zone).
+func parseText(t *testing.T, text string, outputFormat OutputFormat) [][]bool {
+	t.Helper()
+
+	var modules [][]bool
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		switch outputFormat {
+		case HALF_BLOCKS, HALF_BLOCKS_INVERTED:
+			if outputFormat == HALF_BLOCKS {
+				if !strings.HasPrefix(line, ansiDarkOnLight) || !strings.HasSuffix(line, ansiReset) {
+					t.Fatalf("Expected line with colors %q and reset, got %q", ansiDarkOnLight, line)
+				}
+				line = strings.TrimSuffix(strings.TrimPrefix(line, ansiDarkOnLight), ansiReset)
+			} else if strings.Contains(line, "\x1b") {
+				// the inverted format uses the colors of the terminal theme
+				t.Fatalf("Expected line without escape sequences, got %q", line)
+			}
+
+			var top, bottom []bool
+			for _, char := range line {
+				found := false
+				for value, block := range halfBlocks {
+					if string(char) == block {
+						top, bottom = append(top, value[0]), append(bottom, value[1])
+						found = true
+					}
+				}
+				if !found {
+					t.Fatalf("unexpected character %q", char)
+				}
+			}
+			modules = append(modules, top, bottom)
+		case ASCII, ASCII_INVERTED:
+			if len(line)%2 != 0 {
+				t.Fatalf("Expected even line length, got %v", len(line))
+			}
+			var row []bool
+			for idx := 0; idx < len(line); idx += 2 {
+				switch line[idx : idx+2] {
+				case "##":
+					row = append(row, true)
+				case "  ":
+					row = append(row, false)
+				default:
+					t.Fatalf("unexpected characters %q", line[idx:idx+2])
+				}
+			}
+			modules = append(modules, row)
+		}
+	}
+
+	return modules
+}
+
+func TestPlotText(t *testing.T) {
+	formats := []OutputFormat{HALF_BLOCKS, HALF_BLOCKS_INVERTED, ASCII, ASCII_INVERTED}
+
+	for _, version := range []int{1, 2, M1, M2} {
+		for _, border := range []int{0, 1, 4} {
+			for _, format := range formats {
+				t.Run(fmt.Sprintf("version %v, border %v, format %v", version, border, format), func(t *testing.T) {
+					qr, err := Create("1234", &QRCodeOptions{Version: version})
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					var buf bytes.Buffer
+					if err := qr.Plot(&buf, &PlotOptions{Border: border, OutputFormat: format}); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					modules := parseText(t, buf.String(), format)
+					inverted := format == HALF_BLOCKS_INVERTED || format == ASCII_INVERTED
+					size := len(qr.Data) + 2*border
+
+					lines := size
+					if format == HALF_BLOCKS || format == HALF_BLOCKS_INVERTED {
+						lines = (size + 1) / 2 * 2
+					}
+					if len(modules) != lines {
+						t.Fatalf("Expected %v rows, got %v", lines, len(modules))
+					}
+
+					for y, row := range modules {
+						if len(row) != size {
+							t.Fatalf("Expected %v columns in row %v, got %v", size, y, len(row))
+						}
+						for x, value := range row {
+							expected := false
+							if y-border >= 0 && y-border < len(qr.Data) && x-border >= 0 && x-border < len(qr.Data) {
+								expected = qr.Data[y-border][x-border].Value
+							}
+							// the bottom half of the last line of the odd size is not drawn
+							if y < size {
+								expected = expected != inverted
+							}
+							if value != expected {
+								t.Errorf("Expected %v at (%v, %v), got %v", expected, x, y, value)
+							}
+						}
+					}
+				})
+			}
+		}
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := plotText([][]Cell{{{Value: true}}}, &buf, 0, PNG); err == nil {
+			t.Error("expected an error, but got nil")
+		}
+	})
+
+	t.Run("invalid writer", func(t *testing.T) {
+		if err := plot([][]Cell{{{Value: true}}}, &InvalidWriter{}, &PlotOptions{Scale: 1, OutputFormat: ASCII}); err == nil {
+			t.Error("expected an error, but got nil")
+		}
+	})
+}
@@ -0,0 +1,343 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Length is a physical length in PostScript points (1/72 inch).
+type Length float64
+
+const (
+	Point      Length = 1
+	Inch       Length = 72
+	Millimeter Length = Inch / 25.4
+)
+
+// rectangle is a rectangle of dark modules (in modules).
+type rectangle struct {
+	x, y          int
+	width, height int
+}
+
+// mergeRectangles merges the dark modules into rectangles: the runs of dark modules in a row become one rectangle,
+// the rectangles with the same runs in the consecutive rows are merged vertically.
+func mergeRectangles(data [][]Cell) []rectangle {
+	var rectangles []rectangle
+	// open rectangles of the previous row by the run start
+	open := make(map[[2]int]int)
+
+	for y, row := range data {
+		next := make(map[[2]int]int)
+		for x := 0; x < len(row); {
+			if !row[x].Value {
+				x++
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x].Value {
+				x++
+			}
+
+			run := [2]int{start, x - start}
+			if idx, ok := open[run]; ok {
+				rectangles[idx].height++
+				next[run] = idx
+				continue
+			}
+
+			next[run] = len(rectangles)
+			rectangles = append(rectangles, rectangle{x: start, y: y, width: x - start, height: 1})
+		}
+		open = next
+	}
+
+	return rectangles
+}
+
+// formatNumber formats the number with at most 4 decimal places and without trailing zeros.
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10000)/10000, 'f', -1, 64)
+}
+
+// bezierArc is the distance of the control points of the cubic Bezier curve, which approximates the quarter
+// of the circle, relative to the radius.
+const bezierArc = 0.5523
+
+// vectorWriter writes the drawing commands of PDF or PostScript. The coordinates are in pixels of the image
+// with the origin in the top-left corner, both formats use the origin in the bottom-left corner.
+type vectorWriter struct {
+	buf    bytes.Buffer
+	pdf    bool
+	pixel  float64
+	height float64
+}
+
+// point returns the coordinates of the point in the coordinate system of the document.
+func (w *vectorWriter) point(x, y float64) string {
+	return formatNumber(x*w.pixel) + " " + formatNumber(w.height-y*w.pixel)
+}
+
+// command writes the operands with the PDF or the PostScript operator.
+func (w *vectorWriter) command(operands, pdfOperator, psOperator string) {
+	operator := psOperator
+	if w.pdf {
+		operator = pdfOperator
+	}
+	if operands != "" {
+		w.buf.WriteString(operands + " ")
+	}
+	w.buf.WriteString(operator + "\n")
+}
+
+func (w *vectorWriter) setColor(clr color.Color) {
+	w.command(rgbComponents(clr), "rg", "setrgbcolor")
+}
+
+// rectangle adds the rectangle to the path (PDF) or fills it (PostScript).
+func (w *vectorWriter) rectangle(x, y, width, height float64) {
+	w.command(w.point(x, y+height)+" "+formatNumber(width*w.pixel)+" "+formatNumber(height*w.pixel), "re", "rectfill")
+}
+
+func (w *vectorWriter) moveTo(x, y float64) {
+	w.command(w.point(x, y), "m", "moveto")
+}
+
+func (w *vectorWriter) lineTo(x, y float64) {
+	w.command(w.point(x, y), "l", "lineto")
+}
+
+// arcTo adds the quarter of the ellipse from the point (fromX, fromY) to the point (toX, toY),
+// the corner is the corner of the bounding box of the arc.
+func (w *vectorWriter) arcTo(fromX, fromY, cornerX, cornerY, toX, toY float64) {
+	w.command(w.point(fromX+bezierArc*(cornerX-fromX), fromY+bezierArc*(cornerY-fromY))+" "+
+		w.point(toX+bezierArc*(cornerX-toX), toY+bezierArc*(cornerY-toY))+" "+w.point(toX, toY), "c", "curveto")
+}
+
+func (w *vectorWriter) closePath() {
+	w.command("", "h", "closepath")
+}
+
+// fill fills the current path, the even-odd rule keeps the holes of the rings.
+func (w *vectorWriter) fill(evenOdd bool) {
+	if evenOdd {
+		w.command("", "f*", "eofill")
+	} else {
+		w.command("", "f", "fill")
+	}
+}
+
+// shapePath adds the shape in the rectangle to the path (the vector version of svgShapePath).
+func (w *vectorWriter) shapePath(shape ModuleShape, x, y, width, height float64) {
+	switch shape {
+	case ModuleShapeCircle:
+		cx, cy := x+width/2, y+height/2
+		w.moveTo(cx, y)
+		w.arcTo(cx, y, x+width, y, x+width, cy)
+		w.arcTo(x+width, cy, x+width, y+height, cx, y+height)
+		w.arcTo(cx, y+height, x, y+height, x, cy)
+		w.arcTo(x, cy, x, y, cx, y)
+	case ModuleShapeDiamond:
+		w.moveTo(x+width/2, y)
+		w.lineTo(x+width, y+height/2)
+		w.lineTo(x+width/2, y+height)
+		w.lineTo(x, y+height/2)
+	case ModuleShapeRounded:
+		r := math.Min(width, height) * eyeCornerRadius
+		w.moveTo(x+r, y)
+		w.lineTo(x+width-r, y)
+		w.arcTo(x+width-r, y, x+width, y, x+width, y+r)
+		w.lineTo(x+width, y+height-r)
+		w.arcTo(x+width, y+height-r, x+width, y+height, x+width-r, y+height)
+		w.lineTo(x+r, y+height)
+		w.arcTo(x+r, y+height, x, y+height, x, y+height-r)
+		w.lineTo(x, y+r)
+		w.arcTo(x, y+r, x, y, x+r, y)
+	default:
+		w.moveTo(x, y)
+		w.lineTo(x+width, y)
+		w.lineTo(x+width, y+height)
+		w.lineTo(x, y+height)
+	}
+	w.closePath()
+}
+
+// modulePath adds the module with the shape to the path (the vector version of svgModulePath).
+func (w *vectorWriter) modulePath(shape ModuleShape, corners [4]bool, x, y, scale float64) {
+	if shape != ModuleShapeRounded {
+		w.shapePath(shape, x, y, scale, scale)
+		return
+	}
+
+	r := scale / 2
+	middles := [4][2]float64{{x + r, y}, {x + scale, y + r}, {x + r, y + scale}, {x, y + r}}
+	cornerPoints := [4][2]float64{{x, y}, {x + scale, y}, {x + scale, y + scale}, {x, y + scale}}
+
+	w.moveTo(middles[0][0], middles[0][1])
+	for idx := 1; idx <= 4; idx++ {
+		corner := idx % 4
+		prev, next := middles[idx-1], middles[idx%4]
+		if corners[corner] {
+			w.arcTo(prev[0], prev[1], cornerPoints[corner][0], cornerPoints[corner][1], next[0], next[1])
+		} else {
+			w.lineTo(cornerPoints[corner][0], cornerPoints[corner][1])
+			w.lineTo(next[0], next[1])
+		}
+	}
+	w.closePath()
+}
+
+// vectorGroup is a group of the dark modules with the same color: the matrix of the square modules
+// and the coordinates of the modules with other shapes.
+type vectorGroup struct {
+	clr     color.Color
+	squares [][]Cell
+	shapes  [][2]int
+}
+
+// vectorContent writes the drawing commands of the dark modules for PDF and EPS in the same way as plotSVG:
+// square modules of the same color are merged into rectangles, other shapes are added as separate subpaths
+// and the finder pattern units are filled with the even-odd rule.
+func vectorContent(w *vectorWriter, data [][]Cell, scale, border int, style *plotStyle) {
+	// modules by the colors in the order of appearance
+	var colors []string
+	groups := make(map[string]*vectorGroup)
+
+	for y, row := range data {
+		for x, cell := range row {
+			if !cell.Value || style.isEye(data, x, y) {
+				continue
+			}
+
+			cellStyle := style.cellStyle(cell.Type)
+			key := rgbComponents(cellStyle.Color)
+			group, ok := groups[key]
+			if !ok {
+				colors = append(colors, key)
+				group = &vectorGroup{clr: cellStyle.Color, squares: make([][]Cell, len(data))}
+				for idx := range group.squares {
+					group.squares[idx] = make([]Cell, len(data[idx]))
+				}
+				groups[key] = group
+			}
+
+			if cellStyle.Shape == ModuleShapeSquare {
+				group.squares[y][x].Value = true
+			} else {
+				group.shapes = append(group.shapes, [2]int{x, y})
+			}
+		}
+	}
+
+	for _, key := range colors {
+		group := groups[key]
+		w.setColor(group.clr)
+
+		for _, rect := range mergeRectangles(group.squares) {
+			w.rectangle(float64(rect.x*scale+border), float64(rect.y*scale+border),
+				float64(rect.width*scale), float64(rect.height*scale))
+		}
+		for _, module := range group.shapes {
+			x, y := module[0], module[1]
+			w.modulePath(style.cellStyle(data[y][x].Type).Shape, roundedCorners(data, x, y),
+				float64(x*scale+border), float64(y*scale+border), float64(scale))
+		}
+
+		// PostScript fills the rectangles immediately
+		if w.pdf || len(group.shapes) > 0 {
+			w.fill(false)
+		}
+	}
+
+	if style.eye == nil {
+		return
+	}
+
+	for _, part := range style.eyeParts(matrixSize(data)) {
+		x, y := float64(part.x*scale+border), float64(part.y*scale+border)
+		size := float64(part.size * scale)
+		w.setColor(part.clr)
+		w.shapePath(part.shape, x, y, size, size)
+		if part.ring {
+			w.shapePath(part.shape, x+float64(scale), y+float64(scale), size-float64(2*scale), size-float64(2*scale))
+		}
+		w.fill(true)
+	}
+}
+
+// plotVector writes the QR code as a single page PDF document or an Encapsulated PostScript file.
+// The page (bounding box) covers the code with the border, one pixel has the size moduleSize / scale.
+// The modules are drawn with the cell styles and the finder pattern style.
+// The formats have no transparency: the transparent background is not drawn, the alpha of the colors is ignored.
+func plotVector(data [][]Cell, writer io.Writer, scale, border int, moduleSize Length, style *plotStyle, logo *Logo, outputFormat OutputFormat) error {
+	pixel := float64(moduleSize) / float64(scale)
+	dataWidth, dataHeight := matrixSize(data)
+	imgWidth := float64(dataWidth*scale+2*border) * pixel
+	imgHeight := float64(dataHeight*scale+2*border) * pixel
+	width, height := formatNumber(imgWidth), formatNumber(imgHeight)
+
+	content := &vectorWriter{pdf: outputFormat == PDF, pixel: pixel, height: imgHeight}
+	if !isTransparent(style.background) {
+		content.setColor(style.background)
+		content.rectangle(0, 0, imgWidth/pixel, imgHeight/pixel)
+		if content.pdf {
+			content.fill(false)
+		}
+	}
+	vectorContent(content, data, scale, border, style)
+	if logo != nil && logo.vector != nil {
+		start, side := logoArea(dataHeight, logo.Size)
+		logo.vector(content, float64(start*scale+border), float64(start*scale+border), float64(side*scale))
+	}
+
+	var buf bytes.Buffer
+	switch outputFormat {
+	case PDF:
+		writePDF(&buf, []string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Contents 4 0 R /Resources << >> >>", width, height),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.buf.Len(), content.buf.String()),
+		})
+	case EPS:
+		fmt.Fprintf(&buf, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+		fmt.Fprintf(&buf, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(imgWidth)), int(math.Ceil(imgHeight)))
+		fmt.Fprintf(&buf, "%%%%HiResBoundingBox: 0 0 %s %s\n", width, height)
+		fmt.Fprintf(&buf, "%%%%Pages: 0\n%%%%EndComments\n")
+		buf.Write(content.buf.Bytes())
+		fmt.Fprintf(&buf, "showpage\n%%%%EOF\n")
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+
+	if _, err := writer.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFormat, err)
+	}
+
+	return nil
+}
+
+// writePDF writes the PDF document with the given objects (numbered from 1, the first one is the catalog)
+// and the cross-reference table.
+func writePDF(buf *bytes.Buffer, objects []string) {
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for idx, object := range objects {
+		offsets[idx] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", idx+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n", len(objects)+1)
+	buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+}
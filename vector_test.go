@@ -0,0 +1,331 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMergeRectangles(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       [][]bool
+		rectangles []rectangle
+	}{
+		{"empty", [][]bool{{false, false}}, nil},
+		{"run", [][]bool{{true, true, false, true}}, []rectangle{{0, 0, 2, 1}, {3, 0, 1, 1}}},
+		{"square", [][]bool{{true, true}, {true, true}}, []rectangle{{0, 0, 2, 2}}},
+		{
+			"steps",
+			[][]bool{{true, true, false}, {true, true, true}, {false, true, true}},
+			[]rectangle{{0, 0, 2, 1}, {0, 1, 3, 1}, {1, 2, 2, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := make([][]Cell, len(test.data))
+			for idx, row := range test.data {
+				data[idx] = make([]Cell, len(row))
+				for jdx, value := range row {
+					data[idx][jdx].Value = value
+				}
+			}
+
+			rectangles := mergeRectangles(data)
+			if fmt.Sprint(rectangles) != fmt.Sprint(test.rectangles) {
+				t.Errorf("Expected %v, got %v", test.rectangles, rectangles)
+			}
+		})
+	}
+
+	t.Run("covers QR code", func(t *testing.T) {
+		qr, err := Create("https://example.com", &QRCodeOptions{Version: 5})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		covered := make([][]bool, len(qr.Data))
+		for idx := range covered {
+			covered[idx] = make([]bool, len(qr.Data))
+		}
+
+		rectangles := mergeRectangles(qr.Data)
+		for _, rect := range rectangles {
+			for y := rect.y; y < rect.y+rect.height; y++ {
+				for x := rect.x; x < rect.x+rect.width; x++ {
+					if covered[y][x] {
+						t.Errorf("module (%v, %v) is covered twice", x, y)
+					}
+					covered[y][x] = true
+				}
+			}
+		}
+
+		dark := 0
+		for idx, row := range qr.Data {
+			for jdx, cell := range row {
+				if cell.Value != covered[idx][jdx] {
+					t.Errorf("Expected %v at (%v, %v), got %v", cell.Value, jdx, idx, covered[idx][jdx])
+				}
+				if cell.Value {
+					dark++
+				}
+			}
+		}
+
+		if len(rectangles) >= dark {
+			t.Errorf("Expected merged rectangles, got %v for %v dark modules", len(rectangles), dark)
+		}
+	})
+}
+
+func TestPlotPDF(t *testing.T) {
+	qr, err := Create("1234", &QRCodeOptions{Version: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	options := &PlotOptions{Scale: 2, Border: 8, OutputFormat: PDF, ModuleSize: Inch}
+	if err := qr.Plot(&buf, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pdf := buf.String()
+
+	for y, row := range pdfModules(t, pdf, 21, 21, options) {
+		for x, cell := range row {
+			if cell.Value != qr.Data[y][x].Value {
+				t.Errorf("Expected %v at (%v, %v), got %v", qr.Data[y][x].Value, x, y, cell.Value)
+			}
+		}
+	}
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Errorf("Expected PDF header and trailer, got %v", pdf)
+	}
+
+	// (21 modules * 2 + 2 * 8 border pixels) * 36 points per pixel
+	if !strings.Contains(pdf, "/MediaBox [0 0 2088 2088]") {
+		t.Errorf("Expected media box 2088x2088, got %v", pdf)
+	}
+
+	// the top-left module of the finder pattern (8 border pixels from the left and the top)
+	if !strings.Contains(pdf, "\n288 1728 504 72 re\n") {
+		t.Errorf("Expected the top row of the finder pattern, got %v", pdf)
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	if startxref == nil {
+		t.Fatalf("Expected startxref, got %v", pdf)
+	}
+	xref, _ := strconv.Atoi(startxref[1])
+	if !strings.HasPrefix(pdf[xref:], "xref\n0 5\n") {
+		t.Fatalf("Expected xref table at %v, got %v", xref, pdf[xref:])
+	}
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1)
+	if len(offsets) != 4 {
+		t.Fatalf("Expected 4 objects, got %v", len(offsets))
+	}
+	for idx, offset := range offsets {
+		value, _ := strconv.Atoi(offset[1])
+		if expected := fmt.Sprintf("%d 0 obj\n", idx+1); !strings.HasPrefix(pdf[value:], expected) {
+			t.Errorf("Expected object %v at %v", idx+1, value)
+		}
+	}
+
+	length := regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`).FindStringSubmatchIndex(pdf)
+	if length == nil {
+		t.Fatalf("Expected content stream, got %v", pdf)
+	}
+	streamLength, _ := strconv.Atoi(pdf[length[2]:length[3]])
+	if !strings.HasPrefix(pdf[length[1]+streamLength:], "endstream") {
+		t.Errorf("Expected endstream after %v bytes of the stream", streamLength)
+	}
+}
+
+func TestPlotEPS(t *testing.T) {
+	tests := []struct {
+		version     int
+		scale       int
+		border      int
+		moduleSize  Length
+		boundingBox string
+		hiRes       string
+	}{
+		{1, 1, 0, Inch, "0 0 1512 1512", "0 0 1512 1512"},
+		{1, 4, 16, Millimeter, "0 0 83 83", "0 0 82.2047 82.2047"},
+		{M2, 1, 4, Point, "0 0 21 21", "0 0 21 21"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("version %v, module size %v", test.version, test.moduleSize), func(t *testing.T) {
+			qr, err := Create("1234", &QRCodeOptions{Version: test.version})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			options := &PlotOptions{Scale: test.scale, Border: test.border, OutputFormat: EPS, ModuleSize: test.moduleSize}
+			if err := qr.Plot(&buf, options); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			eps := buf.String()
+
+			if !strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n") {
+				t.Errorf("Expected EPS header, got %v", eps)
+			}
+			if !strings.Contains(eps, "%%BoundingBox: "+test.boundingBox+"\n") {
+				t.Errorf("Expected bounding box %v, got %v", test.boundingBox, eps)
+			}
+			if !strings.Contains(eps, "%%HiResBoundingBox: "+test.hiRes+"\n") {
+				t.Errorf("Expected high resolution bounding box %v, got %v", test.hiRes, eps)
+			}
+
+			rects := strings.Count(eps, "rectfill\n") - 1
+			if expected := len(mergeRectangles(qr.Data)); rects != expected {
+				t.Errorf("Expected %v rectangles, got %v", expected, rects)
+			}
+		})
+	}
+
+	t.Run("default module size", func(t *testing.T) {
+		qr, err := Create("1234", &QRCodeOptions{Version: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := qr.Plot(&buf, &PlotOptions{OutputFormat: EPS}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// 21 modules * 0.5 mm
+		if !strings.Contains(buf.String(), "%%HiResBoundingBox: 0 0 29.7638 29.7638\n") {
+			t.Errorf("Expected 10.5 mm bounding box, got %v", buf.String())
+		}
+	})
+
+	t.Run("invalid writer", func(t *testing.T) {
+		if err := plot([][]Cell{{{Value: true}}}, &InvalidWriter{}, &PlotOptions{Scale: 1, OutputFormat: EPS, ModuleSize: Point}); err == nil {
+			t.Error("expected an error, but got nil")
+		}
+	})
+}
+
+// pdfFill is a filled path of the PDF content stream, the curves are flattened to the polygons.
+// The coordinates are in points with the origin in the top-left corner of the page.
+type pdfFill struct {
+	dark     bool
+	evenOdd  bool
+	polygons [][][2]float64
+}
+
+// parsePDFFills parses the fills of the content stream with the rg, re, m, l, c, h, f and f* operators.
+func parsePDFFills(t *testing.T, pdf string) []pdfFill {
+	t.Helper()
+
+	mediaBox := regexp.MustCompile(`/MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`).FindStringSubmatch(pdf)
+	start, end := strings.Index(pdf, "stream\n"), strings.Index(pdf, "endstream")
+	if mediaBox == nil || start < 0 || end < 0 {
+		t.Fatalf("Expected media box and content stream, got %v", pdf)
+	}
+	height, _ := strconv.ParseFloat(mediaBox[2], 64)
+
+	var fills []pdfFill
+	var operands []float64
+	var polygons [][][2]float64
+	dark := false
+	point := func(idx int) [2]float64 {
+		return [2]float64{operands[idx], height - operands[idx+1]}
+	}
+	for _, token := range strings.Fields(pdf[start+len("stream\n") : end]) {
+		if value, err := strconv.ParseFloat(token, 64); err == nil {
+			operands = append(operands, value)
+			continue
+		}
+
+		switch token {
+		case "rg":
+			dark = operands[0]+operands[1]+operands[2] < 1.5
+		case "re":
+			x, y := point(0)[0], point(0)[1]
+			width, rectHeight := operands[2], operands[3]
+			polygons = append(polygons, [][2]float64{{x, y}, {x + width, y}, {x + width, y - rectHeight}, {x, y - rectHeight}})
+		case "m":
+			polygons = append(polygons, [][2]float64{point(0)})
+		case "l":
+			polygons[len(polygons)-1] = append(polygons[len(polygons)-1], point(0))
+		case "c":
+			polygon := polygons[len(polygons)-1]
+			p0, p1, p2, p3 := polygon[len(polygon)-1], point(0), point(2), point(4)
+			for step := 1; step <= 8; step++ {
+				s := float64(step) / 8
+				a, b, c, d := (1-s)*(1-s)*(1-s), 3*(1-s)*(1-s)*s, 3*(1-s)*s*s, s*s*s
+				polygon = append(polygon, [2]float64{
+					a*p0[0] + b*p1[0] + c*p2[0] + d*p3[0],
+					a*p0[1] + b*p1[1] + c*p2[1] + d*p3[1],
+				})
+			}
+			polygons[len(polygons)-1] = polygon
+		case "h":
+		case "f", "f*":
+			fills = append(fills, pdfFill{dark: dark, evenOdd: token == "f*", polygons: polygons})
+			polygons = nil
+		}
+		operands = nil
+	}
+
+	return fills
+}
+
+// pdfDark returns true if the last fill, which covers the point, is dark.
+func pdfDark(fills []pdfFill, x, y float64) bool {
+	dark := false
+	for _, fill := range fills {
+		winding := 0
+		for _, polygon := range fill.polygons {
+			for idx := range polygon {
+				a, b := polygon[idx], polygon[(idx+1)%len(polygon)]
+				if (a[1] <= y) == (b[1] <= y) {
+					continue
+				}
+				// edges crossing the horizontal ray to the right of the point
+				if a[0]+(y-a[1])/(b[1]-a[1])*(b[0]-a[0]) > x {
+					if b[1] > a[1] {
+						winding++
+					} else {
+						winding--
+					}
+				}
+			}
+		}
+
+		if fill.evenOdd && winding%2 != 0 || !fill.evenOdd && winding != 0 {
+			dark = fill.dark
+		}
+	}
+	return dark
+}
+
+// pdfModules samples the modules of the PDF plotted with the options at the centers of the modules.
+func pdfModules(t *testing.T, pdf string, width, height int, options *PlotOptions) [][]Cell {
+	t.Helper()
+
+	fills := parsePDFFills(t, pdf)
+	pixel := float64(options.ModuleSize) / float64(options.Scale)
+	modules := make([][]Cell, height)
+	for y := range modules {
+		modules[y] = make([]Cell, width)
+		for x := range modules[y] {
+			centerX := (float64(x*options.Scale+options.Border) + float64(options.Scale)/2) * pixel
+			centerY := (float64(y*options.Scale+options.Border) + float64(options.Scale)/2) * pixel
+			modules[y][x].Value = pdfDark(fills, centerX, centerY)
+		}
+	}
+	return modules
+}